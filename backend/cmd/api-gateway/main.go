@@ -7,10 +7,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/gateway"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/monitoring"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/tlsutil"
 )
 
 func main() {
@@ -20,8 +28,89 @@ func main() {
 		os.Exit(0)
 	}
 
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %s\n", err)
+	}
+	defer logger.Sync()
+
+	// Initialize TLS (nil when disabled)
+	tlsManager, err := tlsutil.NewManager(config.LoadTLSConfig(), logger)
+	if err != nil {
+		log.Fatalf("failed to initialize TLS: %s\n", err)
+	}
+	defer tlsManager.Close()
+
+	// Initialize metrics
+	metricsCollector := monitoring.NewMetrics()
+
+	// Initialize the reverse proxy; upstreams get the service certificate when mTLS is on
+	gatewayConfig, err := config.LoadGatewayConfig()
+	if err != nil {
+		log.Fatalf("invalid gateway configuration: %s\n", err)
+	}
+	proxy, err := gateway.New(gatewayConfig, tlsManager.HTTPTransport(), logger)
+	if err != nil {
+		log.Fatalf("failed to initialize gateway: %s\n", err)
+	}
+	metricsCollector.RegisterCollectors(proxy.Collectors()...)
+	proxy.SetRecorder(metricsCollector)
+
+	// Authenticate, rate limit and filter requests here so upstreams only check
+	// the identity the gateway signs for them
+	securityConfig := config.LoadGatewaySecurityConfig()
+	redisOptions, err := redis.ParseURL(securityConfig.RedisURL)
+	if err != nil {
+		log.Fatalf("invalid REDIS_URL: %s\n", err)
+	}
+	redisClient := redis.NewClient(redisOptions)
+	defer redisClient.Close()
+
+	identitySigner, err := security.NewIdentitySigner(securityConfig.Auth.IdentitySecret, securityConfig.Auth.RetiredIdentitySecrets, securityConfig.Auth.IdentityTTL)
+	if err != nil {
+		log.Fatalf("invalid INTERNAL_IDENTITY_SECRET: %s\n", err)
+	}
+	if identitySigner == nil {
+		logger.Warn("INTERNAL_IDENTITY_SECRET is not set, upstreams will authenticate requests themselves")
+	}
+
+	securityMiddleware := security.NewSecurityMiddleware(&security.SecurityConfig{
+		JWTSecret:      securityConfig.Auth.JWTSecret,
+		JWTExpiration:  securityConfig.Auth.JWTExpiry,
+		JWTIssuer:      securityConfig.Auth.JWTIssuer,
+		TrustedProxies: securityConfig.TrustedProxies,
+		AllowedIPs:     securityConfig.AllowedIPs,
+		BlockedIPs:     securityConfig.BlockedIPs,
+	}, logger, redisClient)
+	defer securityMiddleware.Close()
+	metricsCollector.RegisterCollectors(securityMiddleware.Collectors()...)
+	proxy.SetSecurity(securityMiddleware, identitySigner)
+
+	// Routes loaded from a file are reloaded when it changes or on SIGHUP
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if gatewayConfig.File != "" {
+		watcher := gateway.NewConfigWatcher(proxy, gatewayConfig.File, logger)
+		go watcher.Watch(watchCtx, gatewayConfig.ReloadInterval)
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := watcher.Reload(); err != nil {
+					logger.Error("Failed to reload gateway configuration, keeping previous routes", zap.Error(err))
+				}
+			}
+		}()
+	}
+
 	// Set up Gin router
 	r := gin.Default()
+	if err := security.ApplyTrustedProxies(r, strings.Split(os.Getenv("TRUSTED_PROXIES"), ",")); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %s\n", err)
+	}
+	r.Use(metricsCollector.GinMiddleware())
+	// Body limits are enforced per route by the proxy, so uploads can exceed MAX_BODY_BYTES
 
 	// Health endpoints
 	r.GET("/health", func(c *gin.Context) {
@@ -48,25 +137,14 @@ func main() {
 		})
 	})
 
-	// Proxy endpoints (placeholder)
-	r.Any("/api/v1/metrics/*path", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Metrics service proxy",
-			"path":    c.Param("path"),
-		})
-	})
+	// Home screen composed from the metrics and community services
+	r.GET("/api/v1/home", securityMiddleware.IPFilter(), proxy.HomeHandler())
 
-	r.Any("/api/v1/ml/*path", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "ML Pipeline proxy",
-			"path":    c.Param("path"),
-		})
-	})
+	// Everything else is proxied to the upstream of the matching route
+	r.NoRoute(securityMiddleware.IPFilter(), proxy.Handler())
 
 	// Metrics endpoint
-	r.GET("/metrics", func(c *gin.Context) {
-		c.String(http.StatusOK, "# API Gateway Metrics\ngateway_requests_total 0\n")
-	})
+	r.GET("/metrics", gin.WrapH(metricsCollector.Handler()))
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -81,12 +159,12 @@ func main() {
 
 	// Graceful shutdown
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := tlsManager.ListenAndServe(srv); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %s\n", err)
 		}
 	}()
 
-	log.Printf("API Gateway service started on port %s", port)
+	log.Printf("API Gateway service started on port %s (tls=%t)", port, tlsManager.Enabled())
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,10 +11,24 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/apiversion"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/audit"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/cache"
 	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/encryption"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/gdpr"
 	"github.com/Danchouvzv/DunkSense/backend/pkg/logging"
 	"github.com/Danchouvzv/DunkSense/backend/pkg/metrics"
 	"github.com/Danchouvzv/DunkSense/backend/pkg/monitoring"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/openapi"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/storage"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/tlsutil"
+	metricsv1 "github.com/Danchouvzv/DunkSense/backend/proto/metrics/v1"
 )
 
 func main() {
@@ -55,8 +70,171 @@ func main() {
 	}
 	defer store.Close()
 
+	// Enable field-level encryption of location and personal data
+	keyRing, err := encryption.LoadKeyRing(cfg.Encryption)
+	if err != nil {
+		logger.WithError(err).Error("Failed to load encryption keys")
+		os.Exit(1)
+	}
+	if keyRing != nil {
+		store.SetEncryptor(encryption.NewFieldEncryptor(keyRing))
+	} else {
+		logger.Warn("No encryption master key configured, sensitive fields are stored in plaintext")
+	}
+
+	// Re-encrypt sensitive fields with the active master key, then exit
+	if len(os.Args) > 1 && os.Args[1] == "-rotate-encryption-keys" {
+		report, err := store.RotateEncryptionKeys(context.Background())
+		if err != nil {
+			logger.WithError(err).Error("Encryption key rotation failed")
+			os.Exit(1)
+		}
+		logger.WithFields(map[string]interface{}{
+			"metrics":  report.Metrics,
+			"sessions": report.Sessions,
+			"profiles": report.Profiles,
+		}).Info("Encryption key rotation completed")
+		return
+	}
+
+	// Flag minors among profiles written before minor was derived from the age, then exit
+	if len(os.Args) > 1 && os.Args[1] == "-backfill-minors" {
+		flagged, err := store.BackfillMinors(context.Background())
+		if err != nil {
+			logger.WithError(err).Error("Minor backfill failed")
+			os.Exit(1)
+		}
+		logger.WithFields(map[string]interface{}{
+			"profiles": flagged,
+		}).Info("Minor backfill completed")
+		return
+	}
+
 	// Initialize metrics service
 	metricsService := metrics.NewService(store, logger)
+	metricsHandler := metrics.NewHandler(store, logger.Logger)
+
+	// Requests (and optionally responses) of documented routes are checked
+	// against the models the API document is generated from
+	apiRoutes := append(metrics.APIRoutes("/api/v1"), metrics.TrainingRoutes("/api/v1/admin")...)
+	apiValidator := openapi.NewValidator(apiRoutes, openapi.ValidatorConfig{
+		Strict:    cfg.Server.StrictValidation,
+		Responses: cfg.Server.ValidateResponses,
+	}, logger.Logger)
+	metricsCollector.RegisterCollectors(apiValidator.Collectors()...)
+
+	// App builds speaking an older API version have their bodies converted to
+	// and from the models
+	apiVersions := apiversion.New(metrics.APIVersions("/api/v1"), logger.Logger)
+	metricsCollector.RegisterCollectors(apiVersions.Collectors()...)
+
+	// Initialize Redis client
+	redisOptions, err := redis.ParseURL(cfg.Database.RedisURL)
+	if err != nil {
+		logger.WithError(err).Error("Invalid Redis URL")
+		os.Exit(1)
+	}
+	redisClient := redis.NewClient(redisOptions)
+	defer redisClient.Close()
+
+	// Cache athlete reads; writes through the store invalidate them
+	if cfg.Cache.ResponseTTL > 0 {
+		responses := cache.NewResponder(
+			cache.NewStore(redisClient, cfg.Cache.ResponseMaxEntries, logger.Logger),
+			cfg.Cache.ResponseTTL, metricsCollector, logger.Logger,
+		)
+		metricsHandler.SetResponseCache(responses)
+		store.OnAthleteChanged(responses.Invalidate)
+	}
+
+	// Initialize the security audit trail
+	var auditSink audit.Sink
+	switch cfg.Audit.Sink {
+	case "mongo":
+		auditSink, err = audit.NewMongoSink(context.Background(), store.Database().Collection(audit.CollectionName))
+	case "file":
+		auditSink, err = audit.NewFileSink(cfg.Audit.FilePath)
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to initialize audit log")
+		os.Exit(1)
+	}
+	var auditLogger *audit.Logger
+	if auditSink != nil {
+		auditLogger = audit.NewLogger(auditSink, logger.Logger)
+	} else {
+		logger.Warn("Audit log is disabled")
+	}
+	defer auditLogger.Close()
+
+	// Requests through the API gateway carry an identity signed with the shared secret
+	identitySigner, err := security.NewIdentitySigner(cfg.Auth.IdentitySecret, cfg.Auth.RetiredIdentitySecrets, cfg.Auth.IdentityTTL)
+	if err != nil {
+		logger.WithError(err).Error("Invalid internal identity secret")
+		os.Exit(1)
+	}
+
+	// Initialize security middleware
+	securityMiddleware := security.NewSecurityMiddleware(&security.SecurityConfig{
+		JWTSecret:     cfg.Auth.JWTSecret,
+		JWTExpiration: cfg.Auth.JWTExpiry,
+		JWTIssuer:     cfg.Auth.JWTIssuer,
+		TrustedProxies: cfg.Server.TrustedProxies,
+		Audit:          auditLogger,
+		Identity:       identitySigner,
+	}, logger.Logger, redisClient)
+	defer securityMiddleware.Close()
+	metricsCollector.RegisterCollectors(securityMiddleware.Collectors()...)
+
+	// Uploads and guardian invite codes are limited more strictly than the
+	// other writes
+	metricsHandler.SetRateLimits(
+		securityMiddleware.RateLimitWith(security.RateLimitPolicyUpload),
+		securityMiddleware.RateLimitWith(security.RateLimitPolicyAuth),
+	)
+
+	// Initialize TLS (nil when disabled)
+	tlsManager, err := tlsutil.NewManager(cfg.TLS, logger.Logger)
+	if err != nil {
+		logger.WithError(err).Error("Failed to initialize TLS")
+		os.Exit(1)
+	}
+	defer tlsManager.Close()
+
+	// Initialize object storage for videos and data exports
+	var objects storage.ObjectStore
+	if cfg.External.S3AccessKey != "" {
+		objects, err = storage.NewS3ObjectStore(cfg.External, nil)
+		if err != nil {
+			logger.WithError(err).Error("Failed to initialize object storage")
+			os.Exit(1)
+		}
+	} else {
+		logger.Warn("No S3 credentials configured, data exports are kept in memory")
+		objects = storage.NewMemoryObjectStore()
+	}
+
+	// Process data export and deletion requests in the background
+	dataRequests := gdpr.NewManager(
+		gdpr.NewRequestStore(redisClient),
+		objects,
+		gdpr.Config{
+			DeletionGracePeriod: cfg.Privacy.DeletionGracePeriod,
+			ExportRetention:     cfg.Privacy.ExportRetention,
+			ExportBucket:        cfg.External.S3BucketExports,
+		},
+		auditLogger,
+		logger.Logger,
+		gdpr.NewMetricsSource(store),
+		gdpr.NewVideoSource(objects, cfg.External.S3BucketVideos),
+	)
+	dataRequests.OnErased(func(ctx context.Context, userID string) error {
+		return securityMiddleware.Revocations().RevokeUserTokens(ctx, userID, time.Now())
+	})
+	dataRequests.SetGuardianCheck(store.IsGuardianOf)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go dataRequests.Run(workerCtx)
 
 	// Set up Gin router
 	if cfg.Server.Environment == "production" {
@@ -64,13 +242,21 @@ func main() {
 	}
 
 	router := gin.New()
+	if err := securityMiddleware.ConfigureEngine(router); err != nil {
+		logger.WithError(err).Error("Failed to configure trusted proxies")
+		os.Exit(1)
+	}
 
 	// Add middleware
+	router.Use(securityMiddleware.TrustedProxies())
 	router.Use(gin.Recovery())
-	router.Use(metricsCollector.HTTPMiddleware)
+	router.Use(metricsCollector.GinMiddleware())
 	router.Use(requestIDMiddleware())
+	router.Use(securityMiddleware.GatewayIdentity())
+	router.Use(securityMiddleware.IPFilter())
 	router.Use(loggingMiddleware(logger))
 	router.Use(corsMiddleware())
+	router.Use(security.BodyLimit(cfg.Server.MaxBodyBytes))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -84,27 +270,51 @@ func main() {
 	// Metrics endpoint for Prometheus
 	router.GET("/metrics", gin.WrapH(metricsCollector.Handler()))
 
-	// API routes
+	// API routes. Rate limits are mounted after authentication in each group
+	// so that per-user policies see the user.
 	v1 := router.Group("/api/v1")
 	{
 		// Jump metrics endpoints
-		v1.POST("/jumps", metricsService.CreateJumpMetric)
-		v1.GET("/jumps", metricsService.GetJumpMetrics)
-		v1.GET("/jumps/:id", metricsService.GetJumpMetric)
-		v1.PUT("/jumps/:id", metricsService.UpdateJumpMetric)
-		v1.DELETE("/jumps/:id", metricsService.DeleteJumpMetric)
+		legacy := v1.Group("", securityMiddleware.RateLimitByMethod())
+		legacy.POST("/jumps", metricsService.CreateJumpMetric)
+		legacy.GET("/jumps", metricsService.GetJumpMetrics)
+		legacy.GET("/jumps/:id", metricsService.GetJumpMetric)
+		legacy.PUT("/jumps/:id", metricsService.UpdateJumpMetric)
+		legacy.DELETE("/jumps/:id", metricsService.DeleteJumpMetric)
 
 		// User metrics endpoints
-		v1.GET("/users/:user_id/jumps", metricsService.GetUserJumpMetrics)
-		v1.GET("/users/:user_id/stats", metricsService.GetUserStats)
-		v1.GET("/users/:user_id/personal-best", metricsService.GetPersonalBest)
+		legacy.GET("/users/:user_id/jumps", metricsService.GetUserJumpMetrics)
+		legacy.GET("/users/:user_id/stats", metricsService.GetUserStats)
+		legacy.GET("/users/:user_id/personal-best", metricsService.GetPersonalBest)
 
 		// Analytics endpoints
-		v1.GET("/analytics/daily", metricsService.GetDailyAnalytics)
-		v1.GET("/analytics/weekly", metricsService.GetWeeklyAnalytics)
-		v1.GET("/analytics/monthly", metricsService.GetMonthlyAnalytics)
+		legacy.GET("/analytics/daily", metricsService.GetDailyAnalytics)
+		legacy.GET("/analytics/weekly", metricsService.GetWeeklyAnalytics)
+		legacy.GET("/analytics/monthly", metricsService.GetMonthlyAnalytics)
+
+		// Device registration and signed metric uploads
+		devices := v1.Group("/devices", securityMiddleware.JWTAuth(), securityMiddleware.RateLimitByMethod())
+		securityMiddleware.RegisterDeviceRoutes(devices)
+
+		uploads := v1.Group("", securityMiddleware.JWTAuth(), securityMiddleware.RateLimitByMethod(), securityMiddleware.DeviceSignature(), apiVersions.Middleware(), apiValidator.Middleware())
+		metricsHandler.RegisterRoutes(uploads)
+
+		// Security administration endpoints
+		admin := v1.Group("/admin", securityMiddleware.JWTAuth(), securityMiddleware.RequireRole("admin"), securityMiddleware.RateLimitByMethod(), apiValidator.Middleware())
+		securityMiddleware.RegisterAdminRoutes(admin)
+		dataRequests.RegisterAdminRoutes(admin)
+		metricsHandler.RegisterTrainingRoutes(admin)
+
+		// Data export and account deletion
+		privacy := v1.Group("/privacy", securityMiddleware.JWTAuth(), securityMiddleware.RateLimitByMethod())
+		dataRequests.RegisterRoutes(privacy)
+		dataRequests.RegisterGuardianRoutes(privacy.Group("/guardian"))
 	}
 
+	// API document generated from the routes above
+	apiDocument := openapi.Generate(metrics.APIInfo, router.Routes(), apiRoutes)
+	router.GET("/openapi.json", apiDocument.Handler())
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         cfg.Server.HTTPPort,
@@ -118,31 +328,82 @@ func main() {
 		logger.Info("Starting HTTP server", map[string]interface{}{
 			"port":        cfg.Server.HTTPPort,
 			"environment": cfg.Server.Environment,
+			"tls":         tlsManager.Enabled(),
+			"mtls":        cfg.TLS.RequireClientCert,
 		})
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := tlsManager.ListenAndServe(server); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Error("Failed to start HTTP server")
 			os.Exit(1)
 		}
 	}()
 
+	// gRPC API alongside HTTP, authenticated and rate limited like the REST routes.
+	// The health service answers without credentials for load balancers.
+	grpcAuth := security.GRPCAuthConfig{
+		Public:       []string{"/" + healthpb.Health_ServiceDesc.ServiceName + "/"},
+		WriteMethods: metrics.GRPCWriteMethods,
+	}
+	grpcServer := grpc.NewServer(
+		tlsManager.GRPCServerOption(),
+		grpc.MaxRecvMsgSize(metrics.MaxSubmitBodyBytes),
+		grpc.ChainUnaryInterceptor(securityMiddleware.UnaryServerInterceptor(grpcAuth)),
+		grpc.ChainStreamInterceptor(securityMiddleware.StreamServerInterceptor(grpcAuth)),
+	)
+	metrics.NewGRPCServer(store, logger.Logger).Register(grpcServer)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(metricsv1.MetricsService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	grpcListener, err := net.Listen("tcp", cfg.Server.GRPCPort)
+	if err != nil {
+		logger.WithError(err).Error("Failed to listen for gRPC")
+		os.Exit(1)
+	}
+	go func() {
+		logger.WithFields(map[string]interface{}{
+			"port": cfg.Server.GRPCPort,
+			"tls":  tlsManager.Enabled(),
+		}).Info("Starting gRPC server")
+
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.WithError(err).Error("Failed to start gRPC server")
+			os.Exit(1)
+		}
+	}()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopWorkers()
 
 	// Create a deadline for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Report NOT_SERVING, then let running calls and streams finish
+	healthServer.Shutdown()
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
 	// Shutdown server gracefully
 	if err := server.Shutdown(ctx); err != nil {
 		logger.WithError(err).Error("Server forced to shutdown")
 		os.Exit(1)
 	}
 
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
 	logger.Info("Server exited")
 }
 
@@ -193,7 +454,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Request-ID, API-Version")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -7,10 +7,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/monitoring"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/tlsutil"
 )
 
 func main() {
@@ -20,8 +26,29 @@ func main() {
 		os.Exit(0)
 	}
 
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %s\n", err)
+	}
+	defer logger.Sync()
+
+	// Initialize TLS (nil when disabled)
+	tlsManager, err := tlsutil.NewManager(config.LoadTLSConfig(), logger)
+	if err != nil {
+		log.Fatalf("failed to initialize TLS: %s\n", err)
+	}
+	defer tlsManager.Close()
+
+	// Initialize metrics
+	metricsCollector := monitoring.NewMetrics()
+
 	// Set up Gin router
 	r := gin.Default()
+	if err := security.ApplyTrustedProxies(r, strings.Split(os.Getenv("TRUSTED_PROXIES"), ",")); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %s\n", err)
+	}
+	r.Use(metricsCollector.GinMiddleware())
+	r.Use(security.BodyLimit(config.LoadMaxBodyBytes()))
 
 	// Health endpoints
 	r.GET("/health", func(c *gin.Context) {
@@ -55,9 +82,7 @@ func main() {
 	})
 
 	// Metrics endpoint
-	r.GET("/metrics", func(c *gin.Context) {
-		c.String(http.StatusOK, "# ML Pipeline Metrics\nml_requests_total 0\n")
-	})
+	r.GET("/metrics", gin.WrapH(metricsCollector.Handler()))
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -72,12 +97,12 @@ func main() {
 
 	// Graceful shutdown
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := tlsManager.ListenAndServe(srv); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("listen: %s\n", err)
 		}
 	}()
 
-	log.Printf("ML Pipeline service started on port %s", port)
+	log.Printf("ML Pipeline service started on port %s (tls=%t)", port, tlsManager.Enabled())
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
@@ -0,0 +1,392 @@
+package apiversion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Header selects the API version of a request. Responses carry the version
+// they were served in.
+const Header = "API-Version"
+
+// contextKey is the Gin context key of the version a request is served in
+const contextKey = "api_version"
+
+// Version is an API version, numbered from 1
+type Version int
+
+func (v Version) String() string {
+	return strconv.Itoa(int(v))
+}
+
+// Deprecation announces that a version is going away. It is sent with every
+// response served in that version.
+type Deprecation struct {
+	// Since is when the version was deprecated, sent as the Deprecation header
+	Since time.Time
+	// Sunset is when the version stops being served, sent as the Sunset header when set
+	Sunset time.Time
+	// Link points to the migration guide, sent as a Link with rel="deprecation" when set
+	Link string
+}
+
+// Change converts the bodies of one route between the version that introduced
+// the change and the version before it. Either direction may be nil when only
+// requests or only responses changed.
+type Change struct {
+	Method string
+	Path   string // in Gin syntax, e.g. /api/v1/athletes/:athlete_id/metrics
+	// Upgrade converts a request body of the previous version
+	Upgrade func(c *gin.Context, body []byte) ([]byte, error)
+	// Downgrade converts a successful response body to the previous version.
+	// Error responses are the same in every version and are not converted.
+	Downgrade func(c *gin.Context, body []byte) ([]byte, error)
+}
+
+// Config configures the versions a service serves
+type Config struct {
+	// Latest is the version the handlers and their models speak
+	Latest Version
+	// Default is the version of requests without an API-Version header
+	Default Version
+	// Deprecated versions are served with deprecation headers
+	Deprecated map[Version]Deprecation
+	// Changes lists the route changes each version introduced over the one before it
+	Changes map[Version][]Change
+}
+
+// Versions negotiates the API version of requests and converts the bodies of
+// changed routes between the requested version and the latest one, so
+// handlers only ever see the latest models
+type Versions struct {
+	config   Config
+	routes   map[string][]versionedChange // method and path -> changes by ascending version
+	requests *prometheus.CounterVec
+	logger   *zap.Logger
+}
+
+type versionedChange struct {
+	version Version
+	Change
+}
+
+// New creates the versions of a service. It panics on a configuration that
+// cannot be served, as the routes are fixed at compile time.
+func New(config Config, logger *zap.Logger) *Versions {
+	if config.Latest < 1 || config.Default < 1 || config.Default > config.Latest {
+		panic(fmt.Sprintf("apiversion: default version %d is not between 1 and latest version %d", config.Default, config.Latest))
+	}
+
+	v := &Versions{
+		config: config,
+		routes: map[string][]versionedChange{},
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "api_version_requests_total",
+				Help: "Requests by the API version they were served in",
+			},
+			[]string{"version"},
+		),
+		logger: logger,
+	}
+	for version, changes := range config.Changes {
+		if version < 2 || version > config.Latest {
+			panic(fmt.Sprintf("apiversion: changes of version %d outside 2 to %d", version, config.Latest))
+		}
+		for _, change := range changes {
+			key := change.Method + " " + change.Path
+			v.routes[key] = append(v.routes[key], versionedChange{version: version, Change: change})
+		}
+	}
+	for _, changes := range v.routes {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].version < changes[j].version })
+	}
+	return v
+}
+
+// Collectors returns the Prometheus collectors of the versions
+func (v *Versions) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{v.requests}
+}
+
+// FromContext returns the version a request is served in, 0 when the request
+// did not pass through Middleware
+func FromContext(c *gin.Context) Version {
+	version, _ := c.Get(contextKey)
+	v, _ := version.(Version)
+	return v
+}
+
+// Middleware resolves the version of each request from the API-Version
+// header. Request bodies of changed routes are upgraded to the latest version
+// before the handler runs, and successful responses are downgraded to the
+// requested version afterwards, with an entity tag of that version. Routes are
+// looked up by their Gin path, so the middleware is installed on a group:
+// after middleware that needs the body as the client sent it, such as
+// signature checks, and before validation against the latest models.
+func (v *Versions) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", Header)
+		version, ok := v.resolve(c.GetHeader(Header))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Unsupported API version, supported versions are 1 to %d", v.config.Latest),
+				"code":  "unsupported_api_version",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(contextKey, version)
+		c.Header(Header, version.String())
+		v.requests.WithLabelValues(version.String()).Inc()
+		if deprecation, deprecated := v.config.Deprecated[version]; deprecated {
+			setDeprecationHeaders(c, deprecation)
+		}
+
+		changes := v.changesSince(c.Request.Method+" "+c.FullPath(), version)
+		if len(changes) == 0 {
+			c.Next()
+			return
+		}
+		if !upgradeRequest(c, changes) {
+			return
+		}
+		downgrades := hasDowngrades(changes)
+		if downgrades {
+			unversionPreconditions(c.Request, version)
+		}
+
+		buffer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffer
+		c.Next()
+		c.Writer = buffer.ResponseWriter
+		if downgrades {
+			versionETag(c, buffer.status, version)
+		}
+		v.writeResponse(c, buffer, changes)
+	}
+}
+
+// resolve parses the requested version, falling back to the default
+func (v *Versions) resolve(requested string) (Version, bool) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return v.config.Default, true
+	}
+	n, err := strconv.Atoi(requested)
+	if err != nil || n < 1 || Version(n) > v.config.Latest {
+		return 0, false
+	}
+	return Version(n), true
+}
+
+// changesSince returns the changes of a route made after version
+func (v *Versions) changesSince(route string, version Version) []versionedChange {
+	changes := v.routes[route]
+	for i, change := range changes {
+		if change.version > version {
+			return changes[i:]
+		}
+	}
+	return nil
+}
+
+func setDeprecationHeaders(c *gin.Context, deprecation Deprecation) {
+	// RFC 9745 and RFC 8594
+	c.Header("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+	if !deprecation.Sunset.IsZero() {
+		c.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if deprecation.Link != "" {
+		c.Writer.Header().Add("Link", "<"+deprecation.Link+`>; rel="deprecation"`)
+	}
+}
+
+// upgradeRequest converts the request body through every change, oldest first
+func upgradeRequest(c *gin.Context, changes []versionedChange) bool {
+	upgrades := false
+	for _, change := range changes {
+		upgrades = upgrades || change.Upgrade != nil
+	}
+	if !upgrades || c.Request.Body == nil {
+		return true
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large", "code": "body_too_large"})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body", "code": "invalid_body"})
+		}
+		c.Abort()
+		return false
+	}
+
+	for _, change := range changes {
+		if change.Upgrade == nil {
+			continue
+		}
+		if body, err = change.Upgrade(c, body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "code": "invalid_body"})
+			c.Abort()
+			return false
+		}
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return true
+}
+
+// etagVersionSeparator separates an entity tag of the latest version from the
+// version whose representation it stands for, e.g. "3f2a-v1"
+const etagVersionSeparator = "-v"
+
+func hasDowngrades(changes []versionedChange) bool {
+	for _, change := range changes {
+		if change.Downgrade != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// versionETag gives a downgraded response an entity tag of its own, as its
+// body differs from that of the latest version. Not Modified responses carry
+// the tag of the representation the client holds.
+func versionETag(c *gin.Context, status int, version Version) {
+	etag := c.Writer.Header().Get("ETag")
+	if etag == "" || !strings.HasSuffix(etag, `"`) {
+		return
+	}
+	if (status < 200 || status >= 300) && status != http.StatusNotModified {
+		return
+	}
+	c.Writer.Header().Set("ETag", strings.TrimSuffix(etag, `"`)+etagVersionSeparator+version.String()+`"`)
+}
+
+// unversionPreconditions turns the entity tags a client of a version
+// revalidates with back into those of the latest version, which the handlers
+// compare them with. Tags of other versions are dropped, as they describe
+// another representation and must never match.
+func unversionPreconditions(req *http.Request, version Version) {
+	value := req.Header.Get("If-None-Match")
+	if value == "" {
+		return
+	}
+
+	suffix := etagVersionSeparator + version.String() + `"`
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "*":
+			tags = append(tags, tag)
+		case strings.HasSuffix(tag, suffix):
+			tags = append(tags, strings.TrimSuffix(tag, suffix)+`"`)
+		}
+	}
+	if len(tags) == 0 {
+		req.Header.Del("If-None-Match")
+		return
+	}
+	req.Header.Set("If-None-Match", strings.Join(tags, ", "))
+}
+
+// writeResponse converts a buffered successful response through every
+// change, newest first, and sends it
+func (v *Versions) writeResponse(c *gin.Context, buffer *bufferedWriter, changes []versionedChange) {
+	if !buffer.written {
+		// Gin writes the header of responses without a body after the handlers
+		c.Writer.WriteHeader(buffer.status)
+		return
+	}
+
+	body := buffer.body.Bytes()
+	if buffer.status >= 200 && buffer.status < 300 && len(body) > 0 {
+		var err error
+		for i := len(changes) - 1; i >= 0; i-- {
+			if changes[i].Downgrade == nil {
+				continue
+			}
+			if body, err = changes[i].Downgrade(c, body); err != nil {
+				v.logger.Error("Failed to convert response to the requested API version",
+					zap.String("method", c.Request.Method),
+					zap.String("route", c.FullPath()),
+					zap.Stringer("version", FromContext(c)),
+					zap.Error(err),
+				)
+				c.Writer.Header().Del("ETag")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response", "code": "internal_error"})
+				return
+			}
+		}
+	}
+
+	c.Writer.Header().Del("Content-Length")
+	c.Writer.WriteHeader(buffer.status)
+	if len(body) == 0 {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Writer.Write(body)
+}
+
+// bufferedWriter holds back the status and body of a response until they are
+// converted. Headers are written through, they are the same in every version.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
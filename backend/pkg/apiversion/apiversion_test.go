@@ -0,0 +1,242 @@
+package apiversion
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// renameField returns a change of one JSON field between the name of the
+// previous version and the name of the changing version
+func renameField(from, to string) func(c *gin.Context, body []byte) ([]byte, error) {
+	return func(c *gin.Context, body []byte) ([]byte, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, err
+		}
+		if value, ok := fields[from]; ok {
+			fields[to] = value
+			delete(fields, from)
+		}
+		return json.Marshal(fields)
+	}
+}
+
+// testRouter serves three versions of an echo route: version 2 renamed
+// "height" to "height_cm" and version 3 renamed "height_cm" to "jump_height_cm"
+func testRouter() *gin.Engine {
+	versions := New(Config{
+		Latest:  3,
+		Default: 1,
+		Deprecated: map[Version]Deprecation{
+			1: {
+				Since:  time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+				Sunset: time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+				Link:   "https://docs.example.com/api/versions",
+			},
+		},
+		Changes: map[Version][]Change{
+			3: {{
+				Method:    http.MethodPost,
+				Path:      "/jumps",
+				Upgrade:   renameField("height_cm", "jump_height_cm"),
+				Downgrade: renameField("jump_height_cm", "height_cm"),
+			}},
+			2: {{
+				Method:    http.MethodPost,
+				Path:      "/jumps",
+				Upgrade:   renameField("height", "height_cm"),
+				Downgrade: renameField("height_cm", "height"),
+			}},
+		},
+	}, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("", versions.Middleware())
+	group.POST("/jumps", func(c *gin.Context) {
+		var jump map[string]interface{}
+		if err := c.ShouldBindJSON(&jump); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if _, ok := jump["jump_height_cm"]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "jump_height_cm is required"})
+			return
+		}
+		c.Header("ETag", `"jump"`)
+		c.JSON(http.StatusCreated, jump)
+	})
+	group.GET("/version", func(c *gin.Context) {
+		c.String(http.StatusOK, FromContext(c).String())
+	})
+	return router
+}
+
+func serve(router http.Handler, method, path, version, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if version != "" {
+		req.Header.Set(Header, version)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware_Negotiation(t *testing.T) {
+	router := testRouter()
+
+	w := serve(router, http.MethodGet, "/version", "", "")
+	assert.Equal(t, "1", w.Body.String())
+	assert.Equal(t, "1", w.Header().Get(Header))
+	assert.Equal(t, Header, w.Header().Get("Vary"))
+
+	w = serve(router, http.MethodGet, "/version", "3", "")
+	assert.Equal(t, "3", w.Body.String())
+	assert.Equal(t, "3", w.Header().Get(Header))
+
+	for _, version := range []string{"0", "4", "v2", "latest"} {
+		w = serve(router, http.MethodGet, "/version", version, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, version)
+		assert.Contains(t, w.Body.String(), "unsupported_api_version")
+	}
+}
+
+func TestMiddleware_DeprecationHeaders(t *testing.T) {
+	router := testRouter()
+
+	w := serve(router, http.MethodGet, "/version", "1", "")
+	assert.Equal(t, "@1790812800", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `<https://docs.example.com/api/versions>; rel="deprecation"`, w.Header().Get("Link"))
+
+	w = serve(router, http.MethodGet, "/version", "2", "")
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
+
+func TestMiddleware_ConvertsThroughEveryVersion(t *testing.T) {
+	router := testRouter()
+
+	for version, field := range map[string]string{"1": "height", "2": "height_cm", "3": "jump_height_cm"} {
+		w := serve(router, http.MethodPost, "/jumps", version, `{"`+field+`": 61.5}`)
+		require.Equal(t, http.StatusCreated, w.Code, version)
+		assert.JSONEq(t, `{"`+field+`": 61.5}`, w.Body.String(), version)
+	}
+}
+
+func TestMiddleware_ETagsPerVersion(t *testing.T) {
+	router := testRouter()
+
+	// Downgraded bodies differ from the latest one and so do their tags
+	etags := map[string]string{"1": `"jump-v1"`, "2": `"jump-v2"`, "3": `"jump"`}
+	for version, etag := range etags {
+		w := serve(router, http.MethodPost, "/jumps", version, `{"jump_height_cm": 61.5, "height_cm": 61.5, "height": 61.5}`)
+		require.Equal(t, http.StatusCreated, w.Code, version)
+		assert.Equal(t, etag, w.Header().Get("ETag"), version)
+	}
+
+	versions := New(Config{
+		Latest:  2,
+		Default: 1,
+		Changes: map[Version][]Change{
+			2: {{Method: http.MethodGet, Path: "/jumps", Downgrade: renameField("jumps", "items")}},
+		},
+	}, zap.NewNop())
+	gin.SetMode(gin.TestMode)
+	cached := gin.New()
+	cached.GET("/jumps", versions.Middleware(), func(c *gin.Context) {
+		c.Header("ETag", `"jumps"`)
+		if c.GetHeader("If-None-Match") == `"jumps"` {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"jumps": []string{}})
+	})
+	revalidate := func(version, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/jumps", nil)
+		req.Header.Set(Header, version)
+		req.Header.Set("If-None-Match", etag)
+		w := httptest.NewRecorder()
+		cached.ServeHTTP(w, req)
+		return w
+	}
+
+	// Clients revalidate with the tag of their version
+	w := revalidate("1", `"jumps-v1"`)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, `"jumps-v1"`, w.Header().Get("ETag"))
+	w = revalidate("2", `"jumps"`)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// A tag of another version never matches
+	w = revalidate("1", `"jumps"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items": []}`, w.Body.String())
+	assert.Equal(t, `"jumps-v1"`, w.Header().Get("ETag"))
+	w = revalidate("2", `"jumps-v1"`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"jumps"`, w.Header().Get("ETag"))
+}
+
+func TestMiddleware_ErrorsAreNotConverted(t *testing.T) {
+	router := testRouter()
+
+	// Upgraded, but still not what the handler expects
+	w := serve(router, http.MethodPost, "/jumps", "1", `{"jump": 61.5}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "jump_height_cm is required"}`, w.Body.String())
+
+	// Bodies that cannot be upgraded never reach the handler
+	w = serve(router, http.MethodPost, "/jumps", "1", `not json`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_body")
+}
+
+func TestMiddleware_DowngradeFailure(t *testing.T) {
+	versions := New(Config{
+		Latest:  2,
+		Default: 2,
+		Changes: map[Version][]Change{
+			2: {{
+				Method: http.MethodGet,
+				Path:   "/jumps",
+				Downgrade: func(c *gin.Context, body []byte) ([]byte, error) {
+					return nil, errors.New("cannot downgrade")
+				},
+			}},
+		},
+	}, zap.NewNop())
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jumps", versions.Middleware(), func(c *gin.Context) {
+		c.Header("ETag", `"jumps"`)
+		c.JSON(http.StatusOK, []string{})
+	})
+
+	w := serve(router, http.MethodGet, "/jumps", "1", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	w = serve(router, http.MethodGet, "/jumps", "2", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	body, _ := io.ReadAll(w.Body)
+	assert.Equal(t, "[]", string(body))
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	assert.Panics(t, func() { New(Config{Latest: 2, Default: 3}, zap.NewNop()) })
+	assert.Panics(t, func() { New(Config{Latest: 2, Default: 1, Changes: map[Version][]Change{1: nil}}, zap.NewNop()) })
+}
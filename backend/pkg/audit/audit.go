@@ -0,0 +1,288 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/logging"
+)
+
+// EventType identifies what an audit entry records
+type EventType string
+
+const (
+	EventSignIn            EventType = "sign_in"
+	EventSignInFailed      EventType = "sign_in_failed"
+	EventTokenRevoked      EventType = "token_revoked"
+	EventUserTokensRevoked EventType = "user_tokens_revoked"
+	EventRoleChanged       EventType = "role_changed"
+	EventAPIKeyCreated     EventType = "api_key_created"
+	EventAPIKeyRotated     EventType = "api_key_rotated"
+	EventAPIKeyRevoked     EventType = "api_key_revoked"
+	EventBanLifted         EventType = "ban_lifted"
+	EventDeviceRegistered  EventType = "device_registered"
+	EventDeviceRevoked     EventType = "device_revoked"
+	EventDataExport        EventType = "data_export"
+	EventDataDeletion      EventType = "data_deletion"
+)
+
+// GenesisHash is the previous hash of the first entry in a chain
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+	maxAppendAttempts = 5
+)
+
+var (
+	// ErrConflict is returned by a sink when an entry with the same sequence already exists
+	ErrConflict = errors.New("audit entry sequence already exists")
+)
+
+// Event is a security-relevant action to record
+type Event struct {
+	Type      EventType
+	ActorID   string // who performed the action
+	UserID    string // whose account or data it affected
+	IPAddress string
+	Metadata  map[string]string
+}
+
+// Entry is a recorded event. Each entry carries the hash of the previous one,
+// so editing or removing an entry breaks every hash after it.
+type Entry struct {
+	Sequence  int64             `json:"sequence" bson:"_id"`
+	Timestamp time.Time         `json:"timestamp" bson:"timestamp"`
+	Type      EventType         `json:"type" bson:"type"`
+	ActorID   string            `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	UserID    string            `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty" bson:"ip_address,omitempty"`
+	RequestID string            `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	PrevHash  string            `json:"prev_hash" bson:"prev_hash"`
+	Hash      string            `json:"hash" bson:"hash"`
+}
+
+// ComputeHash returns the hash of the entry's content and previous hash
+func (e *Entry) ComputeHash() string {
+	h := sha256.New()
+	write := func(value string) {
+		// Length prefixes keep field boundaries unambiguous
+		h.Write([]byte(strconv.Itoa(len(value))))
+		h.Write([]byte{':'})
+		h.Write([]byte(value))
+	}
+
+	write(strconv.FormatInt(e.Sequence, 10))
+	write(e.Timestamp.UTC().Format(time.RFC3339Nano))
+	write(string(e.Type))
+	write(e.ActorID)
+	write(e.UserID)
+	write(e.IPAddress)
+	write(e.RequestID)
+
+	keys := make([]string, 0, len(e.Metadata))
+	for key := range e.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	write(strconv.Itoa(len(keys)))
+	for _, key := range keys {
+		write(key)
+		write(e.Metadata[key])
+	}
+
+	write(e.PrevHash)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Query selects entries by user and time range
+type Query struct {
+	UserID string // matches entries where the user is the actor or the subject
+	From   time.Time
+	To     time.Time
+	Type   EventType
+	Limit  int
+}
+
+// Matches reports whether an entry satisfies the query
+func (q Query) Matches(e *Entry) bool {
+	if q.UserID != "" && e.UserID != q.UserID && e.ActorID != q.UserID {
+		return false
+	}
+	if q.Type != "" && e.Type != q.Type {
+		return false
+	}
+	if !q.From.IsZero() && e.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && e.Timestamp.After(q.To) {
+		return false
+	}
+	return true
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return defaultQueryLimit
+	}
+	if q.Limit > maxQueryLimit {
+		return maxQueryLimit
+	}
+	return q.Limit
+}
+
+// Sink stores audit entries separately from the application log
+type Sink interface {
+	// Append stores an entry, returning ErrConflict if its sequence is taken
+	Append(ctx context.Context, entry *Entry) error
+	// Last returns the entry with the highest sequence, or nil when empty
+	Last(ctx context.Context) (*Entry, error)
+	// Query returns matching entries, newest first
+	Query(ctx context.Context, query Query) ([]Entry, error)
+	// Scan calls fn for every entry in sequence order
+	Scan(ctx context.Context, fn func(*Entry) error) error
+	Close() error
+}
+
+// Verification is the result of checking a chain
+type Verification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int64  `json:"entries"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Logger appends hash-chained entries to a sink. A nil Logger records nothing.
+type Logger struct {
+	sink   Sink
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	head *Entry
+}
+
+// NewLogger creates an audit logger
+func NewLogger(sink Sink, logger *zap.Logger) *Logger {
+	return &Logger{sink: sink, logger: logger}
+}
+
+// Record appends an event to the audit trail
+func (l *Logger) Record(ctx context.Context, event Event) (*Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := &Entry{
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond), // Mongo stores milliseconds
+		Type:      event.Type,
+		ActorID:   event.ActorID,
+		UserID:    event.UserID,
+		IPAddress: event.IPAddress,
+		Metadata:  event.Metadata,
+	}
+	if requestID, ok := ctx.Value(logging.RequestIDKey).(string); ok {
+		entry.RequestID = requestID
+	}
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		if l.head == nil || attempt > 0 {
+			// Another instance may have extended the chain
+			head, err := l.sink.Last(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+			}
+			l.head = head
+		}
+
+		entry.Sequence, entry.PrevHash = 1, GenesisHash
+		if l.head != nil {
+			entry.Sequence, entry.PrevHash = l.head.Sequence+1, l.head.Hash
+		}
+		entry.Hash = entry.ComputeHash()
+
+		err := l.sink.Append(ctx, entry)
+		if errors.Is(err, ErrConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to append audit entry: %w", err)
+		}
+
+		l.head = entry
+		return entry, nil
+	}
+
+	return nil, fmt.Errorf("failed to append audit entry: %w", ErrConflict)
+}
+
+// Query returns entries matching the query, newest first
+func (l *Logger) Query(ctx context.Context, query Query) ([]Entry, error) {
+	if l == nil {
+		return []Entry{}, nil
+	}
+	query.Limit = query.limit()
+	return l.sink.Query(ctx, query)
+}
+
+// Verify walks the whole chain and reports the first entry that does not
+// match its hash or does not link to its predecessor
+func (l *Logger) Verify(ctx context.Context) (*Verification, error) {
+	result := &Verification{Valid: true}
+	if l == nil {
+		return result, nil
+	}
+
+	prevHash := GenesisHash
+	err := l.sink.Scan(ctx, func(entry *Entry) error {
+		result.Entries++
+		switch {
+		case entry.Sequence != result.Entries:
+			result.Reason = fmt.Sprintf("expected sequence %d, found %d", result.Entries, entry.Sequence)
+		case entry.PrevHash != prevHash:
+			result.Reason = "previous hash does not match"
+		case entry.ComputeHash() != entry.Hash:
+			result.Reason = "entry hash does not match its content"
+		default:
+			prevHash = entry.Hash
+			return nil
+		}
+		result.Valid = false
+		result.BrokenAt = result.Entries
+		return errStopScan
+	})
+	if err != nil && !errors.Is(err, errStopScan) {
+		return nil, fmt.Errorf("failed to scan audit log: %w", err)
+	}
+
+	if !result.Valid {
+		l.logger.Error("Audit log chain is broken",
+			zap.Int64("sequence", result.BrokenAt),
+			zap.String("reason", result.Reason),
+		)
+	}
+	return result, nil
+}
+
+// Close closes the sink
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.sink.Close()
+}
+
+var errStopScan = errors.New("stop scan")
@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestLogger(t *testing.T) (*Logger, string) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { sink.Close() })
+	return NewLogger(sink, zap.NewNop()), path
+}
+
+func TestLogger_RecordChainsEntries(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := newTestLogger(t)
+
+	first, err := logger.Record(ctx, Event{Type: EventSignIn, ActorID: "user-1", UserID: "user-1", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	second, err := logger.Record(ctx, Event{Type: EventTokenRevoked, ActorID: "admin", Metadata: map[string]string{"jti": "abc"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), first.Sequence)
+	assert.Equal(t, GenesisHash, first.PrevHash)
+	assert.Equal(t, int64(2), second.Sequence)
+	assert.Equal(t, first.Hash, second.PrevHash)
+	assert.Equal(t, second.ComputeHash(), second.Hash)
+
+	result, err := logger.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(2), result.Entries)
+}
+
+func TestLogger_VerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	logger, path := newTestLogger(t)
+
+	for _, user := range []string{"user-1", "user-2", "user-3"} {
+		_, err := logger.Record(ctx, Event{Type: EventDataExport, UserID: user})
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(data), `"user_id":"user-2"`, `"user_id":"user-9"`, 1)
+	require.NoError(t, os.WriteFile(path, []byte(tampered), 0600))
+
+	result, err := logger.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenAt)
+	assert.Equal(t, "entry hash does not match its content", result.Reason)
+}
+
+func TestLogger_VerifyDetectsRemovedEntry(t *testing.T) {
+	ctx := context.Background()
+	logger, path := newTestLogger(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := logger.Record(ctx, Event{Type: EventSignIn, UserID: "user-1"})
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(string(data), "\n")
+	require.NoError(t, os.WriteFile(path, []byte(lines[0]+lines[2]), 0600))
+
+	result, err := logger.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.BrokenAt)
+}
+
+func TestFileSink_ReopenContinuesChain(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	first, err := NewLogger(sink, zap.NewNop()).Record(ctx, Event{Type: EventSignIn, UserID: "user-1"})
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	defer sink.Close()
+	logger := NewLogger(sink, zap.NewNop())
+
+	second, err := logger.Record(ctx, Event{Type: EventSignIn, UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second.Sequence)
+	assert.Equal(t, first.Hash, second.PrevHash)
+
+	result, err := logger.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
+
+func TestLogger_Query(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := newTestLogger(t)
+
+	_, err := logger.Record(ctx, Event{Type: EventSignIn, ActorID: "user-1", UserID: "user-1"})
+	require.NoError(t, err)
+	_, err = logger.Record(ctx, Event{Type: EventSignIn, ActorID: "user-2", UserID: "user-2"})
+	require.NoError(t, err)
+	_, err = logger.Record(ctx, Event{Type: EventUserTokensRevoked, ActorID: "admin", UserID: "user-1"})
+	require.NoError(t, err)
+
+	entries, err := logger.Query(ctx, Query{UserID: "user-1"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EventUserTokensRevoked, entries[0].Type, "newest entries come first")
+	assert.Equal(t, EventSignIn, entries[1].Type)
+
+	entries, err = logger.Query(ctx, Query{UserID: "admin", Type: EventUserTokensRevoked})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = logger.Query(ctx, Query{From: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	entries, err = logger.Query(ctx, Query{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLogger_Nil(t *testing.T) {
+	var logger *Logger
+
+	entry, err := logger.Record(context.Background(), Event{Type: EventSignIn})
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+
+	result, err := logger.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
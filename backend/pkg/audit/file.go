@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// maxLineBytes bounds a single JSON line read back from the file
+const maxLineBytes = 1 << 20
+
+// FileSink appends entries as JSON lines to a file opened in append-only mode
+type FileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+	last *Entry
+}
+
+// NewFileSink opens or creates an audit file and restores the chain head from it
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	sink := &FileSink{path: path, file: file}
+	err = sink.Scan(context.Background(), func(entry *Entry) error {
+		sink.last = entry
+		return nil
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return sink, nil
+}
+
+// Append writes an entry and syncs it to disk
+func (s *FileSink) Append(ctx context.Context, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last != nil && entry.Sequence <= s.last.Sequence {
+		return ErrConflict
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	stored := *entry
+	s.last = &stored
+	return nil
+}
+
+// Last returns the most recently written entry
+func (s *FileSink) Last(ctx context.Context) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last == nil {
+		return nil, nil
+	}
+	last := *s.last
+	return &last, nil
+}
+
+// Query scans the file for matching entries
+func (s *FileSink) Query(ctx context.Context, query Query) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	err := s.Scan(ctx, func(entry *Entry) error {
+		if query.Matches(entry) {
+			entries = append(entries, *entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Sequence > entries[j].Sequence })
+	if limit := query.limit(); len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Scan reads the file from the start
+func (s *FileSink) Scan(ctx context.Context, fn func(*Entry) error) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("malformed audit entry on line %d: %w", line, err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionName is the default collection of the Mongo sink
+const CollectionName = "audit_log"
+
+// MongoSink stores entries in a dedicated collection keyed by sequence, so
+// concurrent writers on several instances cannot fork the chain
+type MongoSink struct {
+	collection *mongo.Collection
+}
+
+// NewMongoSink creates a Mongo sink and its indexes
+func NewMongoSink(ctx context.Context, collection *mongo.Collection) (*MongoSink, error) {
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit indexes: %w", err)
+	}
+	return &MongoSink{collection: collection}, nil
+}
+
+// Append inserts an entry
+func (s *MongoSink) Append(ctx context.Context, entry *Entry) error {
+	_, err := s.collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// Last returns the entry with the highest sequence
+func (s *MongoSink) Last(ctx context.Context) (*Entry, error) {
+	var entry Entry
+	err := s.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find last audit entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// Query finds matching entries, newest first
+func (s *MongoSink) Query(ctx context.Context, query Query) ([]Entry, error) {
+	filter := bson.M{}
+	if query.UserID != "" {
+		filter["$or"] = []bson.M{{"user_id": query.UserID}, {"actor_id": query.UserID}}
+	}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+	timeRange := bson.M{}
+	if !query.From.IsZero() {
+		timeRange["$gte"] = query.From
+	}
+	if !query.To.IsZero() {
+		timeRange["$lte"] = query.To
+	}
+	if len(timeRange) > 0 {
+		filter["timestamp"] = timeRange
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(query.limit()))
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]Entry, 0)
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Scan iterates over all entries in sequence order
+func (s *MongoSink) Scan(ctx context.Context, fn func(*Entry) error) error {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("failed to scan audit log: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry Entry
+		if err := cursor.Decode(&entry); err != nil {
+			return fmt.Errorf("failed to decode audit entry: %w", err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// Close is a no-op; the client is owned by the caller
+func (s *MongoSink) Close() error {
+	return nil
+}
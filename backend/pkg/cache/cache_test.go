@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type countingRecorder map[string]int
+
+func (r countingRecorder) RecordCacheOperation(operation, result string) {
+	r[operation+":"+result]++
+}
+
+func TestMemoryStore_Generations(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(10)
+
+	entry, generation, err := store.Lookup(ctx, "athlete-1", "summary?")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// A write that lands between the lookup and the store makes the entry stale
+	require.NoError(t, store.Invalidate(ctx, "athlete-1"))
+	require.NoError(t, store.Set(ctx, "athlete-1", generation, "summary?", NewEntry([]byte(`{}`), time.Now()), time.Minute))
+	entry, generation, _ = store.Lookup(ctx, "athlete-1", "summary?")
+	assert.Nil(t, entry)
+
+	require.NoError(t, store.Set(ctx, "athlete-1", generation, "summary?", NewEntry([]byte(`{}`), time.Now()), time.Minute))
+	entry, _, _ = store.Lookup(ctx, "athlete-1", "summary?")
+	require.NotNil(t, entry)
+	assert.Equal(t, []byte(`{}`), entry.Body)
+
+	// Other athletes are not affected by an invalidation
+	_, other, _ := store.Lookup(ctx, "athlete-2", "summary?")
+	store.Set(ctx, "athlete-2", other, "summary?", NewEntry([]byte(`[]`), time.Now()), time.Minute)
+	store.Invalidate(ctx, "athlete-1")
+	entry, _, _ = store.Lookup(ctx, "athlete-2", "summary?")
+	assert.NotNil(t, entry)
+	entry, _, _ = store.Lookup(ctx, "athlete-1", "summary?")
+	assert.Nil(t, entry)
+}
+
+func TestMemoryStore_Bounded(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(2)
+	for _, scope := range []string{"a", "b", "c"} {
+		store.Set(ctx, scope, 0, "k", NewEntry([]byte(`1`), time.Now()), time.Minute)
+	}
+	assert.LessOrEqual(t, store.size, 2)
+	entry, _, _ := store.Lookup(ctx, "c", "k")
+	assert.NotNil(t, entry)
+}
+
+func TestStore_FallsBackWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	store := NewStore(client, 10, zap.NewNop())
+
+	entry, generation, err := store.Lookup(ctx, "athlete-1", "summary?")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	require.NoError(t, store.Set(ctx, "athlete-1", generation, "summary?", NewEntry([]byte(`{}`), time.Now()), time.Minute))
+
+	entry, _, err = store.Lookup(ctx, "athlete-1", "summary?")
+	require.NoError(t, err)
+	assert.NotNil(t, entry)
+
+	// Invalidations reach the fallback even though Redis reports the failure
+	assert.Error(t, store.Invalidate(ctx, "athlete-1"))
+	entry, _, _ = store.Lookup(ctx, "athlete-1", "summary?")
+	assert.Nil(t, entry)
+}
+
+func TestResponder_ConditionalRequests(t *testing.T) {
+	recorder := countingRecorder{}
+	responder := NewResponder(NewMemoryStore(10), time.Minute, recorder, zap.NewNop())
+
+	loads := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/athletes/:id/summary", func(c *gin.Context) {
+		err := responder.JSON(c, "athlete_summary", c.Param("id"), func(ctx context.Context) (interface{}, error) {
+			loads++
+			if c.Param("id") == "broken" {
+				return nil, errors.New("database unavailable")
+			}
+			return gin.H{"athlete": c.Param("id"), "loads": loads}, nil
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get summary"})
+		}
+	})
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("/athletes/a1/summary", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, first.Header().Get("Last-Modified"))
+	assert.JSONEq(t, `{"athlete": "a1", "loads": 1}`, first.Body.String())
+
+	// Served from the cache, and revalidated without a body
+	second := get("/athletes/a1/summary", nil)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	notModified := get("/athletes/a1/summary", http.Header{"If-None-Match": {`"other", ` + etag}})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	notModified = get("/athletes/a1/summary", http.Header{"If-Modified-Since": {first.Header().Get("Last-Modified")}})
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Equal(t, 1, loads)
+
+	// The query is part of the key
+	get("/athletes/a1/summary?period=month", nil)
+	assert.Equal(t, 2, loads)
+
+	// After a write the next request loads again and gets a new ETag
+	require.NoError(t, responder.Invalidate(context.Background(), "a1"))
+	changed := get("/athletes/a1/summary", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+
+	failed := get("/athletes/broken/summary", nil)
+	assert.Equal(t, http.StatusInternalServerError, failed.Code)
+	assert.Empty(t, failed.Header().Get("ETag"))
+
+	assert.Equal(t, 3, recorder["athlete_summary:hit"])
+	assert.Equal(t, 4, recorder["athlete_summary:miss"])
+}
+
+func TestResponder_Nil(t *testing.T) {
+	var responder *Responder
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := responder.JSON(c, "athlete_summary", "a1", func(ctx context.Context) (interface{}, error) {
+		return []int{1}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "[1]", w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.NoError(t, responder.Invalidate(context.Background(), "a1"))
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Recorder receives cache hits and misses, e.g. monitoring.Metrics
+type Recorder interface {
+	RecordCacheOperation(operation, result string)
+}
+
+// Responder serves JSON responses from a Store and answers conditional
+// requests. A nil Responder loads every response and still sets validators.
+type Responder struct {
+	store    Store
+	ttl      time.Duration
+	recorder Recorder
+	logger   *zap.Logger
+}
+
+// NewResponder creates a responder caching responses for ttl
+func NewResponder(store Store, ttl time.Duration, recorder Recorder, logger *zap.Logger) *Responder {
+	return &Responder{store: store, ttl: ttl, recorder: recorder, logger: logger}
+}
+
+// Invalidate drops every cached response of scope
+func (r *Responder) Invalidate(ctx context.Context, scope string) error {
+	if r == nil {
+		return nil
+	}
+	return r.store.Invalidate(ctx, scope)
+}
+
+// JSON writes the response of operation for scope, keyed by the request's
+// query. load runs only on a miss; when it fails nothing is written and the
+// error is returned for the caller to answer. Callers must authorize the
+// request before calling JSON, since cached responses are shared by everyone
+// allowed to read scope.
+func (r *Responder) JSON(c *gin.Context, operation, scope string, load func(ctx context.Context) (interface{}, error)) error {
+	key := operation + "?" + c.Request.URL.Query().Encode()
+
+	var generation int64
+	if r != nil {
+		entry, gen, err := r.store.Lookup(c.Request.Context(), scope, key)
+		if err != nil {
+			r.logger.Error("Response cache lookup failed", zap.String("operation", operation), zap.Error(err))
+		}
+		if entry != nil {
+			r.record(operation, "hit")
+			serve(c, entry)
+			return nil
+		}
+		r.record(operation, "miss")
+		generation = gen
+	}
+
+	value, err := load(c.Request.Context())
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	entry := NewEntry(body, time.Now())
+
+	if r != nil && r.ttl > 0 {
+		if err := r.store.Set(c.Request.Context(), scope, generation, key, entry, r.ttl); err != nil {
+			r.logger.Error("Response cache write failed", zap.String("operation", operation), zap.Error(err))
+		}
+	}
+	serve(c, entry)
+	return nil
+}
+
+func (r *Responder) record(operation, result string) {
+	if r.recorder != nil {
+		r.recorder.RecordCacheOperation(operation, result)
+	}
+}
+
+// NewEntry creates an entry with a strong ETag derived from body
+func NewEntry(body []byte, modified time.Time) *Entry {
+	sum := sha256.Sum256(body)
+	return &Entry{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: modified.UTC().Truncate(time.Second),
+	}
+}
+
+// serve writes entry, or 304 when the request's validators match it
+func serve(c *gin.Context, entry *Entry) {
+	c.Header("ETag", entry.ETag)
+	c.Header("Last-Modified", entry.LastModified.Format(http.TimeFormat))
+	// Responses are per caller and must be revalidated before reuse
+	c.Header("Cache-Control", "private, no-cache")
+
+	if NotModified(c.Request, entry) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", entry.Body)
+}
+
+// NotModified reports whether the client's copy is still current. If-None-Match
+// takes precedence over If-Modified-Since, as in RFC 9110.
+func NotModified(req *http.Request, entry *Entry) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == entry.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := req.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && !entry.LastModified.After(t)
+	}
+	return false
+}
@@ -0,0 +1,271 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// redisRetryBackoff is how long the in-process fallback is used after a Redis error
+const redisRetryBackoff = 5 * time.Second
+
+// generationTTL outlives every entry, so an expired generation counter can
+// never bring back entries written under an earlier value
+const generationTTL = 7 * 24 * time.Hour
+
+// Entry is a cached response with its validators
+type Entry struct {
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Store keeps entries grouped by scope, e.g. an athlete. Every scope has a
+// generation that Invalidate advances; entries are written under the
+// generation seen by the lookup that missed, so a response computed before a
+// write can never be stored as current after it.
+type Store interface {
+	// Lookup returns the entry for key, or nil, and the scope's generation
+	Lookup(ctx context.Context, scope, key string) (*Entry, int64, error)
+	// Set stores an entry computed at generation
+	Set(ctx context.Context, scope string, generation int64, key string, entry *Entry, ttl time.Duration) error
+	// Invalidate drops every entry of scope
+	Invalidate(ctx context.Context, scope string) error
+}
+
+// NewStore returns a Redis-backed store that falls back to process memory while
+// Redis is unavailable, or a memory store when client is nil
+func NewStore(client *redis.Client, maxEntries int, logger *zap.Logger) Store {
+	memory := NewMemoryStore(maxEntries)
+	if client == nil {
+		return memory
+	}
+	return &fallbackStore{primary: &RedisStore{client: client}, fallback: memory, logger: logger}
+}
+
+// RedisStore shares entries between replicas
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a Redis-backed store
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func generationKey(scope string) string {
+	return "response_cache:" + scope + ":generation"
+}
+
+func entryKey(scope string, generation int64, key string) string {
+	return fmt.Sprintf("response_cache:%s:%d:%s", scope, generation, key)
+}
+
+// Lookup returns the entry for key and the scope's generation
+func (s *RedisStore) Lookup(ctx context.Context, scope, key string) (*Entry, int64, error) {
+	generation, err := s.client.Get(ctx, generationKey(scope)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, fmt.Errorf("failed to read cache generation: %w", err)
+	}
+
+	data, err := s.client.Get(ctx, entryKey(scope, generation, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, generation, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, generation, nil
+	}
+	return &entry, generation, nil
+}
+
+// Set stores an entry computed at generation
+func (s *RedisStore) Set(ctx context.Context, scope string, generation int64, key string, entry *Entry, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, entryKey(scope, generation, key), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Invalidate advances the scope's generation; old entries expire on their own
+func (s *RedisStore) Invalidate(ctx context.Context, scope string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Incr(ctx, generationKey(scope))
+	pipe.Expire(ctx, generationKey(scope), generationTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+	return nil
+}
+
+// MemoryStore keeps entries in process memory, bounded by maxEntries
+type MemoryStore struct {
+	mu         sync.Mutex
+	scopes     map[string]*memoryScope
+	size       int
+	maxEntries int
+}
+
+type memoryScope struct {
+	generation int64
+	entries    map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	entry   Entry
+	expires time.Time
+}
+
+// NewMemoryStore creates an in-process store
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &MemoryStore{scopes: make(map[string]*memoryScope), maxEntries: maxEntries}
+}
+
+// Lookup returns the entry for key and the scope's generation
+func (s *MemoryStore) Lookup(ctx context.Context, scope, key string) (*Entry, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, exists := s.scopes[scope]
+	if !exists {
+		return nil, 0, nil
+	}
+	cached, exists := sc.entries[key]
+	if !exists || time.Now().After(cached.expires) {
+		return nil, sc.generation, nil
+	}
+	entry := cached.entry
+	return &entry, sc.generation, nil
+}
+
+// Set stores an entry unless the scope was invalidated since generation was read
+func (s *MemoryStore) Set(ctx context.Context, scope string, generation int64, key string, entry *Entry, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, exists := s.scopes[scope]
+	if !exists {
+		sc = &memoryScope{entries: make(map[string]memoryEntry)}
+		s.scopes[scope] = sc
+	}
+	if sc.generation != generation {
+		return nil
+	}
+	if _, exists := sc.entries[key]; !exists {
+		if s.size >= s.maxEntries {
+			s.evict()
+		}
+		s.size++
+	}
+	sc.entries[key] = memoryEntry{entry: *entry, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Invalidate drops the scope's entries and advances its generation
+func (s *MemoryStore) Invalidate(ctx context.Context, scope string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sc, exists := s.scopes[scope]
+	if !exists {
+		sc = &memoryScope{}
+		s.scopes[scope] = sc
+	}
+	s.size -= len(sc.entries)
+	sc.generation++
+	sc.entries = make(map[string]memoryEntry)
+	return nil
+}
+
+// evict drops expired entries, or an arbitrary scope when none expired.
+// Scopes without entries keep their generation so in-flight lookups stay safe.
+func (s *MemoryStore) evict() {
+	now := time.Now()
+	for _, sc := range s.scopes {
+		for key, cached := range sc.entries {
+			if now.After(cached.expires) {
+				delete(sc.entries, key)
+				s.size--
+			}
+		}
+	}
+	if s.size < s.maxEntries {
+		return
+	}
+	for _, sc := range s.scopes {
+		if len(sc.entries) > 0 {
+			s.size -= len(sc.entries)
+			sc.entries = make(map[string]memoryEntry)
+			return
+		}
+	}
+}
+
+// fallbackStore uses the memory store while Redis is failing. After an error
+// Redis is not retried for redisRetryBackoff so an outage does not add a failed
+// round trip to every request. Invalidations always reach the memory store.
+type fallbackStore struct {
+	primary  *RedisStore
+	fallback *MemoryStore
+	logger   *zap.Logger
+	retryAt  atomic.Int64
+}
+
+func (s *fallbackStore) available() bool {
+	return time.Now().UnixNano() >= s.retryAt.Load()
+}
+
+func (s *fallbackStore) failed(err error) {
+	s.retryAt.Store(time.Now().Add(redisRetryBackoff).UnixNano())
+	s.logger.Error("Redis response cache error, using in-process fallback", zap.Error(err))
+}
+
+func (s *fallbackStore) Lookup(ctx context.Context, scope, key string) (*Entry, int64, error) {
+	if s.available() {
+		entry, generation, err := s.primary.Lookup(ctx, scope, key)
+		if err == nil {
+			return entry, generation, nil
+		}
+		s.failed(err)
+	}
+	// Fallback generations are negative so Set can tell which store they came from
+	entry, generation, err := s.fallback.Lookup(ctx, scope, key)
+	return entry, -generation - 1, err
+}
+
+func (s *fallbackStore) Set(ctx context.Context, scope string, generation int64, key string, entry *Entry, ttl time.Duration) error {
+	if generation < 0 {
+		return s.fallback.Set(ctx, scope, -generation-1, key, entry, ttl)
+	}
+	if err := s.primary.Set(ctx, scope, generation, key, entry, ttl); err != nil {
+		s.failed(err)
+	}
+	return nil
+}
+
+func (s *fallbackStore) Invalidate(ctx context.Context, scope string) error {
+	s.fallback.Invalidate(ctx, scope)
+	if err := s.primary.Invalidate(ctx, scope); err != nil {
+		s.failed(err)
+		return err
+	}
+	return nil
+}
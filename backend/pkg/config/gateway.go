@@ -0,0 +1,487 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Authentication modes of a gateway route
+const (
+	GatewayAuthRequired = "required" // an API key or JWT is required
+	GatewayAuthOptional = "optional" // credentials are verified when present
+	GatewayAuthNone     = "none"     // credentials are not checked, e.g. for sign-in
+)
+
+// GatewayRateLimitNone disables rate limiting on a gateway route
+const GatewayRateLimitNone = "none"
+
+// Rate limit policies of the security package the default routes apply
+const (
+	gatewayRateLimitUpload = "upload" // per user per hour
+	gatewayRateLimitAuth   = "auth"   // per client IP, for endpoints handing out or redeeming credentials
+)
+
+// GatewaySecurityConfig holds the settings the gateway needs to authenticate,
+// rate limit and filter requests before proxying them
+type GatewaySecurityConfig struct {
+	Auth           AuthConfig
+	RedisURL       string
+	TrustedProxies []string
+	AllowedIPs     []string
+	BlockedIPs     []string
+}
+
+// LoadGatewaySecurityConfig loads the gateway security settings from the environment
+func LoadGatewaySecurityConfig() GatewaySecurityConfig {
+	return GatewaySecurityConfig{
+		Auth:           LoadAuthConfig(),
+		RedisURL:       getEnv("REDIS_URL", "redis://localhost:6379"),
+		TrustedProxies: getSliceEnv("TRUSTED_PROXIES", nil),
+		AllowedIPs:     getSliceEnv("GATEWAY_ALLOWED_IPS", nil),
+		BlockedIPs:     getSliceEnv("GATEWAY_BLOCKED_IPS", nil),
+	}
+}
+
+// GatewayConfig describes the routes the API gateway proxies
+type GatewayConfig struct {
+	Routes []GatewayRoute `json:"routes"`
+	Home   HomeConfig     `json:"home"`
+
+	File           string        `json:"-"` // GATEWAY_CONFIG_FILE, watched for changes when set
+	ReloadInterval time.Duration `json:"-"`
+}
+
+// GatewayRoute sends requests under Prefix to a pool of upstreams
+type GatewayRoute struct {
+	Prefix                string            `json:"prefix"`
+	Upstreams             []string          `json:"upstreams"`               // base URLs, e.g. http://metrics-svc:8080
+	StripPrefix           bool              `json:"strip_prefix"`            // forward /prefix/x as /x
+	Timeout               Duration          `json:"timeout"`                 // whole upstream exchange, including streamed bodies
+	MaxBodyBytes          int64             `json:"max_body_bytes"`          // defaults to MAX_BODY_BYTES
+	SetHeaders            map[string]string `json:"set_headers"`             // added to upstream requests
+	RemoveHeaders         []string          `json:"remove_headers"`          // removed from upstream requests
+	RemoveResponseHeaders []string          `json:"remove_response_headers"` // removed from responses to clients
+	Auth                  string            `json:"auth"`                    // required, optional or none
+	RateLimit             string            `json:"rate_limit"`              // policy name, by_method or none
+
+	Retry            RetryPolicy          `json:"retry"`
+	CircuitBreaker   CircuitBreakerPolicy `json:"circuit_breaker"`
+	OutlierDetection OutlierPolicy        `json:"outlier_detection"`
+
+	// Variants take part of the route's traffic from Upstreams, e.g. a canary
+	Variants []RouteVariant `json:"variants"`
+}
+
+// StableVariant names the route's own upstreams in metrics and overrides
+const StableVariant = "stable"
+
+// RouteVariant is an alternative set of upstreams for a route. Callers are
+// assigned by a hash of their user ID, so each user keeps seeing the same
+// variant while its weight stays the same.
+type RouteVariant struct {
+	Name      string   `json:"name"`
+	Upstreams []string `json:"upstreams"`
+	Weight    int      `json:"weight"` // percent of callers, 0-100
+	Users     []string `json:"users"`  // user IDs always sent here, e.g. internal testers
+}
+
+// HomeConfig describes the /api/v1/home endpoint, which composes the app's home
+// screen from several upstream calls made concurrently
+type HomeConfig struct {
+	Sections        []HomeSection `json:"sections"`  // none disables the endpoint
+	CacheTTL        Duration      `json:"cache_ttl"` // how long a complete response is reused for the same user
+	CacheMaxEntries int           `json:"cache_max_entries"`
+}
+
+// HomeSection is one upstream call of the home endpoint
+type HomeSection struct {
+	Name    string   `json:"name"`    // key of the section in the response
+	Route   string   `json:"route"`   // prefix of the route whose upstreams serve the call
+	Path    string   `json:"path"`    // upstream path and query; {user_id} is replaced with the caller
+	Timeout Duration `json:"timeout"` // deadline for the call, after which the section is reported as timed out
+}
+
+// RetryPolicy retries idempotent requests without a body on another upstream.
+// Retries are limited by a budget that earns BudgetRatio retries per request,
+// holding at most BudgetBurst.
+type RetryPolicy struct {
+	Attempts      int      `json:"attempts"`        // retries after the first attempt; 0 disables
+	PerTryTimeout Duration `json:"per_try_timeout"` // 0 leaves only the route timeout
+	BaseBackoff   Duration `json:"base_backoff"`
+	MaxBackoff    Duration `json:"max_backoff"`
+	BudgetRatio   float64  `json:"budget_ratio"`
+	BudgetBurst   int      `json:"budget_burst"`
+}
+
+// CircuitBreakerPolicy stops sending requests to an upstream whose failure ratio
+// within Window reaches FailureRatio, and probes it again after OpenTimeout
+type CircuitBreakerPolicy struct {
+	FailureRatio  float64  `json:"failure_ratio"` // 0 disables
+	MinRequests   int      `json:"min_requests"`
+	Window        Duration `json:"window"`
+	OpenTimeout   Duration `json:"open_timeout"`
+	MaxConcurrent int      `json:"max_concurrent"` // requests in flight per upstream; 0 is unlimited
+}
+
+// OutlierPolicy ejects an upstream from its pool after consecutive failures.
+// Ejections last BaseEjectionTime times the number of recent ejections.
+type OutlierPolicy struct {
+	ConsecutiveFailures int      `json:"consecutive_failures"` // 0 disables
+	BaseEjectionTime    Duration `json:"base_ejection_time"`
+	MaxEjectionTime     Duration `json:"max_ejection_time"`
+	MaxEjectedPercent   int      `json:"max_ejected_percent"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" in JSON
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\"")
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadGatewayConfig reads the routes from GATEWAY_CONFIG_FILE, or builds the
+// default routes from the environment: /api/v1/ml to the ML service and the
+// rest of /api/v1 to the metrics service
+func LoadGatewayConfig() (*GatewayConfig, error) {
+	if path := os.Getenv("GATEWAY_CONFIG_FILE"); path != "" {
+		cfg, err := ReadGatewayConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ReloadInterval = getDurationEnv("GATEWAY_CONFIG_RELOAD_INTERVAL", 30*time.Second)
+		return cfg, nil
+	}
+
+	// Outlier detection and breakers keep a slow or failing instance from
+	// holding up requests that other instances could serve
+	breaker := CircuitBreakerPolicy{FailureRatio: 0.5, MinRequests: 20}
+	outliers := OutlierPolicy{ConsecutiveFailures: 5}
+
+	// Metrics, athletes, guardians, devices, leaderboard and privacy requests
+	metricsRoute := GatewayRoute{
+		Prefix:           "/api/v1",
+		Upstreams:        getSliceEnv("METRICS_SERVICE_URLS", []string{"http://localhost:8080"}),
+		Timeout:          Duration(getDurationEnv("METRICS_SERVICE_TIMEOUT", 30*time.Second)),
+		Retry:            RetryPolicy{Attempts: 2, PerTryTimeout: Duration(5 * time.Second)},
+		CircuitBreaker:   breaker,
+		OutlierDetection: outliers,
+	}
+	// Metric submissions fall under the stricter upload policy
+	uploadsRoute := metricsRoute
+	uploadsRoute.Prefix = "/api/v1/metrics"
+	uploadsRoute.RateLimit = gatewayRateLimitUpload
+	// Device registration issues secrets and guardian invite codes can be guessed
+	devicesRoute := metricsRoute
+	devicesRoute.Prefix = "/api/v1/devices"
+	devicesRoute.RateLimit = gatewayRateLimitAuth
+	guardianRoute := metricsRoute
+	guardianRoute.Prefix = "/api/v1/guardian/accept"
+	guardianRoute.RateLimit = gatewayRateLimitAuth
+
+	sectionTimeout := Duration(getDurationEnv("HOME_SECTION_TIMEOUT", 2*time.Second))
+	cfg := &GatewayConfig{Routes: []GatewayRoute{
+		metricsRoute,
+		uploadsRoute,
+		devicesRoute,
+		guardianRoute,
+		{
+			// Video uploads for processing
+			Prefix:           "/api/v1/ml",
+			Upstreams:        getSliceEnv("ML_SERVICE_URLS", []string{"http://localhost:8081"}),
+			StripPrefix:      true,
+			Timeout:          Duration(getDurationEnv("ML_SERVICE_TIMEOUT", 2*time.Minute)),
+			MaxBodyBytes:     getInt64Env("ML_MAX_UPLOAD_BYTES", 512<<20),
+			RateLimit:        gatewayRateLimitUpload,
+			Retry:            RetryPolicy{Attempts: 1},
+			CircuitBreaker:   CircuitBreakerPolicy{FailureRatio: 0.5, MinRequests: 20, MaxConcurrent: int(getInt64Env("ML_MAX_CONCURRENT", 64))},
+			OutlierDetection: outliers,
+		},
+	}, Home: HomeConfig{
+		Sections: []HomeSection{
+			{Name: "recent_metrics", Route: "/api/v1", Path: "/api/v1/athletes/{user_id}/metrics", Timeout: sectionTimeout},
+			{Name: "personal_best", Route: "/api/v1", Path: "/api/v1/users/{user_id}/personal-best", Timeout: sectionTimeout},
+			{Name: "weekly_stats", Route: "/api/v1", Path: "/api/v1/athletes/{user_id}/summary", Timeout: sectionTimeout},
+		},
+		CacheTTL: Duration(getDurationEnv("HOME_CACHE_TTL", 10*time.Second)),
+	}}
+
+	// Challenges are served by the community service when one is deployed
+	if community := getSliceEnv("COMMUNITY_SERVICE_URLS", nil); len(community) > 0 {
+		cfg.Routes = append(cfg.Routes, GatewayRoute{
+			Prefix:           "/api/v1/community",
+			Upstreams:        community,
+			Timeout:          Duration(getDurationEnv("COMMUNITY_SERVICE_TIMEOUT", 30*time.Second)),
+			Retry:            RetryPolicy{Attempts: 2, PerTryTimeout: Duration(5 * time.Second)},
+			CircuitBreaker:   breaker,
+			OutlierDetection: outliers,
+		})
+		cfg.Home.Sections = append(cfg.Home.Sections, HomeSection{
+			Name: "challenges", Route: "/api/v1/community", Path: "/api/v1/community/challenges", Timeout: sectionTimeout,
+		})
+	}
+
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadGatewayConfig reads a JSON gateway configuration file
+func ReadGatewayConfig(path string) (*GatewayConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway config: %w", err)
+	}
+	cfg, err := ParseGatewayConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg.File = path
+	return cfg, nil
+}
+
+// ParseGatewayConfig decodes and validates a JSON gateway configuration
+func ParseGatewayConfig(data []byte) (*GatewayConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var cfg GatewayConfig
+	if err := decoder.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("invalid gateway config: %w", err)
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// normalize applies defaults and validates every route
+func (c *GatewayConfig) normalize() error {
+	if len(c.Routes) == 0 {
+		return fmt.Errorf("gateway config has no routes")
+	}
+
+	seen := make(map[string]bool)
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		route.Prefix = strings.TrimSuffix(route.Prefix, "/")
+		if !strings.HasPrefix(route.Prefix, "/") {
+			return fmt.Errorf("route %d: prefix must start with /", i)
+		}
+		if seen[route.Prefix] {
+			return fmt.Errorf("route %s is defined twice", route.Prefix)
+		}
+		seen[route.Prefix] = true
+
+		if len(route.Upstreams) == 0 {
+			return fmt.Errorf("route %s has no upstreams", route.Prefix)
+		}
+		if err := validateUpstreams(route.Upstreams); err != nil {
+			return fmt.Errorf("route %s: %w", route.Prefix, err)
+		}
+		if err := route.validateVariants(); err != nil {
+			return fmt.Errorf("route %s: %w", route.Prefix, err)
+		}
+
+		if route.Timeout <= 0 {
+			route.Timeout = Duration(30 * time.Second)
+		}
+		if route.MaxBodyBytes <= 0 {
+			route.MaxBodyBytes = LoadMaxBodyBytes()
+		}
+
+		switch route.Auth {
+		case "":
+			route.Auth = GatewayAuthRequired
+		case GatewayAuthRequired, GatewayAuthOptional, GatewayAuthNone:
+		default:
+			return fmt.Errorf("route %s: auth must be required, optional or none", route.Prefix)
+		}
+		if route.RateLimit == "" {
+			route.RateLimit = "by_method"
+		}
+		if err := route.normalizePolicies(); err != nil {
+			return fmt.Errorf("route %s: %w", route.Prefix, err)
+		}
+	}
+	return c.normalizeHome()
+}
+
+func validateUpstreams(upstreams []string) error {
+	for _, upstream := range upstreams {
+		u, err := url.Parse(upstream)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid upstream %q", upstream)
+		}
+	}
+	return nil
+}
+
+// validateVariants checks that variants are named uniquely, have upstreams of
+// their own and weights that add up to at most 100
+func (r *GatewayRoute) validateVariants() error {
+	names := map[string]bool{StableVariant: true}
+	upstreams := make(map[string]bool)
+	for _, upstream := range r.Upstreams {
+		upstreams[upstream] = true
+	}
+	total := 0
+	for i, variant := range r.Variants {
+		if variant.Name == "" {
+			return fmt.Errorf("variant %d has no name", i)
+		}
+		if names[variant.Name] {
+			return fmt.Errorf("variant %s is defined twice or reserved", variant.Name)
+		}
+		names[variant.Name] = true
+
+		if len(variant.Upstreams) == 0 {
+			return fmt.Errorf("variant %s has no upstreams", variant.Name)
+		}
+		if err := validateUpstreams(variant.Upstreams); err != nil {
+			return fmt.Errorf("variant %s: %w", variant.Name, err)
+		}
+		for _, upstream := range variant.Upstreams {
+			if upstreams[upstream] {
+				return fmt.Errorf("variant %s: upstream %s already serves another variant", variant.Name, upstream)
+			}
+			upstreams[upstream] = true
+		}
+		if variant.Weight < 0 || variant.Weight > 100 {
+			return fmt.Errorf("variant %s: weight must be between 0 and 100", variant.Name)
+		}
+		total += variant.Weight
+	}
+	if total > 100 {
+		return fmt.Errorf("variant weights add up to more than 100")
+	}
+	return nil
+}
+
+// normalizeHome validates the home sections against the routes and applies defaults
+func (c *GatewayConfig) normalizeHome() error {
+	home := &c.Home
+	if home.CacheTTL < 0 || home.CacheMaxEntries < 0 {
+		return fmt.Errorf("home cache settings must not be negative")
+	}
+	if home.CacheTTL == 0 {
+		home.CacheTTL = Duration(10 * time.Second)
+	}
+	if home.CacheMaxEntries == 0 {
+		home.CacheMaxEntries = 10000
+	}
+
+	routes := make(map[string]*GatewayRoute, len(c.Routes))
+	for i := range c.Routes {
+		routes[c.Routes[i].Prefix] = &c.Routes[i]
+	}
+	names := make(map[string]bool)
+	for i := range home.Sections {
+		section := &home.Sections[i]
+		if section.Name == "" {
+			return fmt.Errorf("home section %d has no name", i)
+		}
+		if names[section.Name] {
+			return fmt.Errorf("home section %s is defined twice", section.Name)
+		}
+		names[section.Name] = true
+
+		route, exists := routes[strings.TrimSuffix(section.Route, "/")]
+		if !exists {
+			return fmt.Errorf("home section %s: no route %q", section.Name, section.Route)
+		}
+		section.Route = route.Prefix
+		if !strings.HasPrefix(section.Path, "/") {
+			return fmt.Errorf("home section %s: path must start with /", section.Name)
+		}
+		if _, err := url.ParseRequestURI(strings.ReplaceAll(section.Path, "{user_id}", "user")); err != nil {
+			return fmt.Errorf("home section %s: invalid path: %w", section.Name, err)
+		}
+		if section.Timeout <= 0 {
+			section.Timeout = Duration(2 * time.Second)
+		}
+		if section.Timeout > route.Timeout {
+			section.Timeout = route.Timeout
+		}
+	}
+	return nil
+}
+
+// normalizePolicies validates the resilience policies and fills in the tuning
+// defaults; the policies themselves stay disabled unless configured
+func (r *GatewayRoute) normalizePolicies() error {
+	retry := &r.Retry
+	if retry.Attempts < 0 || retry.BudgetRatio < 0 || retry.BudgetBurst < 0 {
+		return fmt.Errorf("retry settings must not be negative")
+	}
+	if retry.BaseBackoff <= 0 {
+		retry.BaseBackoff = Duration(25 * time.Millisecond)
+	}
+	if retry.MaxBackoff <= 0 {
+		retry.MaxBackoff = Duration(250 * time.Millisecond)
+	}
+	if retry.MaxBackoff < retry.BaseBackoff {
+		retry.MaxBackoff = retry.BaseBackoff
+	}
+	if retry.BudgetRatio == 0 {
+		retry.BudgetRatio = 0.2
+	}
+	if retry.BudgetBurst == 0 {
+		retry.BudgetBurst = 10
+	}
+
+	breaker := &r.CircuitBreaker
+	if breaker.FailureRatio < 0 || breaker.FailureRatio > 1 {
+		return fmt.Errorf("circuit breaker failure_ratio must be between 0 and 1")
+	}
+	if breaker.MaxConcurrent < 0 || breaker.MinRequests < 0 {
+		return fmt.Errorf("circuit breaker settings must not be negative")
+	}
+	if breaker.MinRequests == 0 {
+		breaker.MinRequests = 20
+	}
+	if breaker.Window <= 0 {
+		breaker.Window = Duration(10 * time.Second)
+	}
+	if breaker.OpenTimeout <= 0 {
+		breaker.OpenTimeout = Duration(30 * time.Second)
+	}
+
+	outliers := &r.OutlierDetection
+	if outliers.ConsecutiveFailures < 0 || outliers.MaxEjectedPercent < 0 || outliers.MaxEjectedPercent > 100 {
+		return fmt.Errorf("outlier detection settings are out of range")
+	}
+	if outliers.BaseEjectionTime <= 0 {
+		outliers.BaseEjectionTime = Duration(30 * time.Second)
+	}
+	if outliers.MaxEjectionTime <= 0 {
+		outliers.MaxEjectionTime = Duration(5 * time.Minute)
+	}
+	if outliers.MaxEjectionTime < outliers.BaseEjectionTime {
+		outliers.MaxEjectionTime = outliers.BaseEjectionTime
+	}
+	if outliers.MaxEjectedPercent == 0 {
+		outliers.MaxEjectedPercent = 50
+	}
+	return nil
+}
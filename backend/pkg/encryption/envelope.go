@@ -0,0 +1,244 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// Algorithm identifies the envelope format stored with every encrypted field
+const Algorithm = "AES256-GCM"
+
+const keySize = 32
+
+var (
+	ErrUnknownKey       = errors.New("encryption key not found")
+	ErrInvalidEnvelope  = errors.New("invalid encrypted field")
+	ErrDecryptionFailed = errors.New("decryption failed")
+)
+
+// Envelope is an encrypted field value. The value is encrypted with a random
+// data key, and the data key is wrapped with the master key named by KeyID.
+type Envelope struct {
+	Algorithm  string `json:"alg" bson:"alg"`
+	KeyID      string `json:"kid" bson:"kid"`
+	WrappedKey []byte `json:"wk" bson:"wk"`
+	Nonce      []byte `json:"n" bson:"n"`
+	Ciphertext []byte `json:"ct" bson:"ct"`
+}
+
+// KeyRing holds the active master key and retired ones that are still needed
+// to decrypt data written before a rotation
+type KeyRing struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing creates a key ring. All keys must be 32 bytes.
+func NewKeyRing(activeID string, active []byte, retired map[string][]byte) (*KeyRing, error) {
+	if activeID == "" {
+		return nil, fmt.Errorf("master key id is required")
+	}
+
+	keys := make(map[string][]byte, len(retired)+1)
+	for id, key := range retired {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes", id, keySize)
+		}
+		keys[id] = key
+	}
+	if len(active) != keySize {
+		return nil, fmt.Errorf("master key %q must be %d bytes", activeID, keySize)
+	}
+	keys[activeID] = active
+
+	return &KeyRing{activeID: activeID, keys: keys}, nil
+}
+
+// LoadKeyRing builds a key ring from configuration. It returns nil when no
+// master key is configured, which leaves fields unencrypted.
+func LoadKeyRing(cfg config.EncryptionConfig) (*KeyRing, error) {
+	encoded := cfg.MasterKey
+	if encoded == "" && cfg.MasterKeyFile != "" {
+		data, err := os.ReadFile(cfg.MasterKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read master key file: %w", err)
+		}
+		encoded = string(data)
+	}
+	if strings.TrimSpace(encoded) == "" {
+		return nil, nil
+	}
+
+	active, err := decodeKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+
+	retired := make(map[string][]byte, len(cfg.RetiredMasterKeys))
+	for _, entry := range cfg.RetiredMasterKeys {
+		id, value, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("retired master keys must be formatted as id:base64")
+		}
+		key, err := decodeKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retired master key %q: %w", id, err)
+		}
+		retired[id] = key
+	}
+
+	return NewKeyRing(cfg.MasterKeyID, active, retired)
+}
+
+// ActiveKeyID returns the ID of the key used for new data
+func (r *KeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+// FieldEncryptor seals and opens individual field values
+type FieldEncryptor struct {
+	keys *KeyRing
+}
+
+// NewFieldEncryptor creates a field encryptor
+func NewFieldEncryptor(keys *KeyRing) *FieldEncryptor {
+	return &FieldEncryptor{keys: keys}
+}
+
+// ActiveKeyID returns the master key ID new envelopes are wrapped with
+func (e *FieldEncryptor) ActiveKeyID() string {
+	return e.keys.activeID
+}
+
+// NeedsRotation reports whether an envelope was wrapped with a retired master key
+func (e *FieldEncryptor) NeedsRotation(env *Envelope) bool {
+	return env != nil && env.KeyID != e.keys.activeID
+}
+
+// Seal JSON-encodes value and encrypts it. The associated data binds the
+// ciphertext to its location, e.g. "jump_metrics/<id>/location", so it
+// cannot be copied into another document.
+func (e *FieldEncryptor) Seal(value interface{}, associatedData string) (*Envelope, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode field: %w", err)
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	nonce, ciphertext, err := seal(dataKey, plaintext, []byte(associatedData))
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := e.keys.activeID
+	wrapNonce, wrapped, err := seal(e.keys.keys[keyID], dataKey, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		Algorithm:  Algorithm,
+		KeyID:      keyID,
+		WrappedKey: append(wrapNonce, wrapped...),
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Open decrypts an envelope into out
+func (e *FieldEncryptor) Open(env *Envelope, associatedData string, out interface{}) error {
+	if env == nil || env.Algorithm != Algorithm {
+		return ErrInvalidEnvelope
+	}
+
+	masterKey, exists := e.keys.keys[env.KeyID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownKey, env.KeyID)
+	}
+
+	dataKey, err := open(masterKey, env.WrappedKey, []byte(env.KeyID))
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := open(dataKey, append(append([]byte{}, env.Nonce...), env.Ciphertext...), []byte(associatedData))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(plaintext, out); err != nil {
+		return fmt.Errorf("failed to decode field: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts with AES-GCM under a fresh random nonce
+func seal(key, plaintext, associatedData []byte) ([]byte, []byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, associatedData), nil
+}
+
+// open decrypts a nonce-prefixed AES-GCM ciphertext
+func open(key, sealed, associatedData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// decodeKey accepts standard or URL-safe base64, padded or not
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := encoding.DecodeString(encoded); err == nil {
+			if len(key) != keySize {
+				return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key is not valid base64")
+}
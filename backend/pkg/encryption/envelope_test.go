@@ -0,0 +1,76 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+type testLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func TestFieldEncryptor_RoundTrip(t *testing.T) {
+	keys, err := NewKeyRing("v1", bytes.Repeat([]byte{1}, 32), nil)
+	require.NoError(t, err)
+	encryptor := NewFieldEncryptor(keys)
+
+	envelope, err := encryptor.Seal(testLocation{Latitude: 43.2, Longitude: 76.9}, "athlete/a1/location")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", envelope.KeyID)
+	assert.NotContains(t, string(envelope.Ciphertext), "43.2")
+
+	var location testLocation
+	require.NoError(t, encryptor.Open(envelope, "athlete/a1/location", &location))
+	assert.Equal(t, 43.2, location.Latitude)
+
+	// Ciphertext is bound to its athlete
+	assert.ErrorIs(t, encryptor.Open(envelope, "athlete/a2/location", &location), ErrDecryptionFailed)
+}
+
+func TestFieldEncryptor_Rotation(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	oldKeys, err := NewKeyRing("v1", oldKey, nil)
+	require.NoError(t, err)
+	envelope, err := NewFieldEncryptor(oldKeys).Seal("Jordan", "athlete/a1/personal")
+	require.NoError(t, err)
+
+	rotated, err := LoadKeyRing(config.EncryptionConfig{
+		MasterKey:         base64.StdEncoding.EncodeToString(newKey),
+		MasterKeyID:       "v2",
+		RetiredMasterKeys: []string{"v1:" + base64.StdEncoding.EncodeToString(oldKey)},
+	})
+	require.NoError(t, err)
+	encryptor := NewFieldEncryptor(rotated)
+	assert.True(t, encryptor.NeedsRotation(envelope))
+
+	var name string
+	require.NoError(t, encryptor.Open(envelope, "athlete/a1/personal", &name))
+	assert.Equal(t, "Jordan", name)
+
+	resealed, err := encryptor.Seal(name, "athlete/a1/personal")
+	require.NoError(t, err)
+	assert.False(t, encryptor.NeedsRotation(resealed))
+
+	// Once the old key is dropped, old envelopes can no longer be read
+	withoutOld, err := NewKeyRing("v2", newKey, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, NewFieldEncryptor(withoutOld).Open(envelope, "athlete/a1/personal", &name), ErrUnknownKey)
+}
+
+func TestLoadKeyRing_NotConfigured(t *testing.T) {
+	keys, err := LoadKeyRing(config.EncryptionConfig{MasterKeyID: "v1"})
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+
+	_, err = LoadKeyRing(config.EncryptionConfig{MasterKey: "c2hvcnQ=", MasterKeyID: "v1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+func TestGateway_AuthenticatesAndSignsIdentity(t *testing.T) {
+	signer, err := security.NewIdentitySigner("0123456789abcdef0123456789abcdef", nil, time.Minute)
+	require.NoError(t, err)
+
+	var verified []*security.Identity
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := signer.Verify(r.Header.Get(security.IdentityHeader), r.Method, r.URL.Path, r.Header.Get(HeaderRequestID), time.Now())
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		verified = append(verified, identity)
+	}))
+	defer upstream.Close()
+
+	g, err := New(&config.GatewayConfig{Routes: []config.GatewayRoute{
+		{Prefix: "/api/v1/metrics", Upstreams: []string{upstream.URL}, Timeout: config.Duration(5 * time.Second), MaxBodyBytes: 1 << 20, Auth: config.GatewayAuthRequired},
+		{Prefix: "/api/v1/ml", Upstreams: []string{upstream.URL}, Timeout: config.Duration(5 * time.Second), MaxBodyBytes: 1 << 20, Auth: config.GatewayAuthNone, RateLimit: config.GatewayRateLimitNone},
+	}}, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	g.SetSecurity(security.NewSecurityMiddleware(&security.SecurityConfig{JWTSecret: "test-secret", JWTIssuer: "dunksense"}, zap.NewNop(), nil), signer)
+	router := newRouter(g)
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/jumps", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, verified)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user-1",
+		"iss":   "dunksense",
+		"roles": []string{"athlete"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	// A header sent by the client is replaced with the one the gateway signs
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/metrics/jumps", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(security.IdentityHeader, signer.Sign(security.Identity{UserID: "admin", Roles: []string{"admin"}}, http.MethodGet, "/api/v1/metrics/jumps", "", time.Now()))
+	w, _ = serve(router, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, verified, 1)
+	assert.Equal(t, security.Identity{UserID: "user-1", Roles: []string{"athlete"}}, *verified[0])
+
+	// Anonymous callers still get a signed, empty identity
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, verified, 2)
+	assert.Equal(t, security.Identity{}, *verified[1])
+}
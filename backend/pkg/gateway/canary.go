@@ -0,0 +1,127 @@
+package gateway
+
+import (
+	"hash/fnv"
+	"net/http"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+const (
+	// HeaderVariant selects a route variant by name and reports the variant that served a response
+	HeaderVariant = "X-Route-Variant"
+	// CookieVariant selects a route variant by name, e.g. for testers using the app
+	CookieVariant = "route_variant"
+
+	// RoleTester lets users select any variant with the header or cookie
+	RoleTester = "tester"
+	// ScopeRouteVariants lets API keys select any variant with the header
+	ScopeRouteVariants = "gateway:variants"
+)
+
+// variant is a pool of upstreams serving part of a route's traffic. Every route
+// has a stable variant for its own upstreams.
+type variant struct {
+	name   string
+	weight int
+	users  map[string]bool
+	pool   *Pool
+	budget *retryBudget
+}
+
+func newVariant(name string, weight int, users []string, pool *Pool, retry config.RetryPolicy) *variant {
+	v := &variant{
+		name:   name,
+		weight: weight,
+		users:  make(map[string]bool, len(users)),
+		pool:   pool,
+		budget: newRetryBudget(retry.BudgetRatio, retry.BudgetBurst),
+	}
+	for _, user := range users {
+		v.users[user] = true
+	}
+	return v
+}
+
+// selectVariant picks the variant serving a request. An override naming a
+// variant the caller may select wins, then the variant's user list, then the
+// caller's hash bucket. Anyone may opt out to the stable variant. Callers
+// without a user are bucketed by API key, then by client IP.
+func (rt *route) selectVariant(req *http.Request, identity security.Identity, clientIP string) *variant {
+	stable := rt.variants[0]
+	if len(rt.variants) == 1 {
+		return stable
+	}
+
+	if name := variantOverride(req); name != "" {
+		for _, v := range rt.variants {
+			if v.name == name && (v == stable || v.selectableBy(identity)) {
+				return v
+			}
+		}
+	}
+	if identity.UserID != "" {
+		for _, v := range rt.variants[1:] {
+			if v.users[identity.UserID] {
+				return v
+			}
+		}
+	}
+
+	key := identity.UserID
+	if key == "" {
+		key = identity.APIKeyID
+	}
+	if key == "" {
+		key = clientIP
+	}
+	bucket := stickyBucket(rt.config.Prefix, key)
+	for _, v := range rt.variants[1:] {
+		if bucket < v.weight {
+			return v
+		}
+		bucket -= v.weight
+	}
+	return stable
+}
+
+// selectableBy reports whether a caller may select the variant by name: testers,
+// API keys granted ScopeRouteVariants and the variant's own users. Anyone else
+// would reach variants that are not meant to serve them yet, e.g. at weight 0.
+func (v *variant) selectableBy(identity security.Identity) bool {
+	if identity.HasRole(RoleTester) || identity.HasScope(ScopeRouteVariants) {
+		return true
+	}
+	return identity.UserID != "" && v.users[identity.UserID]
+}
+
+// variantOverride returns the variant named by the request's header or cookie
+func variantOverride(req *http.Request) string {
+	if name := req.Header.Get(HeaderVariant); name != "" {
+		return name
+	}
+	if cookie, err := req.Cookie(CookieVariant); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// stickyBucket maps a caller to one of 100 buckets. The route is part of the
+// hash so a canary of one route does not always get the same users as another.
+func stickyBucket(prefix, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(prefix))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100)
+}
+
+// upstreams returns the members of every variant's pool
+func (rt *route) upstreams() []*Upstream {
+	var upstreams []*Upstream
+	for _, v := range rt.variants {
+		upstreams = append(upstreams, v.pool.Upstreams()...)
+	}
+	return upstreams
+}
@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+func newCanaryGateway(t *testing.T, weight int, users ...string) (*Gateway, http.Handler) {
+	stable := echoUpstream(t, "stable")
+	canary := echoUpstream(t, "canary")
+	return newResilientGateway(t, config.GatewayRoute{
+		Prefix:    "/api/v1/ml",
+		Upstreams: []string{stable.URL},
+		Variants: []config.RouteVariant{
+			{Name: "canary", Upstreams: []string{canary.URL}, Weight: weight, Users: users},
+		},
+	})
+}
+
+func TestCanary_Overrides(t *testing.T) {
+	g, router := newCanaryGateway(t, 0, "tester-1")
+
+	w, echoed := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, "stable", echoed["upstream"])
+	assert.Equal(t, "stable", w.Header().Get(HeaderVariant))
+
+	// Anonymous callers cannot reach a variant that is not serving them
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil)
+	req.Header.Set(HeaderVariant, "canary")
+	w, echoed = serve(router, req)
+	assert.Equal(t, "stable", echoed["upstream"])
+	assert.Equal(t, "stable", w.Header().Get(HeaderVariant))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil)
+	req.AddCookie(&http.Cookie{Name: CookieVariant, Value: "canary"})
+	_, echoed = serve(router, req)
+	assert.Equal(t, "stable", echoed["upstream"])
+
+	assert.Equal(t, float64(3), testutil.ToFloat64(g.metrics.requests.WithLabelValues("/api/v1/ml", "stable", "200")))
+
+	rt := g.match("/api/v1/ml")
+	override := func(name string, header bool) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header {
+			req.Header.Set(HeaderVariant, name)
+		} else {
+			req.AddCookie(&http.Cookie{Name: CookieVariant, Value: name})
+		}
+		return req
+	}
+
+	// Testers and keys granted the scope select any variant
+	tester := security.Identity{UserID: "user-2", Roles: []string{RoleTester}}
+	assert.Equal(t, "canary", rt.selectVariant(override("canary", true), tester, "10.0.0.1").name)
+	assert.Equal(t, "canary", rt.selectVariant(override("canary", false), tester, "10.0.0.1").name)
+	key := security.Identity{APIKeyID: "key-1", Scopes: []string{ScopeRouteVariants}}
+	assert.Equal(t, "canary", rt.selectVariant(override("canary", true), key, "10.0.0.1").name)
+
+	// Other users and keys keep their assignment
+	for _, identity := range []security.Identity{
+		{UserID: "user-2", Roles: []string{"athlete"}},
+		{APIKeyID: "key-2", Scopes: []string{"metrics:write"}},
+	} {
+		assert.Equal(t, "stable", rt.selectVariant(override("canary", true), identity, "10.0.0.1").name)
+		assert.Equal(t, "stable", rt.selectVariant(override("canary", false), identity, "10.0.0.1").name)
+	}
+
+	// Unknown variants fall back to the assignment
+	assert.Equal(t, "stable", rt.selectVariant(override("experimental", true), tester, "10.0.0.1").name)
+
+	// Listed users always get the variant and may opt out of it
+	listed := security.Identity{UserID: "tester-1"}
+	assert.Equal(t, "canary", rt.selectVariant(httptest.NewRequest(http.MethodGet, "/", nil), listed, "10.0.0.1").name)
+	assert.Equal(t, "stable", rt.selectVariant(override("stable", true), listed, "10.0.0.1").name)
+}
+
+func TestCanary_StickyWeightedAssignment(t *testing.T) {
+	g, _ := newCanaryGateway(t, 20)
+	rt := g.match("/api/v1/ml")
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	canary := 0
+	for i := 0; i < 2000; i++ {
+		identity := security.Identity{UserID: fmt.Sprintf("user-%d", i)}
+		first := rt.selectVariant(req, identity, "10.0.0.1")
+		require.Same(t, first, rt.selectVariant(req, identity, "10.0.0.2"))
+		if first.name == "canary" {
+			canary++
+		}
+	}
+	assert.InDelta(t, 400, canary, 80)
+
+	// Raising the weight only moves stable users over
+	before := make(map[string]string)
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("user-%d", i)
+		before[id] = rt.selectVariant(req, security.Identity{UserID: id}, "").name
+	}
+	cfg := &config.GatewayConfig{Routes: []config.GatewayRoute{rt.config}}
+	cfg.Routes[0].Variants[0].Weight = 50
+	require.NoError(t, g.Reload(cfg))
+	rt = g.match("/api/v1/ml")
+	for id, name := range before {
+		if name == "canary" {
+			assert.Equal(t, "canary", rt.selectVariant(req, security.Identity{UserID: id}, "").name)
+		}
+	}
+}
+
+func TestParseGatewayConfig_Variants(t *testing.T) {
+	_, err := config.ParseGatewayConfig([]byte(`{"routes": [{"prefix": "/api/v1/ml", "upstreams": ["http://ml:8081"],
+		"variants": [{"name": "a", "upstreams": ["http://ml-a:8081"], "weight": 60}, {"name": "b", "upstreams": ["http://ml-b:8081"], "weight": 60}]}]}`))
+	assert.ErrorContains(t, err, "more than 100")
+
+	_, err = config.ParseGatewayConfig([]byte(`{"routes": [{"prefix": "/api/v1/ml", "upstreams": ["http://ml:8081"],
+		"variants": [{"name": "stable", "upstreams": ["http://ml-a:8081"]}]}]}`))
+	assert.ErrorContains(t, err, "reserved")
+
+	_, err = config.ParseGatewayConfig([]byte(`{"routes": [{"prefix": "/api/v1/ml", "upstreams": ["http://ml:8081"],
+		"variants": [{"name": "canary", "upstreams": ["http://ml:8081"]}]}]}`))
+	assert.ErrorContains(t, err, "already serves")
+}
@@ -0,0 +1,311 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/apiversion"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// maxHomeSectionBytes bounds the upstream response a section may contribute
+const maxHomeSectionBytes = 1 << 20
+
+// Error codes of a home section that could not be loaded
+const (
+	homeErrorTimeout     = "timeout"
+	homeErrorUnavailable = "unavailable"
+	homeErrorUpstream    = "upstream_error"
+)
+
+// homeResponse is the body of /api/v1/home. Partial is set when a section
+// failed; the other sections are still returned.
+type homeResponse struct {
+	UserID      string                 `json:"user_id"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Partial     bool                   `json:"partial"`
+	Sections    map[string]homeSection `json:"sections"`
+}
+
+// homeSection holds either the upstream's JSON response or an error marker
+type homeSection struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Code   string          `json:"code,omitempty"`
+	Status int             `json:"status,omitempty"` // upstream status when it answered with an error
+}
+
+// HomeHandler serves the app's home screen in one request. The configured
+// sections are fetched concurrently from their routes' upstreams, each within
+// its own deadline, and complete responses are cached per user for a short time.
+func (g *Gateway) HomeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		home := g.home.Load()
+		if home == nil || len(home.Sections) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		if g.security != nil {
+			if !g.security.Authenticate(c, false) {
+				return
+			}
+			if !g.security.RateLimitRequest(c, security.RateLimitPolicyRead) {
+				return
+			}
+		}
+		identity := security.CallerIdentity(c)
+		if identity.UserID == "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "A user token is required"})
+			return
+		}
+
+		rid := requestID(c.GetHeader(HeaderRequestID))
+		c.Header(HeaderRequestID, rid)
+
+		// Sections are fetched in the caller's API version, see fetchSection
+		cacheKey := identity.UserID
+		if version := c.GetHeader(apiversion.Header); version != "" {
+			cacheKey += "\x00" + version
+		}
+		c.Writer.Header().Add("Vary", apiversion.Header)
+
+		if body, ok := g.homeCache.get(cacheKey, time.Now()); ok {
+			g.metrics.homeCache.WithLabelValues("hit").Inc()
+			c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(time.Duration(home.CacheTTL).Seconds())))
+			c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+			return
+		}
+		g.metrics.homeCache.WithLabelValues("miss").Inc()
+
+		resp := g.composeHome(c.Request, home, identity, c.ClientIP(), rid)
+		body, err := json.Marshal(resp)
+		if err != nil {
+			g.logger.Error("Failed to encode home response", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		status := http.StatusOK
+		switch {
+		case !resp.Partial:
+			g.homeCache.put(cacheKey, body, time.Now().Add(time.Duration(home.CacheTTL)))
+			c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(time.Duration(home.CacheTTL).Seconds())))
+		case allFailed(resp.Sections):
+			status = http.StatusBadGateway
+			c.Header("Cache-Control", "no-store")
+		default:
+			c.Header("Cache-Control", "no-store")
+		}
+		c.Data(status, "application/json; charset=utf-8", body)
+	}
+}
+
+// composeHome fetches every section concurrently
+func (g *Gateway) composeHome(in *http.Request, home *config.HomeConfig, identity security.Identity, clientIP, rid string) homeResponse {
+	resp := homeResponse{
+		UserID:      identity.UserID,
+		GeneratedAt: time.Now().UTC(),
+		Sections:    make(map[string]homeSection, len(home.Sections)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, section := range home.Sections {
+		wg.Add(1)
+		go func(section config.HomeSection) {
+			defer wg.Done()
+			result := g.fetchSection(in, section, identity, clientIP, rid)
+			mu.Lock()
+			resp.Sections[section.Name] = result
+			if result.Code != "" {
+				resp.Partial = true
+			}
+			mu.Unlock()
+		}(section)
+	}
+	wg.Wait()
+	return resp
+}
+
+// fetchSection calls the section's upstream through its route, so the call gets
+// the route's retries, circuit breakers and identity forwarding
+func (g *Gateway) fetchSection(in *http.Request, section config.HomeSection, identity security.Identity, clientIP, rid string) homeSection {
+	rt := g.routeFor(section.Route)
+	if rt == nil {
+		g.metrics.homeSections.WithLabelValues(section.Name, homeErrorUnavailable).Inc()
+		return homeSection{Error: "Section unavailable", Code: homeErrorUnavailable}
+	}
+
+	ctx, cancel := context.WithTimeout(in.Context(), time.Duration(section.Timeout))
+	defer cancel()
+	ctx = context.WithValue(ctx, exchangeKey{}, &exchange{
+		route:     rt,
+		variant:   rt.selectVariant(in, identity, clientIP),
+		requestID: rid,
+		identity:  identity,
+		signer:    g.signer,
+	})
+
+	target := strings.ReplaceAll(section.Path, "{user_id}", url.PathEscape(identity.UserID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		g.metrics.homeSections.WithLabelValues(section.Name, homeErrorUnavailable).Inc()
+		return homeSection{Error: "Section unavailable", Code: homeErrorUnavailable}
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(HeaderRequestID, rid)
+	req.Header.Set(HeaderTraceparent, childTraceparent(in.Header.Get(HeaderTraceparent)))
+	if version := in.Header.Get(apiversion.Header); version != "" {
+		req.Header.Set(apiversion.Header, version)
+	}
+	if g.signer == nil {
+		// Without a signed identity the upstream authenticates the caller itself
+		for _, name := range []string{"Authorization", "X-API-Key"} {
+			if value := in.Header.Get(name); value != "" {
+				req.Header.Set(name, value)
+			}
+		}
+	}
+	for name, value := range rt.config.SetHeaders {
+		req.Header.Set(name, value)
+	}
+
+	result, err := g.readSection(rt, req)
+	if err != nil {
+		code, message := homeErrorUpstream, "Upstream unavailable"
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			code, message = homeErrorTimeout, "Section timed out"
+		case errors.Is(err, ErrNoUpstream):
+			code, message = homeErrorUnavailable, "Section unavailable"
+		}
+		result = homeSection{Error: message, Code: code}
+		g.metrics.observeError(rt.config.Prefix, err)
+		g.logger.Warn("Home section failed",
+			zap.String("section", section.Name),
+			zap.String("request_id", rid),
+			zap.Error(err),
+		)
+	}
+
+	outcome := "ok"
+	if result.Code != "" {
+		outcome = result.Code
+	}
+	g.metrics.homeSections.WithLabelValues(section.Name, outcome).Inc()
+	return result
+}
+
+// readSection performs the call and reads a JSON body of bounded size
+func (g *Gateway) readSection(rt *route, req *http.Request) (homeSection, error) {
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return homeSection{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHomeSectionBytes+1))
+	if err != nil {
+		return homeSection{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return homeSection{Error: "Upstream returned an error", Code: homeErrorUpstream, Status: resp.StatusCode}, nil
+	}
+	if len(body) > maxHomeSectionBytes {
+		return homeSection{}, fmt.Errorf("section response exceeds %d bytes", maxHomeSectionBytes)
+	}
+	if !json.Valid(body) {
+		return homeSection{}, fmt.Errorf("section response is not JSON")
+	}
+	return homeSection{Data: body}, nil
+}
+
+// routeFor returns the route with exactly the given prefix
+func (g *Gateway) routeFor(prefix string) *route {
+	for _, rt := range *g.routes.Load() {
+		if rt.config.Prefix == prefix {
+			return rt
+		}
+	}
+	return nil
+}
+
+func allFailed(sections map[string]homeSection) bool {
+	for _, section := range sections {
+		if section.Code == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// homeCache keeps composed home responses per user until they expire
+type homeCache struct {
+	mu         sync.Mutex
+	entries    map[string]homeCacheEntry
+	maxEntries int
+}
+
+type homeCacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+func newHomeCache() *homeCache {
+	return &homeCache{entries: make(map[string]homeCacheEntry)}
+}
+
+func (hc *homeCache) get(userID string, now time.Time) ([]byte, bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	entry, exists := hc.entries[userID]
+	if !exists || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (hc *homeCache) put(userID string, body []byte, expires time.Time) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if len(hc.entries) >= hc.maxEntries {
+		now := time.Now()
+		for key, entry := range hc.entries {
+			if now.After(entry.expires) {
+				delete(hc.entries, key)
+			}
+		}
+		// Still full: the entries expire within seconds, so any of them will do
+		for key := range hc.entries {
+			if len(hc.entries) < hc.maxEntries {
+				break
+			}
+			delete(hc.entries, key)
+		}
+	}
+	hc.entries[userID] = homeCacheEntry{body: body, expires: expires}
+}
+
+// reset drops every entry and applies a new size limit
+func (hc *homeCache) reset(maxEntries int) {
+	hc.mu.Lock()
+	hc.entries = make(map[string]homeCacheEntry)
+	hc.maxEntries = maxEntries
+	hc.mu.Unlock()
+}
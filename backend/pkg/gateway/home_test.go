@@ -0,0 +1,223 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/apiversion"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// newHomeGateway serves the home endpoint of a gateway with the given sections
+// in front of upstream, authenticating callers with HS256 tokens
+func newHomeGateway(t *testing.T, upstream string, sections ...config.HomeSection) (*Gateway, *gin.Engine) {
+	data, err := json.Marshal(config.GatewayConfig{
+		Routes: []config.GatewayRoute{{Prefix: "/api/v1/metrics", Upstreams: []string{upstream}}},
+		Home:   config.HomeConfig{Sections: sections, CacheTTL: config.Duration(time.Minute)},
+	})
+	require.NoError(t, err)
+	cfg, err := config.ParseGatewayConfig(data)
+	require.NoError(t, err)
+
+	g, err := New(cfg, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	signer, err := security.NewIdentitySigner("0123456789abcdef0123456789abcdef", nil, time.Minute)
+	require.NoError(t, err)
+	g.SetSecurity(security.NewSecurityMiddleware(&security.SecurityConfig{JWTSecret: "test-secret", JWTIssuer: "dunksense"}, zap.NewNop(), nil), signer)
+
+	router := newRouter(g)
+	router.GET("/api/v1/home", g.HomeHandler())
+	return g, router
+}
+
+func homeRequest(t *testing.T, userID string) *http.Request {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": userID,
+		"iss": "dunksense",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/home", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func decodeHome(t *testing.T, w *httptest.ResponseRecorder) homeResponse {
+	var resp homeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestHome_ComposesSectionsConcurrently(t *testing.T) {
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+	defer upstream.Close()
+
+	_, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "recent_metrics", Route: "/api/v1/metrics", Path: "/api/v1/athletes/{user_id}/metrics"},
+		config.HomeSection{Name: "personal_best", Route: "/api/v1/metrics", Path: "/api/v1/users/{user_id}/personal-best"},
+		config.HomeSection{Name: "weekly_stats", Route: "/api/v1/metrics", Path: "/api/v1/athletes/{user_id}/summary"},
+	)
+
+	start := time.Now()
+	w, _ := serve(router, homeRequest(t, "user-1"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Less(t, time.Since(start), 140*time.Millisecond)
+
+	resp := decodeHome(t, w)
+	assert.Equal(t, "user-1", resp.UserID)
+	assert.False(t, resp.Partial)
+	assert.JSONEq(t, `{"path": "/api/v1/athletes/user-1/metrics"}`, string(resp.Sections["recent_metrics"].Data))
+	assert.JSONEq(t, `{"path": "/api/v1/users/user-1/personal-best"}`, string(resp.Sections["personal_best"].Data))
+	assert.JSONEq(t, `{"path": "/api/v1/athletes/user-1/summary"}`, string(resp.Sections["weekly_stats"].Data))
+	assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+	assert.Equal(t, int64(3), calls.Load())
+}
+
+func TestHome_PartialResults(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer upstream.Close()
+
+	g, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "fast", Route: "/api/v1/metrics", Path: "/fast"},
+		config.HomeSection{Name: "slow", Route: "/api/v1/metrics", Path: "/slow", Timeout: config.Duration(20 * time.Millisecond)},
+		config.HomeSection{Name: "missing", Route: "/api/v1/metrics", Path: "/missing"},
+	)
+
+	w, _ := serve(router, homeRequest(t, "user-1"))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	resp := decodeHome(t, w)
+	assert.True(t, resp.Partial)
+	assert.JSONEq(t, `{"ok": true}`, string(resp.Sections["fast"].Data))
+	assert.Equal(t, homeErrorTimeout, resp.Sections["slow"].Code)
+	assert.Empty(t, resp.Sections["slow"].Data)
+	assert.Equal(t, homeErrorUpstream, resp.Sections["missing"].Code)
+	assert.Equal(t, http.StatusNotFound, resp.Sections["missing"].Status)
+	assert.Equal(t, float64(1), testutil.ToFloat64(g.metrics.homeSections.WithLabelValues("slow", homeErrorTimeout)))
+
+	// Partial responses are not cached
+	serve(router, homeRequest(t, "user-1"))
+	assert.Equal(t, float64(2), testutil.ToFloat64(g.metrics.homeCache.WithLabelValues("miss")))
+}
+
+func TestHome_CachesPerUser(t *testing.T) {
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]string{"path": r.URL.Path})
+	}))
+	defer upstream.Close()
+
+	g, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "recent_metrics", Route: "/api/v1/metrics", Path: "/api/v1/athletes/{user_id}/metrics"},
+	)
+
+	first, _ := serve(router, homeRequest(t, "user-1"))
+	second, _ := serve(router, homeRequest(t, "user-1"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, int64(1), calls.Load())
+
+	other, _ := serve(router, homeRequest(t, "user-2"))
+	assert.JSONEq(t, `{"path": "/api/v1/athletes/user-2/metrics"}`, string(decodeHome(t, other).Sections["recent_metrics"].Data))
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Equal(t, float64(1), testutil.ToFloat64(g.metrics.homeCache.WithLabelValues("hit")))
+}
+
+func TestHome_ForwardsAPIVersion(t *testing.T) {
+	var calls atomic.Int64
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		json.NewEncoder(w).Encode(map[string]string{"version": r.Header.Get(apiversion.Header)})
+	}))
+	defer upstream.Close()
+
+	_, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "recent_metrics", Route: "/api/v1/metrics", Path: "/api/v1/athletes/{user_id}/metrics"},
+	)
+
+	get := func(version string) string {
+		req := homeRequest(t, "user-1")
+		if version != "" {
+			req.Header.Set(apiversion.Header, version)
+		}
+		w, _ := serve(router, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, apiversion.Header, w.Header().Get("Vary"))
+		return string(decodeHome(t, w).Sections["recent_metrics"].Data)
+	}
+
+	assert.JSONEq(t, `{"version": ""}`, get(""))
+	assert.JSONEq(t, `{"version": "2"}`, get("2"))
+
+	// Each version is cached on its own
+	assert.JSONEq(t, `{"version": "2"}`, get("2"))
+	assert.JSONEq(t, `{"version": ""}`, get(""))
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestHome_RequiresUser(t *testing.T) {
+	upstream := statusUpstream(t, http.StatusOK, new(atomic.Int64))
+	_, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "recent_metrics", Route: "/api/v1/metrics", Path: "/metrics"},
+	)
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/home", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHome_AllSectionsFailed(t *testing.T) {
+	upstream := statusUpstream(t, http.StatusInternalServerError, new(atomic.Int64))
+	_, router := newHomeGateway(t, upstream.URL,
+		config.HomeSection{Name: "recent_metrics", Route: "/api/v1/metrics", Path: "/metrics"},
+	)
+
+	w, _ := serve(router, homeRequest(t, "user-1"))
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.True(t, decodeHome(t, w).Partial)
+}
+
+func TestParseGatewayConfig_HomeSections(t *testing.T) {
+	_, err := config.ParseGatewayConfig([]byte(`{
+		"routes": [{"prefix": "/api/v1/metrics", "upstreams": ["http://metrics:8080"]}],
+		"home": {"sections": [{"name": "challenges", "route": "/api/v1/community", "path": "/challenges"}]}
+	}`))
+	assert.ErrorContains(t, err, "no route")
+
+	cfg, err := config.ParseGatewayConfig([]byte(`{
+		"routes": [{"prefix": "/api/v1/metrics", "upstreams": ["http://metrics:8080"], "timeout": "1s"}],
+		"home": {"sections": [{"name": "recent", "route": "/api/v1/metrics/", "path": "/recent", "timeout": "5s"}]}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/metrics", cfg.Home.Sections[0].Route)
+	assert.Equal(t, config.Duration(time.Second), cfg.Home.Sections[0].Timeout)
+	assert.Equal(t, config.Duration(10*time.Second), cfg.Home.CacheTTL)
+}
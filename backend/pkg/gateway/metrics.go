@@ -0,0 +1,234 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// Recorder receives upstream calls and proxy errors, e.g. monitoring.Metrics
+type Recorder interface {
+	RecordUpstreamRequest(route, upstream, statusCode string, duration time.Duration)
+	RecordProxyError(route, class string)
+}
+
+// Classes of proxy errors reported to the Recorder
+const (
+	errorClassNoUpstream   = "no_upstream"
+	errorClassTimeout      = "timeout"
+	errorClassCanceled     = "client_canceled"
+	errorClassBodyTooLarge = "body_too_large"
+	errorClassRefused      = "connection_refused"
+	errorClassReset        = "connection_reset"
+	errorClassDNS          = "dns"
+	errorClassTLS          = "tls"
+	errorClassUpstream     = "upstream_error"
+	upstreamStatusError    = "error"
+)
+
+// metrics are shared by all route tables so counters survive reloads
+type metrics struct {
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+	breakerOpened *prometheus.CounterVec
+	ejections     *prometheus.CounterVec
+	unavailable   *prometheus.CounterVec
+	homeSections  *prometheus.CounterVec
+	homeCache     *prometheus.CounterVec
+
+	recorder Recorder // nil unless set with SetRecorder
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_requests_total",
+				Help: "Proxied requests by route, variant and status code",
+			},
+			[]string{"route", "variant", "status_code"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "gateway_request_duration_seconds",
+				Help:    "Proxied request duration by route and variant, including streamed bodies",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"route", "variant"},
+		),
+		retries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_retries_total",
+				Help: "Retried upstream requests by route and result (retried or budget_exhausted)",
+			},
+			[]string{"route", "result"},
+		),
+		breakerOpened: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_circuit_breaker_opened_total",
+				Help: "Times an upstream's circuit breaker opened",
+			},
+			[]string{"route", "upstream"},
+		),
+		ejections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_upstream_ejections_total",
+				Help: "Upstreams ejected by outlier detection",
+			},
+			[]string{"route", "upstream"},
+		),
+		unavailable: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_no_upstream_total",
+				Help: "Requests rejected because no upstream of the route was available",
+			},
+			[]string{"route"},
+		),
+		homeSections: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_home_sections_total",
+				Help: "Home screen sections by result (ok, timeout, unavailable or upstream_error)",
+			},
+			[]string{"section", "result"},
+		),
+		homeCache: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "gateway_home_cache_total",
+				Help: "Home screen cache lookups by result (hit or miss)",
+			},
+			[]string{"result"},
+		),
+	}
+}
+
+// observeRequest records a proxied request of a route variant
+func (m *metrics) observeRequest(route, variant string, status int, elapsed time.Duration) {
+	m.requests.WithLabelValues(route, variant, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(route, variant).Observe(elapsed.Seconds())
+}
+
+// observeUpstream reports one attempt against an upstream; status is 0 when
+// no response was received
+func (m *metrics) observeUpstream(route, upstream string, status int, elapsed time.Duration) {
+	if m.recorder == nil {
+		return
+	}
+	statusCode := upstreamStatusError
+	if status != 0 {
+		statusCode = strconv.Itoa(status)
+	}
+	m.recorder.RecordUpstreamRequest(route, upstream, statusCode, elapsed)
+}
+
+// observeError reports a request of route that failed with err
+func (m *metrics) observeError(route string, err error) {
+	if m.recorder != nil {
+		m.recorder.RecordProxyError(route, errorClass(err))
+	}
+}
+
+// errorClass groups proxy errors by cause
+func errorClass(err error) string {
+	var (
+		dnsErr    *net.DNSError
+		certErr   *tls.CertificateVerificationError
+		recordErr tls.RecordHeaderError
+		authErr   x509.UnknownAuthorityError
+		hostErr   x509.HostnameError
+	)
+	switch {
+	case errors.Is(err, ErrNoUpstream):
+		return errorClassNoUpstream
+	case security.IsBodyTooLarge(err):
+		return errorClassBodyTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return errorClassCanceled
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errorClassRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		return errorClassReset
+	case errors.As(err, &dnsErr):
+		return errorClassDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &authErr), errors.As(err, &hostErr):
+		return errorClassTLS
+	}
+	return errorClassUpstream
+}
+
+var (
+	breakerStateDesc = prometheus.NewDesc(
+		"gateway_circuit_breaker_state",
+		"Circuit breaker state per upstream: 0 closed, 1 half-open, 2 open",
+		[]string{"route", "upstream"}, nil,
+	)
+	ejectedDesc = prometheus.NewDesc(
+		"gateway_upstream_ejected",
+		"Whether the upstream is currently ejected by outlier detection",
+		[]string{"route", "upstream"}, nil,
+	)
+	inflightDesc = prometheus.NewDesc(
+		"gateway_upstream_requests_in_flight",
+		"Requests currently held by the upstream",
+		[]string{"route", "upstream"}, nil,
+	)
+)
+
+// stateCollector reports the state of the upstreams in the current route table
+type stateCollector struct {
+	gateway *Gateway
+}
+
+func (c stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- breakerStateDesc
+	ch <- ejectedDesc
+	ch <- inflightDesc
+}
+
+func (c stateCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	for _, rt := range *c.gateway.routes.Load() {
+		for _, u := range rt.upstreams() {
+			state, ejected := u.State(now)
+			ejectedValue := 0.0
+			if ejected {
+				ejectedValue = 1
+			}
+			ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, float64(state), rt.config.Prefix, u.URL.Host)
+			ch <- prometheus.MustNewConstMetric(ejectedDesc, prometheus.GaugeValue, ejectedValue, rt.config.Prefix, u.URL.Host)
+			ch <- prometheus.MustNewConstMetric(inflightDesc, prometheus.GaugeValue, float64(u.inflight.Load()), rt.config.Prefix, u.URL.Host)
+		}
+	}
+}
+
+// SetRecorder reports upstream calls and proxy errors to recorder as well. It
+// must be called before the gateway serves requests.
+func (g *Gateway) SetRecorder(recorder Recorder) {
+	g.metrics.recorder = recorder
+}
+
+// Collectors returns the gateway's Prometheus collectors
+func (g *Gateway) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		g.metrics.requests,
+		g.metrics.duration,
+		g.metrics.retries,
+		g.metrics.breakerOpened,
+		g.metrics.ejections,
+		g.metrics.unavailable,
+		g.metrics.homeSections,
+		g.metrics.homeCache,
+		stateCollector{gateway: g},
+	}
+}
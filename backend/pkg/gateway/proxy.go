@@ -0,0 +1,290 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/monitoring"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// route is a configured prefix with its upstream pools. Routes are immutable;
+// a reload builds new ones that inherit the upstream state.
+type route struct {
+	config    config.GatewayRoute
+	variants  []*variant // the stable variant first
+	proxy     *httputil.ReverseProxy
+	transport http.RoundTripper
+	metrics   *metrics
+	logger    *zap.Logger
+}
+
+// exchange carries per-request proxy state from the handler to the proxy hooks
+type exchange struct {
+	route     *route
+	variant   *variant
+	upstream  *Upstream
+	requestID string
+	identity  security.Identity
+	signer    *security.IdentitySigner // nil when identities are not forwarded
+}
+
+type exchangeKey struct{}
+
+// Gateway proxies API routes to pools of upstream services
+type Gateway struct {
+	routes    atomic.Pointer[[]*route] // longest prefix first
+	home      atomic.Pointer[config.HomeConfig]
+	homeCache *homeCache
+	reloadMu  sync.Mutex
+	transport http.RoundTripper
+	metrics   *metrics
+	logger    *zap.Logger
+
+	security *security.SecurityMiddleware
+	signer   *security.IdentitySigner
+}
+
+// New creates a gateway for the configured routes. The transport is shared by
+// all upstreams, e.g. tlsutil.Manager.HTTPTransport() for mTLS.
+func New(cfg *config.GatewayConfig, transport http.RoundTripper, logger *zap.Logger) (*Gateway, error) {
+	g := &Gateway{transport: transport, homeCache: newHomeCache(), metrics: newMetrics(), logger: logger}
+	if err := g.Reload(cfg); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetSecurity makes the gateway authenticate and rate limit requests as each
+// route is configured to, and forward the caller's identity signed with signer.
+// It must be called before the gateway serves requests.
+func (g *Gateway) SetSecurity(sm *security.SecurityMiddleware, signer *security.IdentitySigner) {
+	g.security = sm
+	g.signer = signer
+}
+
+// Reload replaces the route table and the home sections. Requests in flight finish on the routes they
+// started with, and upstreams that stay in a route keep their breaker and
+// outlier state.
+func (g *Gateway) Reload(cfg *config.GatewayConfig) error {
+	g.reloadMu.Lock()
+	defer g.reloadMu.Unlock()
+
+	previous := make(map[string]*route)
+	if current := g.routes.Load(); current != nil {
+		for _, rt := range *current {
+			previous[rt.config.Prefix] = rt
+		}
+	}
+
+	routes := make([]*route, 0, len(cfg.Routes))
+	for _, rc := range cfg.Routes {
+		variants, err := buildVariants(rc, previous[rc.Prefix])
+		if err != nil {
+			return fmt.Errorf("route %s: %w", rc.Prefix, err)
+		}
+
+		rt := &route{
+			config:    rc,
+			variants:  variants,
+			transport: g.transport,
+			metrics:   g.metrics,
+			logger:    g.logger,
+		}
+		rt.proxy = &httputil.ReverseProxy{
+			Transport:      rt,
+			FlushInterval:  -1, // stream responses as they arrive
+			Rewrite:        g.rewrite,
+			ModifyResponse: g.modifyResponse,
+			ErrorHandler:   g.handleError,
+		}
+		routes = append(routes, rt)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		return len(routes[i].config.Prefix) > len(routes[j].config.Prefix)
+	})
+	g.routes.Store(&routes)
+
+	home := cfg.Home
+	g.home.Store(&home)
+	g.homeCache.reset(home.CacheMaxEntries)
+	return nil
+}
+
+// buildVariants creates the pools of a route. Upstreams keep their state from
+// any variant of the previous route, so promoting a canary keeps its breakers.
+func buildVariants(rc config.GatewayRoute, previous *route) ([]*variant, error) {
+	pool, err := NewPool(rc.Upstreams)
+	if err != nil {
+		return nil, err
+	}
+	variants := []*variant{newVariant(config.StableVariant, 0, nil, pool, rc.Retry)}
+	for _, vc := range rc.Variants {
+		pool, err := NewPool(vc.Upstreams)
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", vc.Name, err)
+		}
+		variants = append(variants, newVariant(vc.Name, vc.Weight, vc.Users, pool, rc.Retry))
+	}
+
+	if previous != nil {
+		for _, v := range variants {
+			for _, old := range previous.variants {
+				v.pool.inherit(old.pool)
+			}
+		}
+	}
+	return variants, nil
+}
+
+// Handler proxies requests matching a route and answers 404 otherwise.
+// Mount it with engine.NoRoute so the gateway's own endpoints take precedence.
+func (g *Gateway) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rt := g.match(c.Request.URL.Path)
+		if rt == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		if g.security != nil {
+			if rt.config.Auth != config.GatewayAuthNone && !g.security.Authenticate(c, rt.config.Auth == config.GatewayAuthOptional) {
+				return
+			}
+			if rt.config.RateLimit != config.GatewayRateLimitNone && !g.security.RateLimitRequest(c, rt.config.RateLimit) {
+				return
+			}
+		}
+
+		// Bodies are streamed to the upstream; the limit is enforced while reading
+		if c.Request.ContentLength > rt.config.MaxBodyBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, rt.config.MaxBodyBytes)
+		}
+
+		// Proxied requests have no registered route; label them with the route prefix
+		c.Set(monitoring.EndpointKey, rt.config.Prefix+"/*")
+
+		identity := security.CallerIdentity(c)
+		v := rt.selectVariant(c.Request, identity, c.ClientIP())
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(rt.config.Timeout))
+		defer cancel()
+		ctx = context.WithValue(ctx, exchangeKey{}, &exchange{
+			route:     rt,
+			variant:   v,
+			requestID: requestID(c.GetHeader(HeaderRequestID)),
+			identity:  identity,
+			signer:    g.signer,
+		})
+
+		start := time.Now()
+		rt.proxy.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+		g.metrics.observeRequest(rt.config.Prefix, v.name, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// match returns the route with the longest prefix that matches whole path segments
+func (g *Gateway) match(path string) *route {
+	for _, rt := range *g.routes.Load() {
+		prefix := rt.config.Prefix
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return rt
+		}
+	}
+	return nil
+}
+
+// rewrite strips the route prefix and rewrites headers. The route transport
+// points each attempt at an upstream.
+func (g *Gateway) rewrite(pr *httputil.ProxyRequest) {
+	ex := pr.In.Context().Value(exchangeKey{}).(*exchange)
+	rc := ex.route.config
+
+	if rc.StripPrefix {
+		pr.Out.URL.Path = strings.TrimPrefix(pr.Out.URL.Path, rc.Prefix)
+		pr.Out.URL.RawPath = strings.TrimPrefix(pr.Out.URL.RawPath, rc.Prefix)
+		if pr.Out.URL.Path == "" {
+			pr.Out.URL.Path = "/"
+		}
+	}
+	pr.SetXForwarded()
+
+	// Only the gateway may assert an identity; the transport signs one per attempt
+	pr.Out.Header.Del(security.IdentityHeader)
+	for _, name := range rc.RemoveHeaders {
+		pr.Out.Header.Del(name)
+	}
+	for name, value := range rc.SetHeaders {
+		pr.Out.Header.Set(name, value)
+	}
+	pr.Out.Header.Set(HeaderRequestID, ex.requestID)
+	pr.Out.Header.Set(HeaderTraceparent, childTraceparent(pr.In.Header.Get(HeaderTraceparent)))
+}
+
+// modifyResponse rewrites upstream response headers
+func (g *Gateway) modifyResponse(resp *http.Response) error {
+	ex := resp.Request.Context().Value(exchangeKey{}).(*exchange)
+
+	for _, name := range ex.route.config.RemoveResponseHeaders {
+		resp.Header.Del(name)
+	}
+	resp.Header.Set(HeaderRequestID, ex.requestID)
+	if len(ex.route.variants) > 1 {
+		resp.Header.Set(HeaderVariant, ex.variant.name)
+	}
+	return nil
+}
+
+// handleError answers requests the upstream could not serve
+func (g *Gateway) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	ex := r.Context().Value(exchangeKey{}).(*exchange)
+	g.metrics.observeError(ex.route.config.Prefix, err)
+
+	status, message := http.StatusBadGateway, "Upstream unavailable"
+	switch {
+	case errors.Is(err, ErrNoUpstream):
+		status = http.StatusServiceUnavailable
+	case security.IsBodyTooLarge(err):
+		status, message = http.StatusRequestEntityTooLarge, "Request body too large"
+	case errors.Is(err, context.DeadlineExceeded):
+		status, message = http.StatusGatewayTimeout, "Upstream timed out"
+	case errors.Is(err, context.Canceled):
+		// The client went away; nobody is left to read a response
+		return
+	}
+
+	upstream := ""
+	if ex.upstream != nil {
+		upstream = ex.upstream.URL.Host
+	}
+	g.logger.Warn("Proxy request failed",
+		zap.String("route", ex.route.config.Prefix),
+		zap.String("upstream", upstream),
+		zap.String("request_id", ex.requestID),
+		zap.Int("status", status),
+		zap.Error(err),
+	)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set(HeaderRequestID, ex.requestID)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(gin.H{"error": message})
+}
@@ -0,0 +1,335 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// echoUpstream reports what it received as JSON
+func echoUpstream(t *testing.T, name string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Server", "upstream")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"upstream":      name,
+			"path":          r.URL.RequestURI(),
+			"host":          r.Host,
+			"request_id":    r.Header.Get(HeaderRequestID),
+			"traceparent":   r.Header.Get(HeaderTraceparent),
+			"forwarded_for": r.Header.Get("X-Forwarded-For"),
+			"secret":        r.Header.Get("X-Debug-Secret"),
+			"service":       r.Header.Get("X-Gateway"),
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestGateway(t *testing.T, routes ...config.GatewayRoute) *gin.Engine {
+	cfg := &config.GatewayConfig{Routes: routes}
+	for i := range cfg.Routes {
+		if cfg.Routes[i].Timeout == 0 {
+			cfg.Routes[i].Timeout = config.Duration(5 * time.Second)
+		}
+		if cfg.Routes[i].MaxBodyBytes == 0 {
+			cfg.Routes[i].MaxBodyBytes = 1 << 20
+		}
+	}
+	g, err := New(cfg, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	return newRouter(g)
+}
+
+func newRouter(g *Gateway) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.NoRoute(g.Handler())
+	return router
+}
+
+func serve(router http.Handler, r *http.Request) (*httptest.ResponseRecorder, map[string]string) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	var echoed map[string]string
+	json.Unmarshal(w.Body.Bytes(), &echoed)
+	return w, echoed
+}
+
+func TestGateway_Routing(t *testing.T) {
+	metrics := echoUpstream(t, "metrics")
+	ml := echoUpstream(t, "ml")
+	router := newTestGateway(t,
+		config.GatewayRoute{Prefix: "/api/v1/metrics", Upstreams: []string{metrics.URL}},
+		config.GatewayRoute{Prefix: "/api/v1/ml", Upstreams: []string{ml.URL}, StripPrefix: true},
+	)
+
+	w, echoed := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/recent?limit=5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "metrics", echoed["upstream"])
+	assert.Equal(t, "/api/v1/metrics/recent?limit=5", echoed["path"])
+	assert.Equal(t, strings.TrimPrefix(metrics.URL, "http://"), echoed["host"])
+
+	_, echoed = serve(router, httptest.NewRequest(http.MethodPost, "/api/v1/ml/process", nil))
+	assert.Equal(t, "ml", echoed["upstream"])
+	assert.Equal(t, "/process", echoed["path"])
+
+	_, echoed = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml", nil))
+	assert.Equal(t, "/", echoed["path"])
+
+	// Prefixes match whole segments only
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/mlx/process", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGateway_DefaultRoutes(t *testing.T) {
+	metrics := echoUpstream(t, "metrics")
+	ml := echoUpstream(t, "ml")
+	t.Setenv("GATEWAY_CONFIG_FILE", "")
+	t.Setenv("METRICS_SERVICE_URLS", metrics.URL)
+	t.Setenv("ML_SERVICE_URLS", ml.URL)
+
+	cfg, err := config.LoadGatewayConfig()
+	require.NoError(t, err)
+	g, err := New(cfg, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	router := newRouter(g)
+
+	// Uploads and credential endpoints get the stricter policies
+	policies := map[string]string{}
+	for _, route := range cfg.Routes {
+		policies[route.Prefix] = route.RateLimit
+	}
+	assert.Equal(t, map[string]string{
+		"/api/v1":                 "by_method",
+		"/api/v1/metrics":         "upload",
+		"/api/v1/devices":         "auth",
+		"/api/v1/guardian/accept": "auth",
+		"/api/v1/ml":              "upload",
+	}, policies)
+
+	// Everything under /api/v1 is served by metrics-svc, not only /metrics
+	for _, path := range []string{
+		"/api/v1/metrics",
+		"/api/v1/athletes/athlete-1/metrics",
+		"/api/v1/athletes/athlete-1/privacy",
+		"/api/v1/users/user-1/personal-best",
+		"/api/v1/leaderboard?period=week",
+		"/api/v1/guardian/athletes",
+		"/api/v1/guardian/accept",
+		"/api/v1/devices",
+		"/api/v1/privacy/exports",
+	} {
+		w, echoed := serve(router, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, "metrics", echoed["upstream"], path)
+		assert.Equal(t, path, echoed["path"])
+	}
+
+	// The ML route is more specific
+	_, echoed := serve(router, httptest.NewRequest(http.MethodPost, "/api/v1/ml/process", nil))
+	assert.Equal(t, "ml", echoed["upstream"])
+	assert.Equal(t, "/process", echoed["path"])
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v2/athletes", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGateway_Headers(t *testing.T) {
+	upstream := echoUpstream(t, "metrics")
+	router := newTestGateway(t, config.GatewayRoute{
+		Prefix:                "/api/v1/metrics",
+		Upstreams:             []string{upstream.URL},
+		SetHeaders:            map[string]string{"X-Gateway": "dunksense"},
+		RemoveHeaders:         []string{"X-Debug-Secret"},
+		RemoveResponseHeaders: []string{"Server"},
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+	r.Header.Set(HeaderRequestID, "req-123")
+	r.Header.Set(HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.Header.Set("X-Debug-Secret", "s3cr3t")
+	w, echoed := serve(router, r)
+
+	assert.Equal(t, "req-123", echoed["request_id"])
+	assert.Equal(t, "req-123", w.Header().Get(HeaderRequestID))
+	assert.Len(t, w.Header().Values(HeaderRequestID), 1)
+	assert.Regexp(t, `^00-4bf92f3577b34da6a3ce929d0e0e4736-[0-9a-f]{16}-01$`, echoed["traceparent"])
+	assert.NotContains(t, echoed["traceparent"], "00f067aa0ba902b7")
+	assert.Equal(t, "dunksense", echoed["service"])
+	assert.Empty(t, echoed["secret"])
+	assert.NotEmpty(t, echoed["forwarded_for"])
+	assert.Empty(t, w.Header().Get("Server"))
+
+	// Missing or malformed IDs are replaced
+	r = httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil)
+	r.Header.Set(HeaderTraceparent, "garbage")
+	w, echoed = serve(router, r)
+	assert.NotEmpty(t, echoed["request_id"])
+	assert.Equal(t, echoed["request_id"], w.Header().Get(HeaderRequestID))
+	assert.Regexp(t, `^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`, echoed["traceparent"])
+}
+
+func TestGateway_StreamsMultipartUploads(t *testing.T) {
+	received := make(chan int, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("video")
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n, _ := io.Copy(io.Discard, file)
+		received <- int(n)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	router := newTestGateway(t, config.GatewayRoute{
+		Prefix:       "/api/v1/ml",
+		Upstreams:    []string{upstream.URL},
+		StripPrefix:  true,
+		MaxBodyBytes: 8 << 20,
+	})
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("video", "jump.mov")
+	require.NoError(t, err)
+	part.Write(bytes.Repeat([]byte("v"), 3<<20))
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/ml/upload/video", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	w, _ := serve(router, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 3<<20, <-received)
+}
+
+func TestGateway_BodyLimit(t *testing.T) {
+	upstream := echoUpstream(t, "metrics")
+	router := newTestGateway(t, config.GatewayRoute{
+		Prefix:       "/api/v1/metrics",
+		Upstreams:    []string{upstream.URL},
+		MaxBodyBytes: 16,
+	})
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodPost, "/api/v1/metrics", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// Without a declared length the limit applies while streaming
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/metrics", io.NopCloser(strings.NewReader(strings.Repeat("x", 64))))
+	r.ContentLength = -1
+	w, _ = serve(router, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestGateway_Timeout(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer upstream.Close()
+
+	router := newTestGateway(t, config.GatewayRoute{
+		Prefix:    "/api/v1/ml",
+		Upstreams: []string{upstream.URL},
+		Timeout:   config.Duration(50 * time.Millisecond),
+	})
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NotEmpty(t, w.Header().Get(HeaderRequestID))
+}
+
+func TestGateway_RoundRobin(t *testing.T) {
+	a := echoUpstream(t, "a")
+	b := echoUpstream(t, "b")
+	router := newTestGateway(t, config.GatewayRoute{Prefix: "/api/v1/metrics", Upstreams: []string{a.URL, b.URL}})
+
+	seen := map[string]int{}
+	for i := 0; i < 4; i++ {
+		_, echoed := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+		seen[echoed["upstream"]]++
+	}
+	assert.Equal(t, map[string]int{"a": 2, "b": 2}, seen)
+}
+
+func TestParseGatewayConfig(t *testing.T) {
+	cfg, err := config.ParseGatewayConfig([]byte(`{"routes": [
+		{"prefix": "/api/v1/ml/", "upstreams": ["http://ml:8081"], "timeout": "2m", "strip_prefix": true}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/ml", cfg.Routes[0].Prefix)
+	assert.Equal(t, config.Duration(2*time.Minute), cfg.Routes[0].Timeout)
+	assert.Positive(t, cfg.Routes[0].MaxBodyBytes)
+
+	_, err = config.ParseGatewayConfig([]byte(`{"routes": [{"prefix": "/x", "upstreams": ["ml:8081"]}]}`))
+	assert.Error(t, err)
+	_, err = config.ParseGatewayConfig([]byte(`{"routes": [{"prefix": "/x", "upstreams": ["http://ml"], "retries": 3}]}`))
+	assert.Error(t, err)
+}
+
+// recordedCalls is a Recorder keeping what it was given
+type recordedCalls struct {
+	upstreams []string
+	errors    []string
+}
+
+func (r *recordedCalls) RecordUpstreamRequest(route, upstream, statusCode string, duration time.Duration) {
+	r.upstreams = append(r.upstreams, route+" "+statusCode)
+}
+
+func (r *recordedCalls) RecordProxyError(route, class string) {
+	r.errors = append(r.errors, route+" "+class)
+}
+
+func TestGateway_Recorder(t *testing.T) {
+	ml := echoUpstream(t, "ml")
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	g, err := New(&config.GatewayConfig{Routes: []config.GatewayRoute{
+		{Prefix: "/api/v1/ml", Upstreams: []string{ml.URL}, Timeout: config.Duration(5 * time.Second), MaxBodyBytes: 1 << 20},
+		{Prefix: "/api/v1/metrics", Upstreams: []string{down.URL}, Timeout: config.Duration(5 * time.Second), MaxBodyBytes: 1 << 20},
+	}}, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	recorder := &recordedCalls{}
+	g.SetRecorder(recorder)
+	router := newRouter(g)
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/athletes/a1", nil))
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+
+	assert.Equal(t, []string{"/api/v1/ml 200", "/api/v1/metrics error"}, recorder.upstreams)
+	assert.Equal(t, []string{"/api/v1/metrics connection_refused"}, recorder.errors)
+
+	assert.Equal(t, errorClassNoUpstream, errorClass(fmt.Errorf("proxy: %w", ErrNoUpstream)))
+	assert.Equal(t, errorClassTimeout, errorClass(context.DeadlineExceeded))
+	assert.Equal(t, errorClassDNS, errorClass(&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "ml"}}))
+	assert.Equal(t, errorClassUpstream, errorClass(io.ErrUnexpectedEOF))
+}
@@ -0,0 +1,92 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// ConfigWatcher reloads the gateway when its configuration file changes.
+// An invalid file is logged and the running routes stay in place.
+type ConfigWatcher struct {
+	gateway *Gateway
+	path    string
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	modTime time.Time
+}
+
+// NewConfigWatcher creates a watcher for the file the gateway was loaded from
+func NewConfigWatcher(g *Gateway, path string, logger *zap.Logger) *ConfigWatcher {
+	w := &ConfigWatcher{gateway: g, path: path, logger: logger}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+	}
+	return w
+}
+
+// Reload reads the file and replaces the gateway's routes
+func (w *ConfigWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat gateway config: %w", err)
+	}
+	return w.reload(info.ModTime())
+}
+
+// ReloadIfChanged reloads the routes when the file was modified since the last load
+func (w *ConfigWatcher) ReloadIfChanged() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat gateway config: %w", err)
+	}
+	if info.ModTime().Equal(w.modTime) {
+		return nil
+	}
+	return w.reload(info.ModTime())
+}
+
+func (w *ConfigWatcher) reload(modTime time.Time) error {
+	// Remember the attempt so a broken file is reported once, not on every poll
+	w.modTime = modTime
+
+	cfg, err := config.ReadGatewayConfig(w.path)
+	if err != nil {
+		return err
+	}
+	if err := w.gateway.Reload(cfg); err != nil {
+		return err
+	}
+	w.logger.Info("Gateway configuration reloaded", zap.String("path", w.path), zap.Int("routes", len(cfg.Routes)))
+	return nil
+}
+
+// Watch polls the file until ctx is done
+func (w *ConfigWatcher) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.ReloadIfChanged(); err != nil {
+				w.logger.Error("Failed to reload gateway configuration, keeping previous routes", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
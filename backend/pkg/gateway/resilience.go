@@ -0,0 +1,218 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// ErrNoUpstream is returned when every upstream of a route is ejected, tripped or saturated
+var ErrNoUpstream = errors.New("no upstream available")
+
+// retryBudget earns a fraction of a retry for every request and caps the balance,
+// so retries cannot multiply the load on an upstream that is already struggling
+type retryBudget struct {
+	mu      sync.Mutex
+	balance float64
+	ratio   float64
+	burst   float64
+}
+
+func newRetryBudget(ratio float64, burst int) *retryBudget {
+	return &retryBudget{balance: float64(burst), ratio: ratio, burst: float64(burst)}
+}
+
+func (b *retryBudget) deposit() {
+	b.mu.Lock()
+	b.balance += b.ratio
+	if b.balance > b.burst {
+		b.balance = b.burst
+	}
+	b.mu.Unlock()
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.balance < 1 {
+		return false
+	}
+	b.balance--
+	return true
+}
+
+// RoundTrip sends the request to an available upstream, retrying idempotent
+// requests without a body on another upstream after failures
+func (rt *route) RoundTrip(req *http.Request) (*http.Response, error) {
+	ex := req.Context().Value(exchangeKey{}).(*exchange)
+	policy := rt.config.Retry
+	retryable := policy.Attempts > 0 && isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody)
+	ex.variant.budget.deposit()
+
+	tried := make(map[*Upstream]bool)
+	for attempt := 0; ; attempt++ {
+		upstream := ex.variant.pool.acquire(rt.config.CircuitBreaker, tried, time.Now())
+		if upstream == nil {
+			rt.metrics.unavailable.WithLabelValues(rt.config.Prefix).Inc()
+			return nil, ErrNoUpstream
+		}
+		tried[upstream] = true
+		ex.upstream = upstream
+
+		resp, err := rt.try(req, ex.variant.pool, upstream)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt >= policy.Attempts || !retryable || req.Context().Err() != nil {
+			return resp, err
+		}
+		if !ex.variant.budget.withdraw() {
+			rt.metrics.retries.WithLabelValues(rt.config.Prefix, "budget_exhausted").Inc()
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		rt.logger.Debug("Retrying proxied request",
+			zap.String("route", rt.config.Prefix),
+			zap.String("upstream", upstream.URL.Host),
+			zap.String("request_id", ex.requestID),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+		rt.metrics.retries.WithLabelValues(rt.config.Prefix, "retried").Inc()
+
+		select {
+		case <-time.After(rt.backoff(attempt)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// try sends one attempt to an upstream and records its result. The upstream's
+// slot is held until the response body is closed.
+func (rt *route) try(req *http.Request, pool *Pool, upstream *Upstream) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if perTry := rt.config.Retry.PerTryTimeout; perTry > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(perTry))
+	}
+
+	out := req.Clone(ctx)
+	out.URL = upstream.target(req.URL)
+	out.Host = ""
+	if ex := req.Context().Value(exchangeKey{}).(*exchange); ex.signer != nil {
+		out.Header.Set(security.IdentityHeader, ex.signer.Sign(ex.identity, out.Method, out.URL.Path, ex.requestID, time.Now()))
+	}
+
+	start := time.Now()
+	resp, err := rt.transport.RoundTrip(out)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	rt.metrics.observeUpstream(rt.config.Prefix, upstream.URL.Host, status, time.Since(start))
+
+	if err != nil && req.Context().Err() == context.Canceled {
+		// The client went away; that says nothing about the upstream
+		upstream.abandon()
+	} else {
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		result := pool.record(upstream, rt.config, failed, time.Now())
+		rt.observe(upstream, result)
+	}
+
+	if err != nil {
+		upstream.release()
+		cancel()
+		return nil, err
+	}
+	release := func() {
+		upstream.release()
+		cancel()
+	}
+	if conn, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+		// The proxy needs a writable body to copy upgraded connections
+		resp.Body = &releasingConn{ReadWriteCloser: conn, release: release}
+	} else {
+		resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	}
+	return resp, nil
+}
+
+// observe logs and counts breaker trips and ejections
+func (rt *route) observe(upstream *Upstream, result outcome) {
+	if result.opened {
+		rt.metrics.breakerOpened.WithLabelValues(rt.config.Prefix, upstream.URL.Host).Inc()
+		rt.logger.Warn("Circuit breaker opened",
+			zap.String("route", rt.config.Prefix),
+			zap.String("upstream", upstream.URL.Host),
+		)
+	}
+	if result.ejected {
+		rt.metrics.ejections.WithLabelValues(rt.config.Prefix, upstream.URL.Host).Inc()
+		rt.logger.Warn("Upstream ejected after consecutive failures",
+			zap.String("route", rt.config.Prefix),
+			zap.String("upstream", upstream.URL.Host),
+		)
+	}
+}
+
+// backoff returns a fully jittered exponential delay before the next attempt
+func (rt *route) backoff(attempt int) time.Duration {
+	base := time.Duration(rt.config.Retry.BaseBackoff)
+	limit := time.Duration(rt.config.Retry.MaxBackoff)
+	delay := base << attempt
+	if delay <= 0 || delay > limit {
+		delay = limit
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// releasingBody runs release once when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// releasingConn is releasingBody for upgraded connections
+type releasingConn struct {
+	io.ReadWriteCloser
+	once    sync.Once
+	release func()
+}
+
+func (c *releasingConn) Close() error {
+	err := c.ReadWriteCloser.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,243 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// statusUpstream answers every request with status and counts the calls
+func statusUpstream(t *testing.T, status int, calls *atomic.Int64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newResilientGateway builds a gateway for one route with the config defaults applied
+func newResilientGateway(t *testing.T, rc config.GatewayRoute) (*Gateway, http.Handler) {
+	data, err := json.Marshal(config.GatewayConfig{Routes: []config.GatewayRoute{rc}})
+	require.NoError(t, err)
+	cfg, err := config.ParseGatewayConfig(data)
+	require.NoError(t, err)
+
+	g, err := New(cfg, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	return g, newRouter(g)
+}
+
+func TestGateway_RetriesIdempotentRequests(t *testing.T) {
+	var badCalls, goodCalls atomic.Int64
+	bad := statusUpstream(t, http.StatusServiceUnavailable, &badCalls)
+	good := statusUpstream(t, http.StatusOK, &goodCalls)
+
+	g, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:    "/api/v1/metrics",
+		Upstreams: []string{bad.URL, good.URL},
+		Timeout:   config.Duration(5 * time.Second),
+		Retry:     config.RetryPolicy{Attempts: 1, BaseBackoff: config.Duration(time.Millisecond)},
+	})
+
+	// Round robin sends every request to the failing upstream first
+	for i := 0; i < 4; i++ {
+		w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.Equal(t, int64(4), badCalls.Load())
+	assert.Equal(t, float64(4), testutil.ToFloat64(g.metrics.retries.WithLabelValues("/api/v1/metrics", "retried")))
+
+	// Requests with a body are never replayed
+	w, _ := serve(router, httptest.NewRequest(http.MethodPost, "/api/v1/metrics", strings.NewReader(`{}`)))
+	w2, _ := serve(router, httptest.NewRequest(http.MethodPost, "/api/v1/metrics", strings.NewReader(`{}`)))
+	assert.ElementsMatch(t, []int{http.StatusOK, http.StatusServiceUnavailable}, []int{w.Code, w2.Code})
+}
+
+func TestGateway_RetryBudget(t *testing.T) {
+	var calls atomic.Int64
+	bad := statusUpstream(t, http.StatusBadGateway, &calls)
+	bad2 := statusUpstream(t, http.StatusBadGateway, &calls)
+
+	g, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:    "/api/v1/metrics",
+		Upstreams: []string{bad.URL, bad2.URL},
+		Timeout:   config.Duration(5 * time.Second),
+		Retry:     config.RetryPolicy{Attempts: 1, BaseBackoff: config.Duration(time.Millisecond), BudgetRatio: 0.1, BudgetBurst: 2},
+	})
+
+	for i := 0; i < 5; i++ {
+		w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(g.metrics.retries.WithLabelValues("/api/v1/metrics", "retried")))
+	assert.Equal(t, float64(3), testutil.ToFloat64(g.metrics.retries.WithLabelValues("/api/v1/metrics", "budget_exhausted")))
+	assert.Equal(t, int64(7), calls.Load())
+}
+
+func TestGateway_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int64
+	failing := statusUpstream(t, http.StatusInternalServerError, &calls)
+
+	g, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:    "/api/v1/ml",
+		Upstreams: []string{failing.URL},
+		Timeout:   config.Duration(5 * time.Second),
+		CircuitBreaker: config.CircuitBreakerPolicy{
+			FailureRatio: 0.5,
+			MinRequests:  4,
+			Window:       config.Duration(time.Minute),
+			OpenTimeout:  config.Duration(50 * time.Millisecond),
+		},
+	})
+
+	for i := 0; i < 4; i++ {
+		w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	}
+
+	// The open breaker fails fast without reaching the upstream
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, int64(4), calls.Load())
+
+	upstream := (*g.routes.Load())[0].variants[0].pool.Upstreams()[0]
+	state, _ := upstream.State(time.Now())
+	assert.Equal(t, BreakerOpen, state)
+	assert.Equal(t, float64(1), testutil.ToFloat64(g.metrics.breakerOpened.WithLabelValues("/api/v1/ml", upstream.URL.Host)))
+
+	// After the open timeout a failed probe opens it again
+	time.Sleep(60 * time.Millisecond)
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	state, _ = upstream.State(time.Now())
+	assert.Equal(t, BreakerOpen, state)
+}
+
+func TestGateway_MaxConcurrent(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	_, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:         "/api/v1/ml",
+		Upstreams:      []string{slow.URL},
+		Timeout:        config.Duration(5 * time.Second),
+		CircuitBreaker: config.CircuitBreakerPolicy{MaxConcurrent: 1},
+	})
+
+	go serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/slow", nil))
+	<-started
+
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGateway_OutlierEjection(t *testing.T) {
+	var badCalls, goodCalls atomic.Int64
+	bad := statusUpstream(t, http.StatusInternalServerError, &badCalls)
+	good := statusUpstream(t, http.StatusOK, &goodCalls)
+
+	g, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:           "/api/v1/metrics",
+		Upstreams:        []string{bad.URL, good.URL},
+		Timeout:          config.Duration(5 * time.Second),
+		OutlierDetection: config.OutlierPolicy{ConsecutiveFailures: 2},
+	})
+
+	for i := 0; i < 10; i++ {
+		serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+	}
+	assert.Equal(t, int64(2), badCalls.Load())
+	assert.Equal(t, int64(8), goodCalls.Load())
+
+	pool := (*g.routes.Load())[0].variants[0].pool
+	_, ejected := pool.Upstreams()[0].State(time.Now())
+	assert.True(t, ejected)
+	_, ejected = pool.Upstreams()[1].State(time.Now())
+	assert.False(t, ejected)
+}
+
+func TestGateway_OutlierEjectionKeepsLastUpstream(t *testing.T) {
+	var calls atomic.Int64
+	bad := statusUpstream(t, http.StatusInternalServerError, &calls)
+
+	_, router := newResilientGateway(t, config.GatewayRoute{
+		Prefix:           "/api/v1/metrics",
+		Upstreams:        []string{bad.URL},
+		Timeout:          config.Duration(5 * time.Second),
+		OutlierDetection: config.OutlierPolicy{ConsecutiveFailures: 1},
+	})
+
+	for i := 0; i < 3; i++ {
+		w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	}
+	assert.Equal(t, int64(3), calls.Load())
+}
+
+func TestConfigWatcher_ReloadKeepsUpstreamState(t *testing.T) {
+	var calls atomic.Int64
+	a := statusUpstream(t, http.StatusInternalServerError, &calls)
+	b := statusUpstream(t, http.StatusOK, &calls)
+
+	path := filepath.Join(t.TempDir(), "gateway.json")
+	write := func(body string) {
+		require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	}
+	write(`{"routes": [{"prefix": "/api/v1/metrics", "upstreams": ["` + a.URL + `"],
+		"circuit_breaker": {"failure_ratio": 0.5, "min_requests": 1}}]}`)
+
+	cfg, err := config.ReadGatewayConfig(path)
+	require.NoError(t, err)
+	g, err := New(cfg, http.DefaultTransport, zap.NewNop())
+	require.NoError(t, err)
+	router := newRouter(g)
+	watcher := NewConfigWatcher(g, path, zap.NewNop())
+
+	serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+	tripped := (*g.routes.Load())[0].variants[0].pool.Upstreams()[0]
+	state, _ := tripped.State(time.Now())
+	require.Equal(t, BreakerOpen, state)
+
+	// A broken file keeps the running routes
+	write(`{"routes": [`)
+	assert.Error(t, watcher.Reload())
+	assert.Len(t, *g.routes.Load(), 1)
+
+	write(`{"routes": [
+		{"prefix": "/api/v1/metrics", "upstreams": ["` + a.URL + `", "` + b.URL + `"],
+			"circuit_breaker": {"failure_ratio": 0.5, "min_requests": 1}},
+		{"prefix": "/api/v1/ml", "upstreams": ["` + b.URL + `"], "strip_prefix": true}
+	]}`)
+	require.NoError(t, watcher.Reload())
+	assert.NoError(t, watcher.ReloadIfChanged())
+
+	routes := *g.routes.Load()
+	require.Len(t, routes, 2)
+	metrics := g.match("/api/v1/metrics")
+	assert.Same(t, tripped, metrics.variants[0].pool.Upstreams()[0])
+
+	// Requests avoid the tripped upstream that survived the reload
+	w, _ := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/ml/models", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,83 @@
+package gateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceparent = "traceparent"
+
+	maxRequestIDLength = 128
+)
+
+// requestID returns the client's request ID when it is safe to forward, or a new one
+func requestID(incoming string) string {
+	if incoming == "" || len(incoming) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, r := range incoming {
+		if r < 0x21 || r > 0x7e {
+			return uuid.NewString()
+		}
+	}
+	return incoming
+}
+
+// childTraceparent continues the W3C trace of the incoming request with a new
+// span for the gateway hop, or starts a new sampled trace when the incoming
+// header is missing or malformed
+func childTraceparent(incoming string) string {
+	traceID, flags, ok := parseTraceparent(incoming)
+	if !ok {
+		traceID, flags = randomHex(16), "01"
+	}
+	return "00-" + traceID + "-" + randomHex(8) + "-" + flags
+}
+
+// parseTraceparent extracts the trace ID and flags of a traceparent header
+func parseTraceparent(value string) (traceID, flags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return "", "", false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+	if !isLowerHex(traceID, 32) || traceID == strings.Repeat("0", 32) {
+		return "", "", false
+	}
+	if !isLowerHex(parentID, 16) || parentID == strings.Repeat("0", 16) {
+		return "", "", false
+	}
+	if !isLowerHex(flags, 2) {
+		return "", "", false
+	}
+	return traceID, flags, true
+}
+
+func isLowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(bytes int) string {
+	b := make([]byte, bytes)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,282 @@
+package gateway
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// BreakerState is the state of an upstream's circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// Upstream is one instance of a backend service. Its breaker and outlier state
+// survive config reloads as long as the URL stays in the route.
+type Upstream struct {
+	URL *url.URL
+
+	inflight atomic.Int64
+
+	mu           sync.Mutex
+	state        BreakerState
+	windowStart  time.Time
+	requests     int
+	failures     int
+	openedAt     time.Time
+	probing      bool
+	consecutive  int
+	ejectedUntil time.Time
+	ejections    int
+}
+
+// State returns the breaker state and whether the upstream is currently ejected
+func (u *Upstream) State(now time.Time) (BreakerState, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.state, now.Before(u.ejectedUntil)
+}
+
+// target returns the URL of a request path on this upstream
+func (u *Upstream) target(in *url.URL) *url.URL {
+	out := *in
+	out.Scheme = u.URL.Scheme
+	out.Host = u.URL.Host
+	if base := strings.TrimSuffix(u.URL.Path, "/"); base != "" {
+		out.Path = base + in.Path
+		if in.RawPath != "" {
+			out.RawPath = strings.TrimSuffix(u.URL.EscapedPath(), "/") + in.RawPath
+		}
+	}
+	return &out
+}
+
+// acquire reserves a request slot, or reports that the upstream must be skipped
+func (u *Upstream) acquire(policy config.CircuitBreakerPolicy, now time.Time) bool {
+	if policy.MaxConcurrent > 0 {
+		if u.inflight.Add(1) > int64(policy.MaxConcurrent) {
+			u.inflight.Add(-1)
+			return false
+		}
+	} else {
+		u.inflight.Add(1)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	allowed := true
+	switch {
+	case now.Before(u.ejectedUntil):
+		allowed = false
+	case u.state == BreakerOpen && now.Sub(u.openedAt) >= time.Duration(policy.OpenTimeout):
+		u.state = BreakerHalfOpen
+		fallthrough
+	case u.state == BreakerHalfOpen:
+		// One probe at a time decides whether the breaker closes again
+		allowed = !u.probing
+		u.probing = allowed
+	case u.state == BreakerOpen:
+		allowed = false
+	}
+	if !allowed {
+		u.inflight.Add(-1)
+	}
+	return allowed
+}
+
+// release frees a slot taken by acquire
+func (u *Upstream) release() {
+	u.inflight.Add(-1)
+}
+
+// abandon gives up a half-open probe whose result says nothing about the upstream
+func (u *Upstream) abandon() {
+	u.mu.Lock()
+	u.probing = false
+	u.mu.Unlock()
+}
+
+// outcome reports the state changes caused by a recorded result
+type outcome struct {
+	opened  bool
+	ejected bool
+}
+
+// record updates the breaker and outlier state with the result of a request
+func (u *Upstream) record(route config.GatewayRoute, failed bool, now time.Time) outcome {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var result outcome
+	breaker := route.CircuitBreaker
+
+	switch u.state {
+	case BreakerHalfOpen:
+		u.probing = false
+		if failed {
+			u.state, u.openedAt = BreakerOpen, now
+			result.opened = true
+		} else {
+			u.state = BreakerClosed
+			u.windowStart, u.requests, u.failures = now, 0, 0
+		}
+	case BreakerClosed:
+		if breaker.FailureRatio <= 0 {
+			break
+		}
+		if now.Sub(u.windowStart) > time.Duration(breaker.Window) {
+			u.windowStart, u.requests, u.failures = now, 0, 0
+		}
+		u.requests++
+		if failed {
+			u.failures++
+		}
+		if u.requests >= breaker.MinRequests && float64(u.failures)/float64(u.requests) >= breaker.FailureRatio {
+			u.state, u.openedAt = BreakerOpen, now
+			result.opened = true
+		}
+	}
+
+	if !failed {
+		u.consecutive = 0
+	} else {
+		u.consecutive++
+	}
+	return result
+}
+
+// eject removes the upstream from rotation for a time growing with repeated ejections
+func (u *Upstream) eject(policy config.OutlierPolicy, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// Ejections are forgotten once the upstream has behaved for the maximum ejection time
+	if now.Sub(u.ejectedUntil) > time.Duration(policy.MaxEjectionTime) {
+		u.ejections = 0
+	}
+	u.ejections++
+	duration := time.Duration(u.ejections) * time.Duration(policy.BaseEjectionTime)
+	if duration > time.Duration(policy.MaxEjectionTime) {
+		duration = time.Duration(policy.MaxEjectionTime)
+	}
+	u.ejectedUntil = now.Add(duration)
+	u.consecutive = 0
+}
+
+// shouldEject reports whether the upstream reached the consecutive failure limit
+func (u *Upstream) shouldEject(policy config.OutlierPolicy) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return policy.ConsecutiveFailures > 0 && u.consecutive >= policy.ConsecutiveFailures
+}
+
+// Pool balances requests over the upstreams of a route
+type Pool struct {
+	upstreams []*Upstream
+	next      atomic.Uint64
+	ejectMu   sync.Mutex
+}
+
+// NewPool creates a round-robin pool from upstream base URLs
+func NewPool(urls []string) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("pool has no upstreams")
+	}
+
+	pool := &Pool{upstreams: make([]*Upstream, 0, len(urls))}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream %q: %w", raw, err)
+		}
+		pool.upstreams = append(pool.upstreams, &Upstream{URL: u})
+	}
+	return pool, nil
+}
+
+// inherit takes over the state of upstreams that were already in previous
+func (p *Pool) inherit(previous *Pool) {
+	if previous == nil {
+		return
+	}
+	for i, u := range p.upstreams {
+		for _, old := range previous.upstreams {
+			if old.URL.String() == u.URL.String() {
+				p.upstreams[i] = old
+				break
+			}
+		}
+	}
+}
+
+// Pick returns the next upstream in turn
+func (p *Pool) Pick() *Upstream {
+	n := p.next.Add(1) - 1
+	return p.upstreams[n%uint64(len(p.upstreams))]
+}
+
+// acquire picks the next upstream that is not ejected, not tripped and below
+// its concurrency limit, skipping those already tried. It returns nil when
+// none is available.
+func (p *Pool) acquire(policy config.CircuitBreakerPolicy, tried map[*Upstream]bool, now time.Time) *Upstream {
+	for range p.upstreams {
+		u := p.Pick()
+		if tried[u] {
+			continue
+		}
+		if u.acquire(policy, now) {
+			return u
+		}
+	}
+	return nil
+}
+
+// record applies a request result to an upstream, ejecting it when it keeps
+// failing and the pool can spare it
+func (p *Pool) record(u *Upstream, route config.GatewayRoute, failed bool, now time.Time) outcome {
+	result := u.record(route, failed, now)
+	if !failed || !u.shouldEject(route.OutlierDetection) {
+		return result
+	}
+
+	p.ejectMu.Lock()
+	defer p.ejectMu.Unlock()
+
+	ejected := 0
+	for _, other := range p.upstreams {
+		if _, out := other.State(now); out {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 <= route.OutlierDetection.MaxEjectedPercent*len(p.upstreams) {
+		u.eject(route.OutlierDetection, now)
+		result.ejected = true
+	}
+	return result
+}
+
+// Upstreams returns the members of the pool
+func (p *Pool) Upstreams() []*Upstream {
+	return p.upstreams
+}
@@ -0,0 +1,219 @@
+package gdpr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/storage"
+)
+
+type fakeSource struct {
+	erased []string
+}
+
+func (s *fakeSource) Name() string { return "fake" }
+
+func (s *fakeSource) Export(ctx context.Context, userID string, archive *Archive) error {
+	if err := archive.WriteJSON("profile.json", map[string]string{"user_id": userID}); err != nil {
+		return err
+	}
+	return archive.WriteCSV("jumps.csv", []string{"height_cm"}, [][]string{{"55.5"}})
+}
+
+func (s *fakeSource) Erase(ctx context.Context, userID string) (SystemResult, error) {
+	s.erased = append(s.erased, userID)
+	return SystemResult{Deleted: 1, Anonymized: 3}, nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeSource, *storage.MemoryObjectStore, *time.Time) {
+	source := &fakeSource{}
+	objects := storage.NewMemoryObjectStore()
+	m := NewManager(NewMemoryRequestStore(), objects, DefaultConfig(), nil, zap.NewNop(), source)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m, source, objects, &now
+}
+
+func TestManager_Export(t *testing.T) {
+	ctx := context.Background()
+	m, _, _, now := newTestManager(t)
+
+	req, err := m.RequestExport(ctx, "user-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, req.Status)
+
+	_, err = m.RequestExport(ctx, "user-1", "user-1")
+	assert.ErrorIs(t, err, ErrRequestInProgress)
+
+	_, _, err = m.OpenExport(ctx, "user-1", req.ID)
+	assert.ErrorIs(t, err, ErrExportNotReady)
+
+	m.ProcessDue(ctx)
+
+	req, err = m.Get(ctx, "user-1", req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, req.Status)
+
+	body, info, err := m.OpenExport(ctx, "user-1", req.ID)
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	body.Close()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), info.Size)
+	require.NoError(t, err)
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"profile.json", "jumps.csv", "manifest.json"}, names)
+
+	// Other users cannot see the export
+	_, _, err = m.OpenExport(ctx, "user-2", req.ID)
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+
+	// The archive is removed after the retention period
+	*now = now.Add(8 * 24 * time.Hour)
+	m.ProcessDue(ctx)
+	_, _, err = m.OpenExport(ctx, "user-1", req.ID)
+	assert.ErrorIs(t, err, ErrExportExpired)
+}
+
+func TestManager_DeletionGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	m, source, objects, now := newTestManager(t)
+
+	req, err := m.RequestDeletion(ctx, "user-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusScheduled, req.Status)
+	assert.Equal(t, now.Add(30*24*time.Hour), req.ScheduledFor)
+
+	// Nothing happens during the grace period
+	*now = now.Add(29 * 24 * time.Hour)
+	m.ProcessDue(ctx)
+	assert.Empty(t, source.erased)
+
+	// An export taken meanwhile must be removed together with the data
+	_, err = m.RequestExport(ctx, "user-1", "user-1")
+	require.NoError(t, err)
+	m.ProcessDue(ctx)
+
+	*now = now.Add(2 * 24 * time.Hour)
+	m.ProcessDue(ctx)
+	assert.Equal(t, []string{"user-1"}, source.erased)
+
+	req, err = m.Get(ctx, "user-1", req.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, req.Status)
+	require.NotNil(t, req.Receipt)
+	assert.Equal(t, []SystemResult{
+		{System: "fake", Deleted: 1, Anonymized: 3},
+		{System: "exports", Deleted: 1},
+	}, req.Receipt.Systems)
+	assert.Equal(t, req.Receipt.computeDigest(), req.Receipt.Digest)
+
+	remaining, err := objects.List(ctx, DefaultConfig().ExportBucket, "")
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestManager_CancelDeletion(t *testing.T) {
+	ctx := context.Background()
+	m, source, _, now := newTestManager(t)
+
+	req, err := m.RequestDeletion(ctx, "user-1", "user-1")
+	require.NoError(t, err)
+
+	_, err = m.CancelDeletion(ctx, "user-2", req.ID, "user-2")
+	assert.ErrorIs(t, err, ErrRequestNotFound)
+
+	req, err = m.CancelDeletion(ctx, "user-1", req.ID, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCanceled, req.Status)
+
+	*now = now.Add(31 * 24 * time.Hour)
+	m.ProcessDue(ctx)
+	assert.Empty(t, source.erased)
+
+	_, err = m.CancelDeletion(ctx, "user-1", req.ID, "user-1")
+	assert.ErrorIs(t, err, ErrRequestNotPending)
+
+	// A new deletion can be requested after a cancellation
+	_, err = m.RequestDeletion(ctx, "user-1", "user-1")
+	assert.NoError(t, err)
+}
+
+func TestManager_GuardianRoutes(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	m.SetGuardianCheck(func(ctx context.Context, guardianID, userID string) (bool, error) {
+		return guardianID == "parent-1" && userID == "minor-1", nil
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	rg := router.Group("/guardian", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+	})
+	m.RegisterGuardianRoutes(rg)
+
+	request := func(caller, method, path string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("X-User", caller)
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, request("parent-1", http.MethodPost, "/guardian/users/minor-1/exports"))
+	assert.Equal(t, http.StatusOK, request("parent-1", http.MethodGet, "/guardian/users/minor-1/data-requests"))
+	assert.Equal(t, http.StatusForbidden, request("parent-2", http.MethodPost, "/guardian/users/minor-1/exports"))
+	assert.Equal(t, http.StatusForbidden, request("parent-1", http.MethodGet, "/guardian/users/minor-2/data-requests"))
+
+	requests, err := m.List(context.Background(), "minor-1")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "parent-1", requests[0].RequestedBy)
+}
+
+// failingObjectStore fails every delete after the first
+type failingObjectStore struct {
+	*storage.MemoryObjectStore
+	deletes int
+}
+
+func (s *failingObjectStore) Delete(ctx context.Context, bucket, key string) error {
+	s.deletes++
+	if s.deletes > 1 {
+		return errors.New("connection reset")
+	}
+	return s.MemoryObjectStore.Delete(ctx, bucket, key)
+}
+
+func TestVideoSource_ErasePartialFailure(t *testing.T) {
+	ctx := context.Background()
+	objects := &failingObjectStore{MemoryObjectStore: storage.NewMemoryObjectStore()}
+	for _, key := range []string{"user-1/a.mp4", "user-1/b.mp4", "user-1/c.mp4"} {
+		require.NoError(t, objects.Put(ctx, "videos", key, bytes.NewReader([]byte("video")), 5, "video/mp4"))
+	}
+
+	result, err := NewVideoSource(objects, "videos").Erase(ctx, "user-1")
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), result.Deleted)
+
+	remaining, err := objects.List(ctx, "videos", "user-1/")
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2)
+}
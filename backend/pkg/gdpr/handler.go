@@ -0,0 +1,157 @@
+package gdpr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterRoutes mounts self-service export and deletion for the authenticated
+// user. The group must already be protected by JWTAuth.
+func (m *Manager) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/requests", m.listRequests)
+	rg.GET("/requests/:id", m.getRequest)
+	rg.POST("/exports", m.requestExport)
+	rg.GET("/exports/:id/download", m.downloadExport)
+	rg.POST("/deletion", m.requestDeletion)
+	rg.DELETE("/deletion/:id", m.cancelDeletion)
+}
+
+// RegisterAdminRoutes mounts export and deletion on behalf of a user, e.g.
+// for requests received by email. The group must already be protected by
+// JWTAuth and RequireRole.
+func (m *Manager) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/users/:user_id/data-requests", m.listRequests)
+	rg.POST("/users/:user_id/exports", m.requestExport)
+	rg.POST("/users/:user_id/deletion", m.requestDeletion)
+}
+
+// RegisterGuardianRoutes lets guardians see and export the data of the
+// minors they consented for. The group must already be protected by JWTAuth,
+// and SetGuardianCheck must have been called.
+func (m *Manager) RegisterGuardianRoutes(rg *gin.RouterGroup) {
+	guardian := rg.Group("/users/:user_id", m.requireGuardian)
+	guardian.GET("/data-requests", m.listRequests)
+	guardian.GET("/data-requests/:id", m.getRequest)
+	guardian.POST("/exports", m.requestExport)
+	guardian.GET("/exports/:id/download", m.downloadExport)
+}
+
+// requireGuardian allows only the guardian of the path user
+func (m *Manager) requireGuardian(c *gin.Context) {
+	if m.isGuardian == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Guardian access is not enabled"})
+		c.Abort()
+		return
+	}
+
+	guardianID := c.GetString("user_id")
+	ok, err := m.isGuardian(c.Request.Context(), guardianID, c.Param("user_id"))
+	if err != nil {
+		m.logger.Error("Failed to check guardian", zap.String("user_id", c.Param("user_id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check guardian"})
+		c.Abort()
+		return
+	}
+	if guardianID == "" || !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Not a guardian of this user"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// subject returns the user a request is about: the path user on admin and
+// guardian routes, the caller otherwise
+func subject(c *gin.Context) string {
+	if userID := c.Param("user_id"); userID != "" {
+		return userID
+	}
+	return c.GetString("user_id")
+}
+
+func (m *Manager) listRequests(c *gin.Context) {
+	requests, err := m.List(c.Request.Context(), subject(c))
+	if err != nil {
+		m.logger.Error("Failed to list data requests", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list data requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+func (m *Manager) getRequest(c *gin.Context) {
+	req, err := m.Get(c.Request.Context(), subject(c), c.Param("id"))
+	if err != nil {
+		m.respondError(c, "Failed to get data request", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+func (m *Manager) requestExport(c *gin.Context) {
+	req, err := m.RequestExport(c.Request.Context(), subject(c), c.GetString("user_id"))
+	if err != nil {
+		m.respondError(c, "Failed to request export", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, req)
+}
+
+func (m *Manager) downloadExport(c *gin.Context) {
+	body, info, err := m.OpenExport(c.Request.Context(), subject(c), c.Param("id"))
+	if err != nil {
+		m.respondError(c, "Failed to download export", err)
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, "application/zip", body, map[string]string{
+		"Content-Disposition": `attachment; filename="dunksense-export-` + c.Param("id") + `.zip"`,
+		"Cache-Control":       "no-store",
+	})
+}
+
+func (m *Manager) requestDeletion(c *gin.Context) {
+	req, err := m.RequestDeletion(c.Request.Context(), subject(c), c.GetString("user_id"))
+	if err != nil {
+		m.respondError(c, "Failed to request deletion", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, req)
+}
+
+func (m *Manager) cancelDeletion(c *gin.Context) {
+	req, err := m.CancelDeletion(c.Request.Context(), subject(c), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		m.respondError(c, "Failed to cancel deletion", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// respondError maps manager errors to HTTP responses
+func (m *Manager) respondError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data request not found"})
+	case errors.Is(err, ErrRequestInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": "A request of this type is already in progress"})
+	case errors.Is(err, ErrRequestNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": "Data request can no longer be canceled"})
+	case errors.Is(err, ErrExportNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": "Export is not ready"})
+	case errors.Is(err, ErrExportExpired):
+		c.JSON(http.StatusGone, gin.H{"error": "Export has expired"})
+	default:
+		m.logger.Error(message, zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
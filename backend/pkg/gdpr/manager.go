@@ -0,0 +1,560 @@
+package gdpr
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/audit"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/storage"
+)
+
+// Config controls exports and deletions
+type Config struct {
+	DeletionGracePeriod time.Duration // time a user has to cancel a deletion
+	ExportRetention     time.Duration // how long an export archive can be downloaded
+	ExportBucket        string        // bucket for export archives
+	PollInterval        time.Duration // how often the worker looks for due requests
+	LeaseDuration       time.Duration // how long a worker may hold a request
+	MaxAttempts         int           // attempts before a request is marked failed
+}
+
+// DefaultConfig returns the default data request settings
+func DefaultConfig() Config {
+	return Config{
+		DeletionGracePeriod: 30 * 24 * time.Hour,
+		ExportRetention:     7 * 24 * time.Hour,
+		ExportBucket:        "dunksense-exports",
+		PollInterval:        time.Minute,
+		LeaseDuration:       30 * time.Minute,
+		MaxAttempts:         5,
+	}
+}
+
+// Source is a system that stores personal data, e.g. the metrics store or
+// the video bucket. Every source takes part in exports and deletions.
+type Source interface {
+	Name() string
+	Export(ctx context.Context, userID string, archive *Archive) error
+	Erase(ctx context.Context, userID string) (SystemResult, error)
+}
+
+// SystemResult counts what a source removed for a deletion
+type SystemResult struct {
+	System     string `json:"system"`
+	Deleted    int64  `json:"deleted"`
+	Anonymized int64  `json:"anonymized"`
+}
+
+// Receipt confirms a completed deletion. It contains no personal data besides
+// the user ID so it can be kept as proof after the data itself is gone.
+type Receipt struct {
+	RequestID   string         `json:"request_id"`
+	UserID      string         `json:"user_id"`
+	RequestedAt time.Time      `json:"requested_at"`
+	CompletedAt time.Time      `json:"completed_at"`
+	Systems     []SystemResult `json:"systems"`
+	Digest      string         `json:"digest"` // SHA-256 of the receipt without the digest
+}
+
+// computeDigest hashes the receipt content
+func (r *Receipt) computeDigest() string {
+	content := *r
+	content.Digest = ""
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Archive is the ZIP file an export is written to
+type Archive struct {
+	zip   *zip.Writer
+	files []string
+}
+
+// WriteJSON adds an indented JSON file
+func (a *Archive) WriteJSON(name string, v interface{}) error {
+	w, err := a.create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// WriteCSV adds a CSV file
+func (a *Archive) WriteCSV(name string, header []string, rows [][]string) error {
+	w, err := a.create(name)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func (a *Archive) create(name string) (io.Writer, error) {
+	w, err := a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	a.files = append(a.files, name)
+	return w, nil
+}
+
+// Manager runs export and deletion requests in the background
+type Manager struct {
+	store   RequestStore
+	objects storage.ObjectStore
+	sources []Source
+	config  Config
+	audit   *audit.Logger
+	logger  *zap.Logger
+
+	onErased   func(ctx context.Context, userID string) error
+	isGuardian func(ctx context.Context, guardianID, userID string) (bool, error)
+	wake       chan struct{}
+	now        func() time.Time
+}
+
+// NewManager creates a manager for the given sources of personal data
+func NewManager(store RequestStore, objects storage.ObjectStore, config Config, auditLogger *audit.Logger, logger *zap.Logger, sources ...Source) *Manager {
+	defaults := DefaultConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = defaults.LeaseDuration
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.ExportRetention <= 0 {
+		config.ExportRetention = defaults.ExportRetention
+	}
+
+	return &Manager{
+		store:   store,
+		objects: objects,
+		sources: sources,
+		config:  config,
+		audit:   auditLogger,
+		logger:  logger,
+		wake:    make(chan struct{}, 1),
+		now:     time.Now,
+	}
+}
+
+// OnErased registers a hook that runs after all sources erased a user,
+// e.g. to revoke the user's tokens
+func (m *Manager) OnErased(hook func(ctx context.Context, userID string) error) {
+	m.onErased = hook
+}
+
+// SetGuardianCheck enables the guardian routes; check reports whether
+// guardianID is the consenting guardian of a minor owned by userID
+func (m *Manager) SetGuardianCheck(check func(ctx context.Context, guardianID, userID string) (bool, error)) {
+	m.isGuardian = check
+}
+
+// RequestExport queues an export of everything stored about a user
+func (m *Manager) RequestExport(ctx context.Context, userID, requestedBy string) (*Request, error) {
+	req, err := m.create(ctx, RequestExport, userID, requestedBy, StatusPending, 0)
+	if err != nil {
+		return nil, err
+	}
+	m.record(ctx, audit.EventDataExport, req, "requested", nil)
+	m.notify()
+	return req, nil
+}
+
+// RequestDeletion schedules the deletion of a user's data after the grace period
+func (m *Manager) RequestDeletion(ctx context.Context, userID, requestedBy string) (*Request, error) {
+	req, err := m.create(ctx, RequestDeletion, userID, requestedBy, StatusScheduled, m.config.DeletionGracePeriod)
+	if err != nil {
+		return nil, err
+	}
+	m.record(ctx, audit.EventDataDeletion, req, "scheduled", map[string]string{
+		"scheduled_for": req.ScheduledFor.Format(time.RFC3339),
+	})
+	m.notify()
+	return req, nil
+}
+
+// CancelDeletion cancels a deletion that is still in its grace period
+func (m *Manager) CancelDeletion(ctx context.Context, userID, id, canceledBy string) (*Request, error) {
+	// A worker holding the lease may already be erasing
+	claimed, err := m.store.Claim(ctx, id, m.config.LeaseDuration)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrRequestNotPending
+	}
+	defer m.store.Release(ctx, id)
+
+	req, err := m.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Type != RequestDeletion || req.Status != StatusScheduled {
+		return nil, ErrRequestNotPending
+	}
+
+	req.Status = StatusCanceled
+	completed := m.now().UTC()
+	req.CompletedAt = &completed
+	if err := m.store.Save(ctx, req); err != nil {
+		return nil, err
+	}
+	m.record(ctx, audit.EventDataDeletion, req, "canceled", map[string]string{"canceled_by": canceledBy})
+	return req, nil
+}
+
+// Get returns a request of a user
+func (m *Manager) Get(ctx context.Context, userID, id string) (*Request, error) {
+	req, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != userID {
+		return nil, ErrRequestNotFound
+	}
+	return req, nil
+}
+
+// List returns the requests of a user, newest first
+func (m *Manager) List(ctx context.Context, userID string) ([]*Request, error) {
+	return m.store.ListByUser(ctx, userID)
+}
+
+// OpenExport opens the archive of a completed export
+func (m *Manager) OpenExport(ctx context.Context, userID, id string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	req, err := m.Get(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Type != RequestExport || req.Status != StatusCompleted {
+		return nil, nil, ErrExportNotReady
+	}
+	if req.Artifact == "" || (req.ExpiresAt != nil && m.now().After(*req.ExpiresAt)) {
+		return nil, nil, ErrExportExpired
+	}
+
+	body, info, err := m.objects.Get(ctx, m.config.ExportBucket, req.Artifact)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, nil, ErrExportExpired
+	}
+	return body, info, err
+}
+
+func (m *Manager) create(ctx context.Context, typ RequestType, userID, requestedBy string, status RequestStatus, delay time.Duration) (*Request, error) {
+	existing, err := m.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, req := range existing {
+		if req.Type == typ && req.active() {
+			return nil, ErrRequestInProgress
+		}
+	}
+
+	id, err := newRequestID()
+	if err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	req := &Request{
+		ID:           id,
+		Type:         typ,
+		UserID:       userID,
+		RequestedBy:  requestedBy,
+		Status:       status,
+		CreatedAt:    now,
+		ScheduledFor: now.Add(delay),
+	}
+	if err := m.store.Save(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Run processes due requests until ctx is canceled
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		m.ProcessDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// ProcessDue runs every request that is due now
+func (m *Manager) ProcessDue(ctx context.Context) {
+	due, err := m.store.Due(ctx, m.now())
+	if err != nil {
+		m.logger.Error("Failed to list due data requests", zap.Error(err))
+		return
+	}
+
+	for _, req := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		m.process(ctx, req.ID)
+	}
+}
+
+func (m *Manager) notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// process runs a single request under a lease
+func (m *Manager) process(ctx context.Context, id string) {
+	claimed, err := m.store.Claim(ctx, id, m.config.LeaseDuration)
+	if err != nil || !claimed {
+		return
+	}
+	defer m.store.Release(ctx, id)
+
+	// Reload under the lease, the request may have been canceled meanwhile
+	req, err := m.store.Get(ctx, id)
+	if err != nil {
+		m.logger.Error("Failed to load data request", zap.String("request_id", id), zap.Error(err))
+		return
+	}
+
+	if !req.active() {
+		if req.Artifact != "" {
+			m.expireExport(ctx, req)
+		}
+		return
+	}
+
+	started := m.now().UTC()
+	req.Status = StatusRunning
+	req.StartedAt = &started
+	req.Attempts++
+	if err := m.store.Save(ctx, req); err != nil {
+		m.logger.Error("Failed to start data request", zap.String("request_id", id), zap.Error(err))
+		return
+	}
+
+	switch req.Type {
+	case RequestExport:
+		err = m.runExport(ctx, req)
+	case RequestDeletion:
+		err = m.runDeletion(ctx, req)
+	default:
+		err = fmt.Errorf("unknown request type %q", req.Type)
+	}
+
+	if err != nil {
+		m.logger.Error("Data request failed",
+			zap.String("request_id", req.ID),
+			zap.String("type", string(req.Type)),
+			zap.Int("attempt", req.Attempts),
+			zap.Error(err),
+		)
+		req.Error = err.Error()
+		if req.Attempts >= m.config.MaxAttempts {
+			req.Status = StatusFailed
+		} else {
+			// Retry with exponential backoff
+			req.Status = StatusPending
+			req.ScheduledFor = m.now().UTC().Add(time.Duration(1<<uint(req.Attempts)) * time.Minute)
+		}
+	}
+
+	if err := m.store.Save(ctx, req); err != nil {
+		m.logger.Error("Failed to save data request", zap.String("request_id", id), zap.Error(err))
+	}
+}
+
+// runExport writes every source into a ZIP archive and uploads it
+func (m *Manager) runExport(ctx context.Context, req *Request) error {
+	file, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	archive := &Archive{zip: zip.NewWriter(file)}
+	for _, source := range m.sources {
+		if err := source.Export(ctx, req.UserID, archive); err != nil {
+			return fmt.Errorf("failed to export %s: %w", source.Name(), err)
+		}
+	}
+
+	manifest := map[string]interface{}{
+		"request_id":   req.ID,
+		"user_id":      req.UserID,
+		"generated_at": m.now().UTC(),
+		"files":        append([]string{}, archive.files...),
+	}
+	if err := archive.WriteJSON("manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := archive.zip.Close(); err != nil {
+		return fmt.Errorf("failed to finish export archive: %w", err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to size export archive: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read export archive: %w", err)
+	}
+
+	key := exportKey(req.UserID, req.ID)
+	if err := m.objects.Put(ctx, m.config.ExportBucket, key, file, size, "application/zip"); err != nil {
+		return err
+	}
+
+	completed := m.now().UTC()
+	expires := completed.Add(m.config.ExportRetention)
+	req.Status = StatusCompleted
+	req.CompletedAt = &completed
+	req.ExpiresAt = &expires
+	req.Artifact = key
+	req.Error = ""
+	m.record(ctx, audit.EventDataExport, req, "completed", map[string]string{"size_bytes": strconv.FormatInt(size, 10)})
+	return nil
+}
+
+// expireExport removes an export archive after its retention period
+func (m *Manager) expireExport(ctx context.Context, req *Request) {
+	if err := m.objects.Delete(ctx, m.config.ExportBucket, req.Artifact); err != nil {
+		m.logger.Error("Failed to remove expired export", zap.String("request_id", req.ID), zap.Error(err))
+		return
+	}
+	req.Artifact = ""
+	if err := m.store.Save(ctx, req); err != nil {
+		m.logger.Error("Failed to save data request", zap.String("request_id", req.ID), zap.Error(err))
+	}
+}
+
+// runDeletion erases the user from every source and issues a receipt
+func (m *Manager) runDeletion(ctx context.Context, req *Request) error {
+	results := make([]SystemResult, 0, len(m.sources)+1)
+	for _, source := range m.sources {
+		result, err := source.Erase(ctx, req.UserID)
+		if err != nil {
+			// The next attempt erases the rest; the counts show how far this one got
+			m.logger.Warn("Erasure interrupted",
+				zap.String("request_id", req.ID),
+				zap.String("system", source.Name()),
+				zap.Int64("deleted", result.Deleted),
+				zap.Int64("anonymized", result.Anonymized),
+			)
+			return fmt.Errorf("failed to erase %s: %w", source.Name(), err)
+		}
+		result.System = source.Name()
+		results = append(results, result)
+	}
+
+	// Earlier exports contain the same personal data
+	exports, err := m.objects.List(ctx, m.config.ExportBucket, exportPrefix(req.UserID))
+	if err != nil {
+		return err
+	}
+	for _, object := range exports {
+		if err := m.objects.Delete(ctx, m.config.ExportBucket, object.Key); err != nil {
+			return err
+		}
+	}
+	results = append(results, SystemResult{System: "exports", Deleted: int64(len(exports))})
+
+	if m.onErased != nil {
+		if err := m.onErased(ctx, req.UserID); err != nil {
+			return fmt.Errorf("failed to finish erasure: %w", err)
+		}
+	}
+
+	completed := m.now().UTC()
+	receipt := &Receipt{
+		RequestID:   req.ID,
+		UserID:      req.UserID,
+		RequestedAt: req.CreatedAt,
+		CompletedAt: completed,
+		Systems:     results,
+	}
+	receipt.Digest = receipt.computeDigest()
+
+	req.Status = StatusCompleted
+	req.CompletedAt = &completed
+	req.Receipt = receipt
+	req.Error = ""
+	m.record(ctx, audit.EventDataDeletion, req, "completed", map[string]string{"receipt_digest": receipt.Digest})
+
+	m.logger.Info("User data erased",
+		zap.String("request_id", req.ID),
+		zap.String("user_id", req.UserID),
+		zap.String("receipt_digest", receipt.Digest),
+	)
+	return nil
+}
+
+// record writes a data request event to the audit trail
+func (m *Manager) record(ctx context.Context, eventType audit.EventType, req *Request, stage string, metadata map[string]string) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata["request_id"] = req.ID
+	metadata["stage"] = stage
+
+	_, err := m.audit.Record(ctx, audit.Event{
+		Type:     eventType,
+		ActorID:  req.RequestedBy,
+		UserID:   req.UserID,
+		Metadata: metadata,
+	})
+	if err != nil {
+		m.logger.Error("Failed to record audit event", zap.String("request_id", req.ID), zap.Error(err))
+	}
+}
+
+func exportPrefix(userID string) string {
+	return "exports/" + userID + "/"
+}
+
+func exportKey(userID, requestID string) string {
+	return exportPrefix(userID) + requestID + ".zip"
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate request id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
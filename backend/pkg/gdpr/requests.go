@@ -0,0 +1,297 @@
+package gdpr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RequestType is the kind of data subject request
+type RequestType string
+
+const (
+	RequestExport   RequestType = "export"
+	RequestDeletion RequestType = "deletion"
+)
+
+// RequestStatus is the state of a data subject request
+type RequestStatus string
+
+const (
+	StatusPending   RequestStatus = "pending"   // export waiting for a worker
+	StatusScheduled RequestStatus = "scheduled" // deletion waiting for its grace period to end
+	StatusRunning   RequestStatus = "running"
+	StatusCompleted RequestStatus = "completed"
+	StatusFailed    RequestStatus = "failed"
+	StatusCanceled  RequestStatus = "canceled"
+)
+
+const (
+	requestRedisPrefix     = "data_request:"
+	requestUserIndexPrefix = "data_requests:"
+	requestDueKey          = "data_requests_due"
+	requestLockPrefix      = "data_request_lock:"
+)
+
+var (
+	ErrRequestNotFound   = errors.New("data request not found")
+	ErrRequestInProgress = errors.New("a request of this type is already in progress")
+	ErrRequestNotPending = errors.New("data request can no longer be canceled")
+	ErrExportNotReady    = errors.New("export is not ready")
+	ErrExportExpired     = errors.New("export has expired")
+)
+
+// Request is an export or deletion of everything stored about a user
+type Request struct {
+	ID           string        `json:"id"`
+	Type         RequestType   `json:"type"`
+	UserID       string        `json:"user_id"`
+	RequestedBy  string        `json:"requested_by"`
+	Status       RequestStatus `json:"status"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	StartedAt    *time.Time    `json:"started_at,omitempty"`
+	CompletedAt  *time.Time    `json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time    `json:"expires_at,omitempty"` // when the export archive is removed
+	Artifact     string        `json:"artifact,omitempty"`   // object key of the export archive
+	Attempts     int           `json:"attempts"`
+	Error        string        `json:"error,omitempty"`
+	Receipt      *Receipt      `json:"receipt,omitempty"`
+}
+
+// active reports whether the request has not reached a final state
+func (r *Request) active() bool {
+	return r.Status == StatusPending || r.Status == StatusScheduled || r.Status == StatusRunning
+}
+
+// dueAt returns when a worker next has to look at the request: when it is
+// scheduled to run, or when its export archive expires and must be removed
+func (r *Request) dueAt() (time.Time, bool) {
+	if r.active() {
+		return r.ScheduledFor, true
+	}
+	if r.Artifact != "" && r.ExpiresAt != nil {
+		return *r.ExpiresAt, true
+	}
+	return time.Time{}, false
+}
+
+// RequestStore persists data subject requests
+type RequestStore interface {
+	Save(ctx context.Context, req *Request) error
+	Get(ctx context.Context, id string) (*Request, error)
+	ListByUser(ctx context.Context, userID string) ([]*Request, error)
+	// Due returns requests whose next run is at or before now
+	Due(ctx context.Context, now time.Time) ([]*Request, error)
+	// Claim takes a lease on a request so only one worker processes it
+	Claim(ctx context.Context, id string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, id string) error
+}
+
+// NewRequestStore returns a Redis-backed store, or an in-memory one when client is nil
+func NewRequestStore(client *redis.Client) RequestStore {
+	if client == nil {
+		return NewMemoryRequestStore()
+	}
+	return &RedisRequestStore{client: client}
+}
+
+// MemoryRequestStore keeps requests in process memory
+type MemoryRequestStore struct {
+	mu       sync.Mutex
+	requests map[string]Request
+	leases   map[string]time.Time
+}
+
+// NewMemoryRequestStore creates an in-memory request store
+func NewMemoryRequestStore() *MemoryRequestStore {
+	return &MemoryRequestStore{
+		requests: make(map[string]Request),
+		leases:   make(map[string]time.Time),
+	}
+}
+
+// Save creates or replaces a request
+func (s *MemoryRequestStore) Save(ctx context.Context, req *Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[req.ID] = *req
+	return nil
+}
+
+// Get loads a request by ID
+func (s *MemoryRequestStore) Get(ctx context.Context, id string) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, exists := s.requests[id]
+	if !exists {
+		return nil, ErrRequestNotFound
+	}
+	return &req, nil
+}
+
+// ListByUser returns the requests of a user, newest first
+func (s *MemoryRequestStore) ListByUser(ctx context.Context, userID string) ([]*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]*Request, 0)
+	for _, req := range s.requests {
+		if req.UserID == userID {
+			req := req
+			requests = append(requests, &req)
+		}
+	}
+	sortNewestFirst(requests)
+	return requests, nil
+}
+
+// Due returns requests whose next run is at or before now, oldest first
+func (s *MemoryRequestStore) Due(ctx context.Context, now time.Time) ([]*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([]*Request, 0)
+	for _, req := range s.requests {
+		if dueAt, ok := req.dueAt(); ok && !dueAt.After(now) {
+			req := req
+			requests = append(requests, &req)
+		}
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, _ := requests[i].dueAt()
+		b, _ := requests[j].dueAt()
+		return a.Before(b)
+	})
+	return requests, nil
+}
+
+// Claim takes a lease on a request
+func (s *MemoryRequestStore) Claim(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until, leased := s.leases[id]; leased && time.Now().Before(until) {
+		return false, nil
+	}
+	s.leases[id] = time.Now().Add(ttl)
+	return true, nil
+}
+
+// Release gives up a lease
+func (s *MemoryRequestStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.leases, id)
+	return nil
+}
+
+// RedisRequestStore keeps requests in Redis so any instance can process them
+type RedisRequestStore struct {
+	client *redis.Client
+}
+
+// Save creates or replaces a request and keeps the due index in sync
+func (s *RedisRequestStore) Save(ctx context.Context, req *Request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode data request: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, requestRedisPrefix+req.ID, data, 0)
+	pipe.SAdd(ctx, requestUserIndexPrefix+req.UserID, req.ID)
+	if dueAt, ok := req.dueAt(); ok {
+		pipe.ZAdd(ctx, requestDueKey, &redis.Z{Score: float64(dueAt.Unix()), Member: req.ID})
+	} else {
+		pipe.ZRem(ctx, requestDueKey, req.ID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store data request: %w", err)
+	}
+	return nil
+}
+
+// Get loads a request by ID
+func (s *RedisRequestStore) Get(ctx context.Context, id string) (*Request, error) {
+	data, err := s.client.Get(ctx, requestRedisPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, ErrRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data request: %w", err)
+	}
+
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode data request: %w", err)
+	}
+	return &req, nil
+}
+
+// ListByUser returns the requests of a user, newest first
+func (s *RedisRequestStore) ListByUser(ctx context.Context, userID string) ([]*Request, error) {
+	ids, err := s.client.SMembers(ctx, requestUserIndexPrefix+userID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data requests: %w", err)
+	}
+	requests, err := s.getAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	sortNewestFirst(requests)
+	return requests, nil
+}
+
+// Due returns requests whose next run is at or before now, oldest first
+func (s *RedisRequestStore) Due(ctx context.Context, now time.Time) ([]*Request, error) {
+	ids, err := s.client.ZRangeByScore(ctx, requestDueKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due data requests: %w", err)
+	}
+	return s.getAll(ctx, ids)
+}
+
+// Claim takes a lease on a request
+func (s *RedisRequestStore) Claim(ctx context.Context, id string, ttl time.Duration) (bool, error) {
+	claimed, err := s.client.SetNX(ctx, requestLockPrefix+id, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim data request: %w", err)
+	}
+	return claimed, nil
+}
+
+// Release gives up a lease
+func (s *RedisRequestStore) Release(ctx context.Context, id string) error {
+	return s.client.Del(ctx, requestLockPrefix+id).Err()
+}
+
+func (s *RedisRequestStore) getAll(ctx context.Context, ids []string) ([]*Request, error) {
+	requests := make([]*Request, 0, len(ids))
+	for _, id := range ids {
+		req, err := s.Get(ctx, id)
+		if errors.Is(err, ErrRequestNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+func sortNewestFirst(requests []*Request) {
+	sort.Slice(requests, func(i, j int) bool { return requests[i].CreatedAt.After(requests[j].CreatedAt) })
+}
@@ -0,0 +1,136 @@
+package gdpr
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/metrics"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/storage"
+)
+
+// MetricsSource covers athlete_profiles, consent_history, jump_sessions and jump_metrics.
+// Leaderboards are computed from jump_metrics, so anonymizing the metrics
+// also removes the user's leaderboard entries.
+type MetricsSource struct {
+	store *metrics.Store
+}
+
+// NewMetricsSource creates the source for the metrics store
+func NewMetricsSource(store *metrics.Store) *MetricsSource {
+	return &MetricsSource{store: store}
+}
+
+// Name identifies the source in receipts
+func (s *MetricsSource) Name() string {
+	return "metrics"
+}
+
+// Export writes profiles, sessions, metrics and consents as JSON, and metrics as CSV
+func (s *MetricsSource) Export(ctx context.Context, userID string, archive *Archive) error {
+	data, err := s.store.ExportUserData(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := archive.WriteJSON("profiles.json", data.Profiles); err != nil {
+		return err
+	}
+	if err := archive.WriteJSON("sessions.json", data.Sessions); err != nil {
+		return err
+	}
+	if err := archive.WriteJSON("metrics.json", data.Metrics); err != nil {
+		return err
+	}
+	if err := archive.WriteJSON("consents.json", data.Consents); err != nil {
+		return err
+	}
+	return archive.WriteCSV("metrics.csv", metricsCSVHeader, metricsCSVRows(data.Metrics))
+}
+
+// Erase deletes profiles and consent history and anonymizes sessions and
+// metrics, and the user as guardian of other athletes. On failure the result
+// counts what was erased before the error.
+func (s *MetricsSource) Erase(ctx context.Context, userID string) (SystemResult, error) {
+	result, err := s.store.EraseUserData(ctx, userID)
+	if result == nil {
+		return SystemResult{}, err
+	}
+	return SystemResult{
+		Deleted:    result.ProfilesDeleted + result.ConsentsDeleted,
+		Anonymized: result.SessionsAnonymized + result.MetricsAnonymized + result.GuardiansAnonymized + result.ConsentsAnonymized,
+	}, err
+}
+
+var metricsCSVHeader = []string{
+	"id", "athlete_id", "session_id", "timestamp",
+	"height_cm", "contact_time_ms", "flight_time_ms", "takeoff_velocity_mps", "landing_force_n",
+	"valgus_angle_deg", "knee_flexion_deg", "hip_flexion_deg",
+	"takeoff_score", "landing_score", "overall_score", "symmetry_score",
+	"device_type", "app_version", "confidence",
+	"latitude", "longitude", "notes",
+}
+
+func metricsCSVRows(metrics []metrics.JumpMetric) [][]string {
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+
+	rows := make([][]string, 0, len(metrics))
+	for _, m := range metrics {
+		latitude, longitude := "", ""
+		if m.Location != nil {
+			latitude, longitude = float(m.Location.Latitude), float(m.Location.Longitude)
+		}
+		rows = append(rows, []string{
+			m.ID, m.AthleteID, m.SessionID, m.Timestamp.UTC().Format(time.RFC3339),
+			float(m.HeightCm), strconv.Itoa(m.ContactTimeMs), strconv.Itoa(m.FlightTimeMs), float(m.TakeoffVelocity), float(m.LandingForce),
+			float(m.ValgusAngleDeg), float(m.KneeFlexionDeg), float(m.HipFlexionDeg),
+			strconv.Itoa(m.TakeoffScore), strconv.Itoa(m.LandingScore), strconv.Itoa(m.OverallScore), strconv.Itoa(m.SymmetryScore),
+			m.DeviceType, m.AppVersion, float(m.Confidence),
+			latitude, longitude, m.Notes,
+		})
+	}
+	return rows
+}
+
+// VideoSource covers uploaded videos, which are stored under "<user id>/" in the video bucket
+type VideoSource struct {
+	objects storage.ObjectStore
+	bucket  string
+}
+
+// NewVideoSource creates the source for uploaded videos
+func NewVideoSource(objects storage.ObjectStore, bucket string) *VideoSource {
+	return &VideoSource{objects: objects, bucket: bucket}
+}
+
+// Name identifies the source in receipts
+func (s *VideoSource) Name() string {
+	return "videos"
+}
+
+// Export lists the user's videos. The files themselves are not copied into
+// the archive; they can be downloaded through the app until deletion.
+func (s *VideoSource) Export(ctx context.Context, userID string, archive *Archive) error {
+	videos, err := s.objects.List(ctx, s.bucket, userID+"/")
+	if err != nil {
+		return err
+	}
+	return archive.WriteJSON("videos.json", videos)
+}
+
+// Erase deletes the user's videos. On failure the result counts the videos
+// deleted before the error.
+func (s *VideoSource) Erase(ctx context.Context, userID string) (SystemResult, error) {
+	videos, err := s.objects.List(ctx, s.bucket, userID+"/")
+	if err != nil {
+		return SystemResult{}, err
+	}
+	var result SystemResult
+	for _, video := range videos {
+		if err := s.objects.Delete(ctx, s.bucket, video.Key); err != nil {
+			return result, err
+		}
+		result.Deleted++
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -334,6 +335,20 @@ func NewHTTPChecker(url, name string) *HTTPChecker {
 }
 
 // Name returns the checker name
+// NewHTTPSChecker creates an HTTP checker that connects with the given TLS
+// configuration, e.g. the service's mTLS client identity
+func NewHTTPSChecker(url, name string, tlsConfig *tls.Config) *HTTPChecker {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &HTTPChecker{
+		url:  url,
+		name: name,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+		},
+	}
+}
 func (hc *HTTPChecker) Name() string {
 	return hc.name
 }
@@ -3,7 +3,6 @@ package logging
 import (
 	"context"
 	"fmt"
-	"os"
 	"runtime"
 	"strings"
 	"time"
@@ -92,6 +91,23 @@ func NewLogger(level LogLevel, environment string) (*Logger, error) {
 	}, nil
 }
 
+// NewFromZap wraps an existing zap logger, e.g. one handed to a package
+// that only accepts *zap.Logger
+func NewFromZap(zapLogger *zap.Logger) *Logger {
+	level := zapcore.FatalLevel
+	for _, l := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel} {
+		if zapLogger.Core().Enabled(l) {
+			level = l
+			break
+		}
+	}
+
+	return &Logger{
+		Logger: zapLogger,
+		level:  level,
+	}
+}
+
 // WithContext returns a logger with context values
 func (l *Logger) WithContext(ctx context.Context) *Logger {
 	fields := []zap.Field{}
@@ -0,0 +1,319 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UserData is everything the metrics store holds about a user
+type UserData struct {
+	Profiles []AthleteProfile `json:"profiles"`
+	Sessions []JumpSession    `json:"sessions"`
+	Metrics  []JumpMetric     `json:"metrics"`
+	Consents []ConsentRecord  `json:"consents"`
+}
+
+// ErasureResult counts the documents removed or anonymized for a user
+type ErasureResult struct {
+	ProfilesDeleted     int64 `json:"profiles_deleted"`
+	SessionsAnonymized  int64 `json:"sessions_anonymized"`
+	MetricsAnonymized   int64 `json:"metrics_anonymized"`
+	ConsentsDeleted     int64 `json:"consents_deleted"`
+	GuardiansAnonymized int64 `json:"guardians_anonymized"` // minors' profiles the user was guardian of
+	ConsentsAnonymized  int64 `json:"consents_anonymized"`  // consent records of other athletes naming the user
+}
+
+// personalMetricFields are removed from jumps when they are anonymized
+var personalMetricFields = []string{"location", "location_enc", "notes", "device_id"}
+
+// athleteIDs returns the athlete IDs owned by a user. Athletes without a
+// profile submit metrics under their user ID, so it is always included.
+func (s *Store) athleteIDs(ctx context.Context, userID string) ([]string, error) {
+	cursor, err := s.database.Collection(AthleteProfilesCollection).Find(ctx,
+		bson.M{"user_id": userID},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find athlete profiles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	ids := []string{userID}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode athlete profile: %w", err)
+		}
+		if doc.ID != userID {
+			ids = append(ids, doc.ID)
+		}
+	}
+	return ids, cursor.Err()
+}
+
+// guardianAthleteIDs returns the athletes a user consented for as guardian,
+// except the user's own
+func (s *Store) guardianAthleteIDs(ctx context.Context, userID string, own []string) ([]string, error) {
+	cursor, err := s.database.Collection(AthleteProfilesCollection).Find(ctx,
+		bson.M{"privacy.guardian.user_id": userID, "_id": bson.M{"$nin": own}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find guardian athletes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode athlete profile: %w", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
+
+// ExportUserData returns the profiles, sessions and metrics of a user with
+// encrypted fields decrypted
+func (s *Store) ExportUserData(ctx context.Context, userID string) (*UserData, error) {
+	ids, err := s.athleteIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &UserData{
+		Profiles: make([]AthleteProfile, 0),
+		Sessions: make([]JumpSession, 0),
+		Metrics:  make([]JumpMetric, 0),
+		Consents: make([]ConsentRecord, 0),
+	}
+
+	// Profiles
+	cursor, err := s.database.Collection(AthleteProfilesCollection).Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find athlete profiles: %w", err)
+	}
+	var profiles []storedAthleteProfile
+	if err := cursor.All(ctx, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to decode athlete profiles: %w", err)
+	}
+	for _, doc := range profiles {
+		profile, err := s.decodeProfile(doc)
+		if err != nil {
+			return nil, err
+		}
+		data.Profiles = append(data.Profiles, profile)
+	}
+
+	byAthlete := bson.M{"athlete_id": bson.M{"$in": ids}}
+	sorted := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
+
+	// Consent history
+	cursor, err = s.database.Collection(ConsentHistoryCollection).Find(ctx, byAthlete,
+		options.Find().SetSort(bson.D{{Key: "changed_at", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consent history: %w", err)
+	}
+	if err := cursor.All(ctx, &data.Consents); err != nil {
+		return nil, fmt.Errorf("failed to decode consent history: %w", err)
+	}
+
+	// Metrics
+	cursor, err = s.database.Collection(MetricsCollection).Find(ctx, byAthlete, sorted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find metrics: %w", err)
+	}
+	var metrics []storedJumpMetric
+	if err := cursor.All(ctx, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to decode metrics: %w", err)
+	}
+	for _, doc := range metrics {
+		metric, err := s.decodeMetric(doc)
+		if err != nil {
+			return nil, err
+		}
+		data.Metrics = append(data.Metrics, metric)
+	}
+
+	// Sessions embed copies of their jumps
+	cursor, err = s.database.Collection(SessionsCollection).Find(ctx, byAthlete,
+		options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		session, err := s.decodeSession(cursor.Current)
+		if err != nil {
+			return nil, err
+		}
+		data.Sessions = append(data.Sessions, session)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	return data, nil
+}
+
+// decodeSession decodes a session document and decrypts its embedded jumps
+func (s *Store) decodeSession(raw bson.Raw) (JumpSession, error) {
+	var session JumpSession
+	if err := bson.Unmarshal(raw, &session); err != nil {
+		return JumpSession{}, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	var doc struct {
+		Jumps []storedJumpMetric `bson:"jumps"`
+	}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return JumpSession{}, fmt.Errorf("failed to decode session %s: %w", session.ID, err)
+	}
+
+	session.Jumps = make([]JumpMetric, len(doc.Jumps))
+	for i, jump := range doc.Jumps {
+		metric, err := s.decodeMetric(jump)
+		if err != nil {
+			return JumpSession{}, err
+		}
+		session.Jumps[i] = metric
+	}
+	return session, nil
+}
+
+// EraseUserData deletes the profiles of a user and anonymizes their sessions
+// and metrics. Anonymized jumps keep their measurements for aggregate
+// statistics, but are moved to a random athlete ID and lose location, notes
+// and device. They no longer count towards leaderboards or training exports,
+// which only include athletes who opted in. The consent history is deleted
+// with the profiles.
+//
+// A guardian's consent on behalf of a minor stays valid after the guardian
+// is erased, so minors keep their guardian consent and consent history, but
+// the guardian's user ID in them is replaced by the pseudonym.
+//
+// On failure the result counts what was erased before the error.
+func (s *Store) EraseUserData(ctx context.Context, userID string) (*ErasureResult, error) {
+	ids, err := s.athleteIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pseudonym, err := anonymousAthleteID()
+	if err != nil {
+		return nil, err
+	}
+	result := &ErasureResult{}
+	byAthlete := bson.M{"athlete_id": bson.M{"$in": ids}}
+
+	unsetMetric := bson.M{}
+	unsetJumps := bson.M{}
+	for _, field := range personalMetricFields {
+		unsetMetric[field] = ""
+		unsetJumps["jumps.$[]."+field] = ""
+	}
+
+	// Sessions first, so an interrupted erasure can be repeated: the remaining
+	// metrics still point at the user
+	sessions := s.database.Collection(SessionsCollection)
+	_, err = sessions.UpdateMany(ctx, bson.M{"athlete_id": bson.M{"$in": ids}, "jumps.0": bson.M{"$exists": true}}, bson.M{
+		"$set": bson.M{
+			"jumps.$[].athlete_id": pseudonym,
+			"jumps.$[].anonymized": true,
+		},
+		"$unset": unsetJumps,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to anonymize session jumps: %w", err)
+	}
+	updated, err := sessions.UpdateMany(ctx, byAthlete, bson.M{
+		"$set": bson.M{"athlete_id": pseudonym, "anonymized": true},
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to anonymize sessions: %w", err)
+	}
+	result.SessionsAnonymized = updated.ModifiedCount
+
+	updated, err = s.database.Collection(MetricsCollection).UpdateMany(ctx, byAthlete, bson.M{
+		"$set":   bson.M{"athlete_id": pseudonym, "anonymized": true},
+		"$unset": unsetMetric,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to anonymize metrics: %w", err)
+	}
+	result.MetricsAnonymized = updated.ModifiedCount
+
+	// Minors the user consented for as their guardian
+	minors, err := s.guardianAthleteIDs(ctx, userID, ids)
+	if err != nil {
+		return result, err
+	}
+	if len(minors) > 0 {
+		updated, err = s.database.Collection(AthleteProfilesCollection).UpdateMany(ctx,
+			bson.M{"_id": bson.M{"$in": minors}, "privacy.guardian.user_id": userID},
+			bson.M{"$set": bson.M{"privacy.guardian.user_id": pseudonym}})
+		if err != nil {
+			return result, fmt.Errorf("failed to anonymize guardian consents: %w", err)
+		}
+		result.GuardiansAnonymized = updated.ModifiedCount
+		s.changed(ctx, minors...)
+	}
+
+	// Consent records of other athletes name the user as the one who made the
+	// change or as the guardian. A record can do both, so they are counted up front.
+	history := s.database.Collection(ConsentHistoryCollection)
+	ofOthers := bson.M{"$nin": ids}
+	result.ConsentsAnonymized, err = history.CountDocuments(ctx, bson.M{
+		"athlete_id": ofOthers,
+		"$or":        []bson.M{{"changed_by": userID}, {"settings.guardian.user_id": userID}},
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to count consent history: %w", err)
+	}
+	if result.ConsentsAnonymized > 0 {
+		if _, err := history.UpdateMany(ctx, bson.M{"athlete_id": ofOthers, "changed_by": userID},
+			bson.M{"$set": bson.M{"changed_by": pseudonym}}); err != nil {
+			return result, fmt.Errorf("failed to anonymize consent history: %w", err)
+		}
+		if _, err := history.UpdateMany(ctx, bson.M{"athlete_id": ofOthers, "settings.guardian.user_id": userID},
+			bson.M{"$set": bson.M{"settings.guardian.user_id": pseudonym}}); err != nil {
+			return result, fmt.Errorf("failed to anonymize consent history: %w", err)
+		}
+	}
+
+	// Profiles last: the athletes are found through them, so an erasure that
+	// fails before this point can still be repeated and finds the consent history
+	deleted, err := history.DeleteMany(ctx, byAthlete)
+	if err != nil {
+		return result, fmt.Errorf("failed to delete consent history: %w", err)
+	}
+	result.ConsentsDeleted = deleted.DeletedCount
+
+	deleted, err = s.database.Collection(AthleteProfilesCollection).DeleteMany(ctx, bson.M{"$or": []bson.M{
+		{"_id": bson.M{"$in": ids}},
+		{"user_id": userID},
+	}})
+	if err != nil {
+		return result, fmt.Errorf("failed to delete athlete profiles: %w", err)
+	}
+	result.ProfilesDeleted = deleted.DeletedCount
+
+	s.changed(ctx, ids...)
+	return result, nil
+}
+
+// anonymousAthleteID returns a random ID that cannot be traced back to the user
+func anonymousAthleteID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate anonymous id: %w", err)
+	}
+	return "anonymized-" + hex.EncodeToString(b), nil
+}
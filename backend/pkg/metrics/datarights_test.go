@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// erasedCollections returns the collections documents were deleted from, in order
+func erasedCollections(mt *mtest.T) []string {
+	var collections []string
+	for _, event := range mt.GetAllStartedEvents() {
+		if event.CommandName == "delete" {
+			collections = append(collections, event.Command.Lookup("delete").StringValue())
+		}
+	}
+	return collections
+}
+
+func TestStore_EraseUserData(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+	profiles := findResponse(AthleteProfilesCollection, bson.D{{Key: "_id", Value: "athlete-1"}})
+	noMinors := findResponse(AthleteProfilesCollection)
+	consentCount := func(n int) bson.D {
+		return findResponse(ConsentHistoryCollection, bson.D{{Key: "n", Value: n}})
+	}
+
+	mt.Run("consent history is deleted before the profiles", func(mt *mtest.T) {
+		store := newTestStore(mt)
+		mt.AddMockResponses(profiles, updated, updated, updated, noMinors, consentCount(0),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		result, err := store.EraseUserData(context.Background(), "user-1")
+		require.NoError(mt, err)
+		assert.Equal(mt, int64(3), result.ConsentsDeleted)
+		assert.Equal(mt, int64(1), result.ProfilesDeleted)
+		assert.Equal(mt, []string{ConsentHistoryCollection, AthleteProfilesCollection}, erasedCollections(mt))
+	})
+
+	mt.Run("profiles stay when the consent history cannot be deleted", func(mt *mtest.T) {
+		store := newTestStore(mt)
+		mt.AddMockResponses(profiles, updated, updated, updated, noMinors, consentCount(0),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad value"}),
+		)
+
+		result, err := store.EraseUserData(context.Background(), "user-1")
+		assert.ErrorContains(mt, err, "failed to delete consent history")
+		assert.Equal(mt, []string{ConsentHistoryCollection}, erasedCollections(mt))
+		// What was anonymized before the failure is still reported
+		require.NotNil(mt, result)
+		assert.Equal(mt, int64(1), result.SessionsAnonymized)
+		assert.Equal(mt, int64(1), result.MetricsAnonymized)
+		assert.Zero(mt, result.ProfilesDeleted)
+	})
+
+	mt.Run("the user is replaced as guardian of other athletes", func(mt *mtest.T) {
+		store := newTestStore(mt)
+		mt.AddMockResponses(profiles, updated, updated, updated,
+			findResponse(AthleteProfilesCollection, bson.D{{Key: "_id", Value: "minor-1"}}),
+			updated, consentCount(2), updated, updated,
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+
+		result, err := store.EraseUserData(context.Background(), "user-1")
+		require.NoError(mt, err)
+		assert.Equal(mt, int64(1), result.GuardiansAnonymized)
+		assert.Equal(mt, int64(2), result.ConsentsAnonymized)
+
+		var pseudonyms []string
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName != "update" {
+				continue
+			}
+			updates, err := event.Command.Lookup("updates").Array().Values()
+			require.NoError(mt, err)
+			set := updates[0].Document().Lookup("u", "$set")
+			for _, field := range []string{"privacy.guardian.user_id", "changed_by", "settings.guardian.user_id"} {
+				if value, err := set.Document().LookupErr(field); err == nil {
+					pseudonyms = append(pseudonyms, value.StringValue())
+				}
+			}
+		}
+		require.Len(mt, pseudonyms, 3)
+		for _, pseudonym := range pseudonyms {
+			assert.True(mt, strings.HasPrefix(pseudonym, "anonymized-"))
+		}
+	})
+}
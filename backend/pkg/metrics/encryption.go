@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/encryption"
+)
+
+// storedJumpMetric is the persisted form of a jump metric with the location encrypted
+type storedJumpMetric struct {
+	JumpMetric  `bson:",inline"`
+	LocationEnc *encryption.Envelope `bson:"location_enc,omitempty"`
+}
+
+// storedAthleteProfile is the persisted form of a profile with personal data encrypted
+type storedAthleteProfile struct {
+	AthleteProfile `bson:",inline"`
+	PersonalEnc    *encryption.Envelope `bson:"personal_enc,omitempty"`
+}
+
+// personalData groups the profile fields that are encrypted together
+type personalData struct {
+	Name   string  `json:"name"`
+	Age    int     `json:"age"`
+	Weight float64 `json:"weight_kg"`
+}
+
+// RotationReport counts the documents re-encrypted by RotateEncryptionKeys
+type RotationReport struct {
+	Metrics  int `json:"metrics"`
+	Sessions int `json:"sessions"`
+	Profiles int `json:"profiles"`
+}
+
+// SetEncryptor enables field-level encryption of sensitive fields.
+// Without an encryptor fields are stored in plaintext.
+func (s *Store) SetEncryptor(encryptor *encryption.FieldEncryptor) {
+	s.encryptor = encryptor
+}
+
+// Associated data binds ciphertext to the athlete it belongs to
+func locationAAD(athleteID string) string { return "athlete/" + athleteID + "/location" }
+func personalAAD(athleteID string) string { return "athlete/" + athleteID + "/personal" }
+
+// encodeMetric encrypts the location of a metric before it is written
+func (s *Store) encodeMetric(metric JumpMetric) (storedJumpMetric, error) {
+	if s.encryptor == nil || metric.Location == nil {
+		return storedJumpMetric{JumpMetric: metric}, nil
+	}
+
+	envelope, err := s.encryptor.Seal(metric.Location, locationAAD(metric.AthleteID))
+	if err != nil {
+		return storedJumpMetric{}, fmt.Errorf("failed to encrypt location: %w", err)
+	}
+	metric.Location = nil
+	return storedJumpMetric{JumpMetric: metric, LocationEnc: envelope}, nil
+}
+
+// decodeMetric decrypts the location of a stored metric
+func (s *Store) decodeMetric(doc storedJumpMetric) (JumpMetric, error) {
+	metric := doc.JumpMetric
+	if doc.LocationEnc == nil {
+		return metric, nil
+	}
+	if s.encryptor == nil {
+		return JumpMetric{}, fmt.Errorf("metric %s has an encrypted location but no encryptor is configured", metric.ID)
+	}
+
+	var location Location
+	if err := s.encryptor.Open(doc.LocationEnc, locationAAD(metric.AthleteID), &location); err != nil {
+		return JumpMetric{}, fmt.Errorf("failed to decrypt location of metric %s: %w", metric.ID, err)
+	}
+	metric.Location = &location
+	return metric, nil
+}
+
+// encodeSession builds the session document with the locations of embedded jumps encrypted
+func (s *Store) encodeSession(session JumpSession) (bson.D, error) {
+	jumps := make([]storedJumpMetric, len(session.Jumps))
+	for i, jump := range session.Jumps {
+		encoded, err := s.encodeMetric(jump)
+		if err != nil {
+			return nil, err
+		}
+		jumps[i] = encoded
+	}
+
+	session.Jumps = nil
+	data, err := bson.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	for i := range doc {
+		if doc[i].Key == "jumps" {
+			doc[i].Value = jumps
+		}
+	}
+	return doc, nil
+}
+
+// encodeProfile encrypts the personal fields of a profile before it is written
+func (s *Store) encodeProfile(profile AthleteProfile) (storedAthleteProfile, error) {
+	if s.encryptor == nil {
+		return storedAthleteProfile{AthleteProfile: profile}, nil
+	}
+
+	personal := personalData{Name: profile.Name, Age: profile.Age, Weight: profile.Weight}
+	envelope, err := s.encryptor.Seal(personal, personalAAD(profile.ID))
+	if err != nil {
+		return storedAthleteProfile{}, fmt.Errorf("failed to encrypt personal data: %w", err)
+	}
+	profile.Name, profile.Age, profile.Weight = "", 0, 0
+	return storedAthleteProfile{AthleteProfile: profile, PersonalEnc: envelope}, nil
+}
+
+// decodeProfile decrypts the personal fields of a stored profile
+func (s *Store) decodeProfile(doc storedAthleteProfile) (AthleteProfile, error) {
+	profile := doc.AthleteProfile
+	if doc.PersonalEnc == nil {
+		return profile, nil
+	}
+	if s.encryptor == nil {
+		return AthleteProfile{}, fmt.Errorf("profile %s has encrypted fields but no encryptor is configured", profile.ID)
+	}
+
+	var personal personalData
+	if err := s.encryptor.Open(doc.PersonalEnc, personalAAD(profile.ID), &personal); err != nil {
+		return AthleteProfile{}, fmt.Errorf("failed to decrypt profile %s: %w", profile.ID, err)
+	}
+	profile.Name, profile.Age, profile.Weight = personal.Name, personal.Age, personal.Weight
+	return profile, nil
+}
+
+// RotateEncryptionKeys re-encrypts every sensitive field that is still in plaintext
+// or wrapped with a retired master key. It is safe to run repeatedly and to
+// interrupt; documents that are already current are skipped.
+func (s *Store) RotateEncryptionKeys(ctx context.Context) (*RotationReport, error) {
+	if s.encryptor == nil {
+		return nil, fmt.Errorf("field encryption is not configured")
+	}
+
+	report := &RotationReport{}
+	stale := bson.M{"$or": []bson.M{
+		{"location": bson.M{"$ne": nil}},
+		{"location_enc.kid": bson.M{"$exists": true, "$ne": s.encryptor.ActiveKeyID()}},
+	}}
+
+	// Metrics
+	err := s.rotate(ctx, MetricsCollection, stale, func(cursor *mongo.Cursor) (interface{}, bson.M, error) {
+		var doc storedJumpMetric
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, nil, err
+		}
+		metric, err := s.decodeMetric(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded, err := s.encodeMetric(metric)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc.ID, bson.M{
+			"$set":   bson.M{"location_enc": encoded.LocationEnc},
+			"$unset": bson.M{"location": ""},
+		}, nil
+	}, &report.Metrics)
+	if err != nil {
+		return report, err
+	}
+
+	// Sessions embed copies of their jumps
+	err = s.rotate(ctx, SessionsCollection, bson.M{"jumps": bson.M{"$elemMatch": stale}}, func(cursor *mongo.Cursor) (interface{}, bson.M, error) {
+		var doc struct {
+			ID    string             `bson:"_id"`
+			Jumps []storedJumpMetric `bson:"jumps"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, nil, err
+		}
+		for i, jump := range doc.Jumps {
+			metric, err := s.decodeMetric(jump)
+			if err != nil {
+				return nil, nil, err
+			}
+			if doc.Jumps[i], err = s.encodeMetric(metric); err != nil {
+				return nil, nil, err
+			}
+		}
+		return doc.ID, bson.M{"$set": bson.M{"jumps": doc.Jumps}}, nil
+	}, &report.Sessions)
+	if err != nil {
+		return report, err
+	}
+
+	// Profiles
+	staleProfiles := bson.M{"$or": []bson.M{
+		{"personal_enc": bson.M{"$exists": false}},
+		{"personal_enc.kid": bson.M{"$ne": s.encryptor.ActiveKeyID()}},
+	}}
+	err = s.rotate(ctx, AthleteProfilesCollection, staleProfiles, func(cursor *mongo.Cursor) (interface{}, bson.M, error) {
+		var doc storedAthleteProfile
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, nil, err
+		}
+		profile, err := s.decodeProfile(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		encoded, err := s.encodeProfile(profile)
+		if err != nil {
+			return nil, nil, err
+		}
+		return doc.ID, bson.M{"$set": bson.M{
+			"personal_enc": encoded.PersonalEnc,
+			"name":         "",
+			"age":          0,
+			"weight_kg":    0,
+		}}, nil
+	}, &report.Profiles)
+
+	return report, err
+}
+
+// rotate applies reencrypt to every document matching filter
+func (s *Store) rotate(ctx context.Context, collectionName string, filter bson.M,
+	reencrypt func(*mongo.Cursor) (interface{}, bson.M, error), count *int) error {
+	collection := s.database.Collection(collectionName)
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to find %s to re-encrypt: %w", collectionName, err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		id, update, err := reencrypt(cursor)
+		if err != nil {
+			return fmt.Errorf("failed to re-encrypt %s: %w", collectionName, err)
+		}
+		if _, err := collection.UpdateByID(ctx, id, update); err != nil {
+			return fmt.Errorf("failed to update %s: %w", collectionName, err)
+		}
+		*count++
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/encryption"
+)
+
+func testEncryptor(t *testing.T, keyID string, key byte, retired map[string][]byte) *encryption.FieldEncryptor {
+	keys, err := encryption.NewKeyRing(keyID, bytes.Repeat([]byte{key}, 32), retired)
+	require.NoError(t, err)
+	return encryption.NewFieldEncryptor(keys)
+}
+
+// toDocument returns v as it is stored
+func toDocument(t *testing.T, v interface{}) bson.D {
+	data, err := bson.Marshal(v)
+	require.NoError(t, err)
+	var doc bson.D
+	require.NoError(t, bson.Unmarshal(data, &doc))
+	return doc
+}
+
+func TestStore_EncodeMetric(t *testing.T) {
+	s := &Store{encryptor: testEncryptor(t, "v1", 1, nil)}
+	metric := JumpMetric{ID: "m1", AthleteID: "a1", HeightCm: 61.5, Location: &Location{Latitude: 43.2, Longitude: 76.9}}
+
+	stored, err := s.encodeMetric(metric)
+	require.NoError(t, err)
+	assert.Nil(t, stored.Location)
+	require.NotNil(t, stored.LocationEnc)
+	assert.Equal(t, "v1", stored.LocationEnc.KeyID)
+	assert.Equal(t, 61.5, stored.HeightCm)
+
+	decoded, err := s.decodeMetric(stored)
+	require.NoError(t, err)
+	assert.Equal(t, metric, decoded)
+
+	// Ciphertext is bound to its athlete
+	stored.AthleteID = "a2"
+	_, err = s.decodeMetric(stored)
+	assert.ErrorIs(t, err, encryption.ErrDecryptionFailed)
+
+	// Encrypted documents are not returned without the keys
+	_, err = (&Store{}).decodeMetric(stored)
+	assert.Error(t, err)
+
+	// Without an encryptor locations stay in plaintext
+	plain, err := (&Store{}).encodeMetric(metric)
+	require.NoError(t, err)
+	assert.Equal(t, metric.Location, plain.Location)
+	assert.Nil(t, plain.LocationEnc)
+}
+
+func TestStore_EncodeProfile(t *testing.T) {
+	s := &Store{encryptor: testEncryptor(t, "v1", 1, nil)}
+	profile := AthleteProfile{ID: "a1", UserID: "u1", Name: "Jordan", Age: 15, Weight: 61.2, Height: 178, Minor: true}
+
+	stored, err := s.encodeProfile(profile)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Name)
+	assert.Zero(t, stored.Age)
+	assert.Zero(t, stored.Weight)
+	assert.Equal(t, 178, stored.Height)
+	assert.True(t, stored.Minor)
+	require.NotNil(t, stored.PersonalEnc)
+
+	decoded, err := s.decodeProfile(stored)
+	require.NoError(t, err)
+	assert.Equal(t, profile, decoded)
+}
+
+func TestStore_RotateEncryptionKeys(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("re-encrypts plaintext and retired keys", func(mt *mtest.T) {
+		old := &Store{encryptor: testEncryptor(mt.T, "v1", 1, nil)}
+		s := newTestStore(mt)
+		s.SetEncryptor(testEncryptor(mt.T, "v2", 2, map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)}))
+
+		location := &Location{Latitude: 43.2, Longitude: 76.9}
+		plaintext := JumpMetric{ID: "m1", AthleteID: "a1", Location: location}
+		retired, err := old.encodeMetric(JumpMetric{ID: "m2", AthleteID: "a1", Location: location})
+		require.NoError(mt, err)
+		profile := AthleteProfile{ID: "a1", Name: "Jordan", Age: 15}
+
+		mt.AddMockResponses(
+			findResponse(MetricsCollection, toDocument(mt.T, plaintext), toDocument(mt.T, retired)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			findResponse(SessionsCollection),
+			findResponse(AthleteProfilesCollection, toDocument(mt.T, profile)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		report, err := s.RotateEncryptionKeys(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, RotationReport{Metrics: 2, Sessions: 0, Profiles: 1}, *report)
+
+		// Every update stores an envelope of the active key that opens to the original value
+		var updates []bson.Raw
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "update" {
+				updates = append(updates, event.Command.Lookup("updates", "0", "u").Document())
+			}
+		}
+		require.Len(mt, updates, 3)
+		for i, update := range updates[:2] {
+			var u struct {
+				Set struct {
+					LocationEnc encryption.Envelope `bson:"location_enc"`
+				} `bson:"$set"`
+				Unset bson.M `bson:"$unset"`
+			}
+			require.NoError(mt, bson.Unmarshal(update, &u), i)
+			assert.Equal(mt, "v2", u.Set.LocationEnc.KeyID)
+			assert.Contains(mt, u.Unset, "location")
+
+			var decrypted Location
+			require.NoError(mt, s.encryptor.Open(&u.Set.LocationEnc, locationAAD("a1"), &decrypted))
+			assert.Equal(mt, *location, decrypted)
+		}
+
+		var u struct {
+			Set struct {
+				PersonalEnc encryption.Envelope `bson:"personal_enc"`
+				Name        string              `bson:"name"`
+				Age         int                 `bson:"age"`
+			} `bson:"$set"`
+		}
+		require.NoError(mt, bson.Unmarshal(updates[2], &u))
+		assert.Empty(mt, u.Set.Name)
+		assert.Zero(mt, u.Set.Age)
+		var personal personalData
+		require.NoError(mt, s.encryptor.Open(&u.Set.PersonalEnc, personalAAD("a1"), &personal))
+		assert.Equal(mt, personalData{Name: "Jordan", Age: 15}, personal)
+	})
+
+	mt.Run("requires an encryptor", func(mt *mtest.T) {
+		_, err := newTestStore(mt).RotateEncryptionKeys(context.Background())
+		assert.Error(mt, err)
+	})
+}
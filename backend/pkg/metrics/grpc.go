@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+	metricsv1 "github.com/Danchouvzv/DunkSense/backend/proto/metrics/v1"
+)
+
+// GRPCWriteMethods are the MetricsService methods limited by the write policy
+var GRPCWriteMethods = []string{
+	metricsv1.MetricsService_SubmitMetrics_FullMethodName,
+}
+
+// GRPCServer exposes the metrics store over gRPC. It mirrors Handler and must
+// run behind the security interceptors, which put the caller in the context.
+type GRPCServer struct {
+	metricsv1.UnimplementedMetricsServiceServer
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewGRPCServer creates a new metrics gRPC server
+func NewGRPCServer(store *Store, logger *zap.Logger) *GRPCServer {
+	return &GRPCServer{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Register registers the MetricsService
+func (s *GRPCServer) Register(registrar grpc.ServiceRegistrar) {
+	metricsv1.RegisterMetricsServiceServer(registrar, s)
+}
+
+// SubmitMetrics stores a session and its jump metrics. gRPC uploads are not
+// signed by a device, so their metrics are never marked as verified.
+func (s *GRPCServer) SubmitMetrics(ctx context.Context, in *metricsv1.SubmitMetricsRequest) (*metricsv1.SubmitMetricsResponse, error) {
+	req := submitRequestFromProto(in)
+
+	// The same constraints the API document declares for REST submissions
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid request")
+	}
+	fieldErrors, err := schema.Generate(SubmitRequest{}).Decode(body, false)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Invalid request")
+	}
+	if len(fieldErrors) > 0 {
+		return nil, invalidArgument("Request does not match the schema", fieldErrors)
+	}
+	if err := s.store.validateSubmitRequest(&req); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := s.authorizeAthlete(ctx, req.AthleteID, false); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Submit(ctx, &req)
+	if errors.Is(err, ErrGuardianConsentRequired) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		s.logger.Error("Failed to submit metrics", zap.String("athlete_id", req.AthleteID), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to submit metrics")
+	}
+
+	return &metricsv1.SubmitMetricsResponse{
+		SessionId: req.Session.ID,
+		Metrics:   int32(len(req.Metrics)),
+	}, nil
+}
+
+// GetAthleteMetrics returns the recent metrics of an athlete
+func (s *GRPCServer) GetAthleteMetrics(ctx context.Context, in *metricsv1.GetAthleteMetricsRequest) (*metricsv1.GetAthleteMetricsResponse, error) {
+	if _, err := s.authorizeAthlete(ctx, in.GetAthleteId(), true); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.store.GetByAthleteID(ctx, in.GetAthleteId())
+	if err != nil {
+		s.logger.Error("Failed to get metrics", zap.String("athlete_id", in.GetAthleteId()), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get metrics")
+	}
+
+	out := &metricsv1.GetAthleteMetricsResponse{
+		Metrics:    make([]*metricsv1.JumpMetric, len(resp.Metrics)),
+		Summary:    summaryToProto(resp.Summary),
+		TotalCount: int32(resp.TotalCount),
+		HasMore:    resp.HasMore,
+	}
+	for i, metric := range resp.Metrics {
+		out.Metrics[i] = jumpMetricToProto(metric)
+	}
+	return out, nil
+}
+
+// GetSummary returns the weekly summary of an athlete
+func (s *GRPCServer) GetSummary(ctx context.Context, in *metricsv1.GetSummaryRequest) (*metricsv1.MetricsSummary, error) {
+	if _, err := s.authorizeAthlete(ctx, in.GetAthleteId(), true); err != nil {
+		return nil, err
+	}
+
+	summary, err := s.store.GetSummary(ctx, in.GetAthleteId())
+	if err != nil {
+		s.logger.Error("Failed to get summary", zap.String("athlete_id", in.GetAthleteId()), zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get summary")
+	}
+	return summaryToProto(*summary), nil
+}
+
+// StreamSessionJumps streams the jumps of a session as they are read
+func (s *GRPCServer) StreamSessionJumps(in *metricsv1.StreamSessionJumpsRequest, stream metricsv1.MetricsService_StreamSessionJumpsServer) error {
+	ctx := stream.Context()
+	if in.GetSessionId() == "" {
+		return status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if _, err := s.authorizeAthlete(ctx, in.GetAthleteId(), true); err != nil {
+		return err
+	}
+
+	sent := 0
+	err := s.store.SessionJumps(ctx, in.GetAthleteId(), in.GetSessionId(), func(metric JumpMetric) error {
+		sent++
+		return stream.Send(jumpMetricToProto(metric))
+	})
+	switch {
+	case errors.Is(err, ErrSessionNotFound):
+		return status.Error(codes.NotFound, "Session not found")
+	case err != nil && ctx.Err() != nil:
+		return status.FromContextError(ctx.Err()).Err()
+	case err != nil:
+		s.logger.Error("Failed to stream session jumps",
+			zap.String("athlete_id", in.GetAthleteId()),
+			zap.String("session_id", in.GetSessionId()),
+			zap.Int("sent", sent),
+			zap.Error(err),
+		)
+		return status.Error(codes.Internal, "Failed to stream session jumps")
+	}
+	return nil
+}
+
+// GetLeaderboard ranks athletes who opted in by their best jump of the
+// week, month or all time
+func (s *GRPCServer) GetLeaderboard(ctx context.Context, in *metricsv1.GetLeaderboardRequest) (*metricsv1.GetLeaderboardResponse, error) {
+	period := in.GetPeriod()
+	if period == "" {
+		period = "week"
+	}
+	since, ok := leaderboardSince(period, time.Now())
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "period must be week, month or all")
+	}
+
+	limit := int(in.GetLimit())
+	if limit == 0 {
+		limit = defaultLeaderboardSize
+	}
+	if limit < 1 || limit > maxLeaderboardSize {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 100")
+	}
+
+	entries, err := s.store.Leaderboard(ctx, since, limit)
+	if err != nil {
+		s.logger.Error("Failed to get leaderboard", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to get leaderboard")
+	}
+
+	out := &metricsv1.GetLeaderboardResponse{Period: period, Entries: make([]*metricsv1.LeaderboardEntry, len(entries))}
+	for i, entry := range entries {
+		out.Entries[i] = leaderboardEntryToProto(entry)
+	}
+	return out, nil
+}
+
+// ListGuardianAthletes lists the minors the caller is guardian of
+func (s *GRPCServer) ListGuardianAthletes(ctx context.Context, in *metricsv1.ListGuardianAthletesRequest) (*metricsv1.ListGuardianAthletesResponse, error) {
+	caller, _ := security.IdentityFromContext(ctx)
+	if caller.UserID == "" {
+		return nil, status.Error(codes.PermissionDenied, "Guardians must sign in as a user")
+	}
+
+	profiles, err := s.store.GuardianAthletes(ctx, caller.UserID)
+	if err != nil {
+		s.logger.Error("Failed to list guarded athletes", zap.Error(err))
+		return nil, status.Error(codes.Internal, "Failed to list athletes")
+	}
+
+	out := &metricsv1.ListGuardianAthletesResponse{Athletes: make([]*metricsv1.AthleteProfile, len(profiles))}
+	for i, profile := range profiles {
+		out.Athletes[i] = athleteProfileToProto(profile)
+	}
+	return out, nil
+}
+
+// ExportTrainingData streams metrics of consenting athletes to admins
+func (s *GRPCServer) ExportTrainingData(in *metricsv1.ExportTrainingDataRequest, stream metricsv1.MetricsService_ExportTrainingDataServer) error {
+	ctx := stream.Context()
+	caller, _ := security.IdentityFromContext(ctx)
+	if !caller.HasRole("admin") {
+		return status.Error(codes.PermissionDenied, "Insufficient permissions")
+	}
+
+	exported := 0
+	err := s.store.ExportTrainingData(ctx, timestampFromProto(in.GetSince()), func(metric JumpMetric) error {
+		exported++
+		return stream.Send(jumpMetricToProto(metric))
+	})
+	if err != nil {
+		s.logger.Error("Failed to export training data", zap.Int("exported", exported), zap.Error(err))
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Error(codes.Internal, "Failed to export training data")
+	}
+
+	s.logger.Info("Training data exported", zap.Int("metrics", exported), zap.String("requested_by", caller.UserID))
+	return nil
+}
+
+// authorizeAthlete checks the caller's access to an athlete's data, see
+// athleteAccess.allows
+func (s *GRPCServer) authorizeAthlete(ctx context.Context, athleteID string, allowCoach bool) (athleteAccess, error) {
+	if athleteID == "" {
+		return athleteAccess{}, status.Error(codes.InvalidArgument, "athlete_id is required")
+	}
+
+	access, err := s.store.athleteAccess(ctx, athleteID)
+	if err != nil {
+		s.logger.Error("Failed to check athlete access", zap.String("athlete_id", athleteID), zap.Error(err))
+		return access, status.Error(codes.Internal, "Failed to check access")
+	}
+
+	caller, _ := security.IdentityFromContext(ctx)
+	if !access.allows(caller, athleteID, allowCoach) {
+		return access, status.Error(codes.PermissionDenied, "Access to this athlete is not permitted")
+	}
+	return access, nil
+}
+
+// invalidArgument reports schema violations as BadRequest field violations
+func invalidArgument(message string, fieldErrors []schema.FieldError) error {
+	st := status.New(codes.InvalidArgument, message)
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(fieldErrors))
+	for i, fieldErr := range fieldErrors {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: fieldErr.Field, Description: fieldErr.Message}
+	}
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
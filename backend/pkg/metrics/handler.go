@@ -0,0 +1,512 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/cache"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+const (
+	defaultLeaderboardSize = 20
+	maxLeaderboardSize     = 100
+	maxPrivacyBodyBytes    = 4 << 10
+	maxProfileBodyBytes    = 4 << 10
+)
+
+// Handler exposes the metrics store over HTTP
+type Handler struct {
+	store       *Store
+	logger      *zap.Logger
+	responses   *cache.Responder
+	uploadLimit gin.HandlerFunc
+	redeemLimit gin.HandlerFunc
+}
+
+// NewHandler creates a new metrics HTTP handler
+func NewHandler(store *Store, logger *zap.Logger) *Handler {
+	return &Handler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// SetResponseCache caches the metrics and summary responses of athletes.
+// Entries must be invalidated through Store.OnAthleteChanged.
+func (h *Handler) SetResponseCache(responses *cache.Responder) {
+	h.responses = responses
+}
+
+// SetRateLimits adds rate limits stricter than those of the route group:
+// upload to submissions and redeem to accepting guardian invites, whose codes
+// must not be guessable by brute force. Call it before RegisterRoutes.
+func (h *Handler) SetRateLimits(upload, redeem gin.HandlerFunc) {
+	h.uploadLimit = upload
+	h.redeemLimit = redeem
+}
+
+// RegisterRoutes mounts the metrics endpoints, documented by APIRoutes.
+// Submissions should run behind SecurityMiddleware.DeviceSignature so that
+// uploads from registered devices are marked as verified, and every route
+// behind an openapi.Validator for the documented routes so request bodies are
+// validated before binding.
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("/metrics", withLimit(h.uploadLimit, h.Submit)...)
+	rg.GET("/athletes/:athlete_id/metrics", h.GetByAthleteID)
+	rg.GET("/athletes/:athlete_id/summary", h.GetSummary)
+	rg.GET("/athletes/:athlete_id/profile", h.GetProfile)
+	rg.PUT("/athletes/:athlete_id/profile", h.UpdateProfile)
+	rg.GET("/athletes/:athlete_id/privacy", h.GetPrivacy)
+	rg.PUT("/athletes/:athlete_id/privacy", h.UpdatePrivacy)
+	rg.GET("/athletes/:athlete_id/privacy/history", h.GetConsentHistory)
+	rg.POST("/athletes/:athlete_id/guardian/invite", h.CreateGuardianInvite)
+	rg.DELETE("/athletes/:athlete_id/guardian", h.RevokeGuardianConsent)
+	rg.POST("/guardian/accept", withLimit(h.redeemLimit, h.AcceptGuardianInvite)...)
+	rg.GET("/guardian/athletes", h.GetGuardianAthletes)
+	rg.GET("/leaderboard", h.GetLeaderboard)
+}
+
+// withLimit puts an optional rate limit in front of a handler
+func withLimit(limit, handler gin.HandlerFunc) []gin.HandlerFunc {
+	if limit == nil {
+		return []gin.HandlerFunc{handler}
+	}
+	return []gin.HandlerFunc{limit, handler}
+}
+
+// RegisterTrainingRoutes mounts the ML training export, documented by
+// TrainingRoutes. The group must already be protected by JWTAuth and RequireRole.
+func (h *Handler) RegisterTrainingRoutes(rg *gin.RouterGroup) {
+	rg.GET("/training-data", h.ExportTrainingData)
+}
+
+// Submit stores a session and its jump metrics
+func (h *Handler) Submit(c *gin.Context) {
+	var req SubmitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Code: "invalid_body"})
+		return
+	}
+
+	// Provenance is decided by the signature middleware, never by the client
+	deviceVerified := c.GetBool("device_verified")
+	deviceID := c.GetString("device_id")
+	for i := range req.Metrics {
+		req.Metrics[i].DeviceVerified = deviceVerified
+		req.Metrics[i].DeviceID = deviceID
+	}
+	for i := range req.Session.Jumps {
+		req.Session.Jumps[i].DeviceVerified = deviceVerified
+		req.Session.Jumps[i].DeviceID = deviceID
+	}
+
+	if err := h.store.validateSubmitRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error(), Code: "validation_failed"})
+		return
+	}
+
+	// Only the athlete, their guardian or an admin may write their metrics
+	if _, ok := h.authorizeAthlete(c, req.AthleteID, false); !ok {
+		return
+	}
+
+	err := h.store.Submit(c.Request.Context(), &req)
+	if errors.Is(err, ErrGuardianConsentRequired) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error(), Code: "guardian_consent_required"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to submit metrics", zap.String("athlete_id", req.AthleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to submit metrics", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, SubmitResponse{
+		SessionID:      req.Session.ID,
+		Metrics:        len(req.Metrics),
+		DeviceVerified: deviceVerified,
+	})
+}
+
+// GetByAthleteID returns the recent metrics of an athlete
+func (h *Handler) GetByAthleteID(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, true); !ok {
+		return
+	}
+
+	err := h.responses.JSON(c, "athlete_metrics", athleteID, func(ctx context.Context) (interface{}, error) {
+		return h.store.GetByAthleteID(ctx, athleteID)
+	})
+	if err != nil {
+		h.logger.Error("Failed to get metrics", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get metrics", Code: "internal_error"})
+	}
+}
+
+// GetSummary returns the weekly summary of an athlete
+func (h *Handler) GetSummary(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, true); !ok {
+		return
+	}
+
+	err := h.responses.JSON(c, "athlete_summary", athleteID, func(ctx context.Context) (interface{}, error) {
+		return h.store.GetSummary(ctx, athleteID)
+	})
+	if err != nil {
+		h.logger.Error("Failed to get summary", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get summary", Code: "internal_error"})
+	}
+}
+
+// GetProfile returns the profile of an athlete
+func (h *Handler) GetProfile(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, false); !ok {
+		return
+	}
+
+	profile, err := h.store.GetAthleteProfile(c.Request.Context(), athleteID)
+	if errors.Is(err, ErrProfileNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Athlete profile not found", Code: "not_found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to get athlete profile", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get athlete profile", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateProfile creates or replaces the personal details of an athlete.
+// Athletes under MinorAgeLimit are flagged as minors; once they are, only
+// their guardian (or an admin) can change their age.
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	access, ok := h.authorizeAthlete(c, athleteID, false)
+	if !ok {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Code: "invalid_body"})
+		return
+	}
+
+	profile, err := h.store.GetAthleteProfile(c.Request.Context(), athleteID)
+	if errors.Is(err, ErrProfileNotFound) {
+		profile, err = &AthleteProfile{ID: athleteID, UserID: access.OwnerID}, nil
+	}
+	if err != nil {
+		h.logger.Error("Failed to get athlete profile", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update athlete profile", Code: "internal_error"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if access.Minor && req.Age != profile.Age && userID != access.guardianID() && !security.HasRole(c, "admin") {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only the guardian can change the age of a minor", Code: "forbidden"})
+		return
+	}
+
+	profile.Name = req.Name
+	profile.Age = req.Age
+	profile.Height = req.Height
+	profile.Weight = req.Weight
+	profile.SportLevel = req.SportLevel
+	if err := h.store.SaveAthleteProfile(c.Request.Context(), profile); err != nil {
+		h.logger.Error("Failed to save athlete profile", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update athlete profile", Code: "internal_error"})
+		return
+	}
+
+	h.logger.Info("Athlete profile updated",
+		zap.String("athlete_id", athleteID),
+		zap.String("changed_by", userID),
+		zap.Bool("minor", profile.Minor),
+	)
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetPrivacy returns the current privacy settings of an athlete
+func (h *Handler) GetPrivacy(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, false); !ok {
+		return
+	}
+
+	privacy, err := h.store.GetPrivacySettings(c.Request.Context(), athleteID)
+	if err != nil {
+		h.logger.Error("Failed to get privacy settings", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get privacy settings", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, privacy)
+}
+
+// UpdatePrivacy stores a new version of an athlete's privacy settings.
+// For minors only the guardian (or an admin) decides.
+func (h *Handler) UpdatePrivacy(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	access, ok := h.authorizeAthlete(c, athleteID, false)
+	if !ok {
+		return
+	}
+	if access.Minor && !security.HasRole(c, "admin") {
+		guardianID := access.guardianID()
+		if guardianID == "" {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: ErrGuardianConsentRequired.Error(), Code: "guardian_consent_required"})
+			return
+		}
+		if c.GetString("user_id") != guardianID {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only the guardian can change the privacy settings of a minor", Code: "forbidden"})
+			return
+		}
+	}
+
+	var req UpdatePrivacyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Code: "invalid_body"})
+		return
+	}
+
+	privacy, err := h.store.UpdatePrivacySettings(c.Request.Context(), athleteID, req, c.GetString("user_id"))
+	switch {
+	case errors.Is(err, ErrProfileNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Athlete profile not found", Code: "not_found"})
+		return
+	case errors.Is(err, ErrConsentConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Privacy settings were changed concurrently, please retry", Code: "conflict"})
+		return
+	case err != nil:
+		h.logger.Error("Failed to update privacy settings", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update privacy settings", Code: "internal_error"})
+		return
+	}
+
+	h.logger.Info("Privacy settings updated",
+		zap.String("athlete_id", athleteID),
+		zap.String("changed_by", c.GetString("user_id")),
+		zap.Int("version", privacy.Version),
+	)
+	c.JSON(http.StatusOK, privacy)
+}
+
+// GetConsentHistory returns every version of an athlete's privacy settings
+func (h *Handler) GetConsentHistory(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, false); !ok {
+		return
+	}
+
+	records, err := h.store.ConsentHistory(c.Request.Context(), athleteID)
+	if err != nil {
+		h.logger.Error("Failed to get consent history", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get consent history", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConsentHistoryResponse{Consents: records})
+}
+
+// CreateGuardianInvite issues the code a minor's guardian uses to consent
+func (h *Handler) CreateGuardianInvite(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	if _, ok := h.authorizeAthlete(c, athleteID, false); !ok {
+		return
+	}
+
+	invite, err := h.store.CreateGuardianInvite(c.Request.Context(), athleteID)
+	if errors.Is(err, ErrNotMinor) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Guardian consent is only needed for minors", Code: "not_minor"})
+		return
+	}
+	if errors.Is(err, ErrGuardianExists) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Athlete already has a guardian", Code: "guardian_exists"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to create guardian invite", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create guardian invite", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invite)
+}
+
+// AcceptGuardianInvite records the caller's consent as guardian of a minor
+func (h *Handler) AcceptGuardianInvite(c *gin.Context) {
+	var req AcceptGuardianInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Code: "invalid_body"})
+		return
+	}
+	if !req.Consent {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "consent must be given to link as guardian", Code: "validation_failed"})
+		return
+	}
+
+	guardianID := c.GetString("user_id")
+	athleteID, privacy, err := h.store.AcceptGuardianInvite(c.Request.Context(), req.Code, guardianID)
+	switch {
+	case errors.Is(err, ErrInvalidGuardianInvite):
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Guardian invite is invalid or expired", Code: "invalid_invite"})
+		return
+	case errors.Is(err, ErrSelfGuardian):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error(), Code: "forbidden"})
+		return
+	case errors.Is(err, ErrConsentConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Privacy settings were changed concurrently, please retry", Code: "conflict"})
+		return
+	case err != nil:
+		h.logger.Error("Failed to accept guardian invite", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to accept guardian invite", Code: "internal_error"})
+		return
+	}
+
+	h.logger.Info("Guardian consent recorded", zap.String("athlete_id", athleteID), zap.String("guardian_id", guardianID))
+	c.JSON(http.StatusOK, GuardianAcceptResponse{AthleteID: athleteID, Privacy: privacy})
+}
+
+// RevokeGuardianConsent withdraws guardian consent; only the guardian or an admin may do so
+func (h *Handler) RevokeGuardianConsent(c *gin.Context) {
+	athleteID := c.Param("athlete_id")
+	access, ok := h.authorizeAthlete(c, athleteID, false)
+	if !ok {
+		return
+	}
+	userID := c.GetString("user_id")
+	if guardianID := access.guardianID(); guardianID == "" || (userID != guardianID && !security.HasRole(c, "admin")) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only the guardian can withdraw guardian consent", Code: "forbidden"})
+		return
+	}
+
+	privacy, err := h.store.RevokeGuardianConsent(c.Request.Context(), athleteID, userID)
+	switch {
+	case errors.Is(err, ErrConsentConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Privacy settings were changed concurrently, please retry", Code: "conflict"})
+		return
+	case err != nil:
+		h.logger.Error("Failed to revoke guardian consent", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to revoke guardian consent", Code: "internal_error"})
+		return
+	}
+
+	h.logger.Info("Guardian consent withdrawn", zap.String("athlete_id", athleteID), zap.String("changed_by", userID))
+	c.JSON(http.StatusOK, privacy)
+}
+
+// GetGuardianAthletes lists the minors the caller is guardian of
+func (h *Handler) GetGuardianAthletes(c *gin.Context) {
+	profiles, err := h.store.GuardianAthletes(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.logger.Error("Failed to list guarded athletes", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list athletes", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, GuardianAthletesResponse{Athletes: profiles})
+}
+
+// GetLeaderboard ranks athletes who opted in by their best jump of the
+// week, month or all time
+func (h *Handler) GetLeaderboard(c *gin.Context) {
+	period := c.DefaultQuery("period", "week")
+	since, ok := leaderboardSince(period, time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "period must be week, month or all", Code: "invalid_query"})
+		return
+	}
+
+	limit := defaultLeaderboardSize
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxLeaderboardSize {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 100", Code: "invalid_query"})
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.store.Leaderboard(c.Request.Context(), since, limit)
+	if err != nil {
+		h.logger.Error("Failed to get leaderboard", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get leaderboard", Code: "internal_error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LeaderboardResponse{Period: period, Entries: entries})
+}
+
+// leaderboardSince returns the start of a leaderboard period, zero for all time
+func leaderboardSince(period string, now time.Time) (time.Time, bool) {
+	switch period {
+	case "week":
+		return now.AddDate(0, 0, -7), true
+	case "month":
+		return now.AddDate(0, -1, 0), true
+	case "all":
+		return time.Time{}, true
+	}
+	return time.Time{}, false
+}
+
+// ExportTrainingData streams metrics of consenting athletes as JSON lines
+func (h *Handler) ExportTrainingData(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "since must be an RFC 3339 timestamp", Code: "invalid_query"})
+			return
+		}
+		since = parsed
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	exported := 0
+	err := h.store.ExportTrainingData(c.Request.Context(), since, func(metric JumpMetric) error {
+		exported++
+		return encoder.Encode(metric)
+	})
+	if err != nil {
+		// Headers are already sent; the truncated stream tells the client it failed
+		h.logger.Error("Failed to export training data", zap.Int("exported", exported), zap.Error(err))
+		return
+	}
+
+	h.logger.Info("Training data exported", zap.Int("metrics", exported), zap.String("requested_by", c.GetString("user_id")))
+}
+
+// authorizeAthlete reports whether the caller may access an athlete's data,
+// see athleteAccess.allows. It writes the error response otherwise.
+func (h *Handler) authorizeAthlete(c *gin.Context, athleteID string, allowCoach bool) (athleteAccess, bool) {
+	access, err := h.store.athleteAccess(c.Request.Context(), athleteID)
+	if err != nil {
+		h.logger.Error("Failed to check athlete access", zap.String("athlete_id", athleteID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check access", Code: "internal_error"})
+		return access, false
+	}
+
+	if access.allows(security.CallerIdentity(c), athleteID, allowCoach) {
+		return access, true
+	}
+
+	c.JSON(http.StatusForbidden, ErrorResponse{Error: "Access to this athlete is not permitted", Code: "forbidden"})
+	return access, false
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.uber.org/zap"
+)
+
+// serveAs sends a request to the routes of h as the given user, the way
+// JWTAuth leaves the caller in the context
+func serveAs(h *Handler, userID string, roles []string, method, path string, body interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("", func(c *gin.Context) {
+		c.Set("jwt_claims", jwt.MapClaims{"sub": userID, "roles": roles})
+		c.Set("user_id", userID)
+	})
+	h.RegisterRoutes(group)
+
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func testSubmitRequest(athleteID string) SubmitRequest {
+	now := time.Now().UTC()
+	return SubmitRequest{
+		AthleteID: athleteID,
+		Session:   JumpSession{StartTime: now.Add(-time.Minute), EndTime: now, JumpCount: 1},
+		Metrics:   []JumpMetric{{AthleteID: athleteID, Timestamp: now, HeightCm: 61.5, Confidence: 0.9}},
+	}
+}
+
+func TestHandler_SubmitAuthorizesTheCaller(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	profile := bson.D{{Key: "_id", Value: "athlete-1"}, {Key: "user_id", Value: "user-1"}}
+
+	mt.Run("other users are forbidden", func(mt *mtest.T) {
+		h := NewHandler(newTestStore(mt), zap.NewNop())
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, profile))
+
+		w := serveAs(h, "user-2", nil, http.MethodPost, "/metrics", testSubmitRequest("athlete-1"))
+		assert.Equal(mt, http.StatusForbidden, w.Code)
+		assert.Contains(mt, w.Body.String(), "forbidden")
+	})
+
+	mt.Run("the owner submits", func(mt *mtest.T) {
+		h := NewHandler(newTestStore(mt), zap.NewNop())
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, profile), findResponse(AthleteProfilesCollection, profile))
+		mt.AddMockResponses(submitResponses()...)
+
+		w := serveAs(h, "user-1", nil, http.MethodPost, "/metrics", testSubmitRequest("athlete-1"))
+		require.Equal(mt, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp SubmitResponse
+		require.NoError(mt, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(mt, 1, resp.Metrics)
+		assert.False(mt, resp.DeviceVerified)
+	})
+}
+
+func TestHandler_SetRateLimits(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("uploads and invite redemption are limited", func(mt *mtest.T) {
+		h := NewHandler(newTestStore(mt), zap.NewNop())
+		var limited []string
+		limit := func(name string) gin.HandlerFunc {
+			return func(c *gin.Context) {
+				limited = append(limited, name)
+				c.AbortWithStatus(http.StatusTooManyRequests)
+			}
+		}
+		h.SetRateLimits(limit("upload"), limit("redeem"))
+
+		w := serveAs(h, "user-1", nil, http.MethodPost, "/metrics", testSubmitRequest("athlete-1"))
+		assert.Equal(mt, http.StatusTooManyRequests, w.Code)
+		w = serveAs(h, "user-1", nil, http.MethodPost, "/guardian/accept", gin.H{"code": "ABCD-1234"})
+		assert.Equal(mt, http.StatusTooManyRequests, w.Code)
+		assert.Equal(mt, []string{"upload", "redeem"}, limited)
+	})
+}
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MinorAgeLimit is the age below which athletes need guardian consent
+	MinorAgeLimit = 16
+
+	// GuardianInviteTTL is how long a guardian invite code can be accepted
+	GuardianInviteTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrGuardianConsentRequired = errors.New("guardian consent is required before data is collected from minors")
+	ErrInvalidGuardianInvite   = errors.New("guardian invite is invalid or expired")
+	ErrNotMinor                = errors.New("athlete is not a minor")
+	ErrSelfGuardian            = errors.New("athletes cannot be their own guardian")
+	ErrGuardianExists          = errors.New("athlete already has a guardian")
+)
+
+// isMinor reports whether an athlete of the given age needs guardian consent.
+// An unknown age (0) is not treated as a minor.
+func isMinor(age int) bool {
+	return age > 0 && age < MinorAgeLimit
+}
+
+// BackfillMinors flags the athletes under MinorAgeLimit whose profiles were
+// written before minor was derived from the age, decrypting encrypted ages to
+// check them. It is safe to run repeatedly and returns the number of profiles
+// flagged.
+func (s *Store) BackfillMinors(ctx context.Context) (int, error) {
+	profiles := s.database.Collection(AthleteProfilesCollection)
+	cursor, err := profiles.Find(ctx, bson.M{
+		"minor": bson.M{"$ne": true},
+		"$or": []bson.M{
+			{"age": bson.M{"$gt": 0, "$lt": MinorAgeLimit}},
+			{"personal_enc": bson.M{"$exists": true}},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find athlete profiles: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	flagged := 0
+	for cursor.Next(ctx) {
+		var doc storedAthleteProfile
+		if err := cursor.Decode(&doc); err != nil {
+			return flagged, fmt.Errorf("failed to decode athlete profile: %w", err)
+		}
+		profile, err := s.decodeProfile(doc)
+		if err != nil {
+			return flagged, err
+		}
+		if !isMinor(profile.Age) {
+			continue
+		}
+		if _, err := profiles.UpdateByID(ctx, profile.ID, bson.M{"$set": bson.M{"minor": true}}); err != nil {
+			return flagged, fmt.Errorf("failed to flag minor %s: %w", profile.ID, err)
+		}
+		flagged++
+	}
+	return flagged, cursor.Err()
+}
+
+// CreateGuardianInvite issues a code the guardian of a minor athlete uses to
+// link their account and consent. A new invite replaces any previous one;
+// an existing guardian has to withdraw consent first.
+func (s *Store) CreateGuardianInvite(ctx context.Context, athleteID string) (*GuardianInvite, error) {
+	access, err := s.athleteAccess(ctx, athleteID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.Minor {
+		return nil, ErrNotMinor
+	}
+	if access.guardianID() != "" {
+		return nil, ErrGuardianExists
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	invite := &GuardianInvite{
+		AthleteID: athleteID,
+		Code:      hex.EncodeToString(b),
+		ExpiresAt: time.Now().Add(GuardianInviteTTL).UTC().Truncate(time.Millisecond),
+	}
+
+	_, err = s.database.Collection(AthleteProfilesCollection).UpdateOne(ctx, bson.M{"_id": athleteID}, bson.M{
+		"$set": bson.M{"guardian_invite": bson.M{
+			"code_hash":  hashInviteCode(invite.Code),
+			"expires_at": invite.ExpiresAt,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store guardian invite: %w", err)
+	}
+	return invite, nil
+}
+
+// AcceptGuardianInvite records the caller as the consenting guardian of the
+// athlete the invite was issued for, and returns that athlete's ID
+func (s *Store) AcceptGuardianInvite(ctx context.Context, code, guardianID string) (string, *PrivacySettings, error) {
+	profiles := s.database.Collection(AthleteProfilesCollection)
+
+	var doc struct {
+		ID     string `bson:"_id"`
+		UserID string `bson:"user_id"`
+	}
+	err := profiles.FindOne(ctx, bson.M{
+		"guardian_invite.code_hash":  hashInviteCode(code),
+		"guardian_invite.expires_at": bson.M{"$gt": time.Now()},
+	}, options.FindOne().SetProjection(bson.M{"user_id": 1})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil, ErrInvalidGuardianInvite
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find guardian invite: %w", err)
+	}
+	if guardianID == doc.ID || guardianID == doc.UserID {
+		return "", nil, ErrSelfGuardian
+	}
+
+	settings, err := s.updatePrivacy(ctx, doc.ID, guardianID, func(current PrivacySettings) (PrivacySettings, error) {
+		current.Guardian = &GuardianConsent{
+			UserID:      guardianID,
+			ConsentedAt: time.Now().UTC().Truncate(time.Millisecond),
+		}
+		return current, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	if _, err := profiles.UpdateByID(ctx, doc.ID, bson.M{"$unset": bson.M{"guardian_invite": ""}}); err != nil {
+		return "", nil, fmt.Errorf("failed to remove guardian invite: %w", err)
+	}
+	return doc.ID, settings, nil
+}
+
+// RevokeGuardianConsent withdraws guardian consent for a minor. Data
+// collection stops and every consent the guardian gave is turned off.
+func (s *Store) RevokeGuardianConsent(ctx context.Context, athleteID, changedBy string) (*PrivacySettings, error) {
+	return s.updatePrivacy(ctx, athleteID, changedBy, func(current PrivacySettings) (PrivacySettings, error) {
+		return PrivacySettings{}, nil
+	})
+}
+
+// GuardianAthletes returns the minor athletes a guardian consented for
+func (s *Store) GuardianAthletes(ctx context.Context, guardianID string) ([]AthleteProfile, error) {
+	cursor, err := s.database.Collection(AthleteProfilesCollection).Find(ctx, bson.M{"privacy.guardian.user_id": guardianID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find guarded athletes: %w", err)
+	}
+
+	var docs []storedAthleteProfile
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode athlete profiles: %w", err)
+	}
+
+	profiles := make([]AthleteProfile, 0, len(docs))
+	for _, doc := range docs {
+		profile, err := s.decodeProfile(doc)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// IsGuardianOf reports whether guardianID consented for an athlete owned by userID
+func (s *Store) IsGuardianOf(ctx context.Context, guardianID, userID string) (bool, error) {
+	count, err := s.database.Collection(AthleteProfilesCollection).CountDocuments(ctx, bson.M{
+		"$or":                      []bson.M{{"_id": userID}, {"user_id": userID}},
+		"privacy.guardian.user_id": guardianID,
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check guardian: %w", err)
+	}
+	return count > 0, nil
+}
+
+// hashInviteCode keeps invite codes out of the database
+func hashInviteCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+func TestIsMinor(t *testing.T) {
+	assert.False(t, isMinor(0), "unknown age")
+	assert.True(t, isMinor(15))
+	assert.False(t, isMinor(MinorAgeLimit))
+}
+
+func TestAthleteAccess_AllowsGuardian(t *testing.T) {
+	access := athleteAccess{OwnerID: "user-1", Minor: true, Privacy: PrivacySettings{Guardian: &GuardianConsent{UserID: "parent-1"}}}
+
+	assert.True(t, access.allows(security.Identity{UserID: "parent-1"}, "athlete-1", false))
+	assert.False(t, access.allows(security.Identity{UserID: "parent-2"}, "athlete-1", false))
+}
+
+func TestStore_SubmitRequiresGuardianConsentForMinors(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("flagged minor without guardian", func(mt *mtest.T) {
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, bson.D{
+			{Key: "_id", Value: "athlete-1"},
+			{Key: "minor", Value: true},
+		}))
+
+		req := testSubmitRequest("athlete-1")
+		assert.ErrorIs(mt, newTestStore(mt).Submit(context.Background(), &req), ErrGuardianConsentRequired)
+		assert.Empty(mt, insertedMetrics(mt))
+	})
+
+	mt.Run("minor by age only", func(mt *mtest.T) {
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, bson.D{
+			{Key: "_id", Value: "athlete-1"},
+			{Key: "age", Value: 14},
+		}))
+
+		req := testSubmitRequest("athlete-1")
+		assert.ErrorIs(mt, newTestStore(mt).Submit(context.Background(), &req), ErrGuardianConsentRequired)
+	})
+
+	mt.Run("minor with guardian", func(mt *mtest.T) {
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, bson.D{
+			{Key: "_id", Value: "athlete-1"},
+			{Key: "minor", Value: true},
+			{Key: "privacy", Value: bson.D{{Key: "guardian", Value: bson.D{{Key: "user_id", Value: "parent-1"}}}}},
+		}))
+		mt.AddMockResponses(submitResponses()...)
+
+		req := testSubmitRequest("athlete-1")
+		require.NoError(mt, newTestStore(mt).Submit(context.Background(), &req))
+		assert.Len(mt, insertedMetrics(mt), 1)
+	})
+}
+
+func TestHandler_SubmitRejectsMinorWithoutGuardian(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("rejected", func(mt *mtest.T) {
+		h := NewHandler(newTestStore(mt), zap.NewNop())
+		profile := bson.D{{Key: "_id", Value: "athlete-1"}, {Key: "user_id", Value: "user-1"}, {Key: "minor", Value: true}}
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, profile), findResponse(AthleteProfilesCollection, profile))
+
+		w := serveAs(h, "user-1", nil, http.MethodPost, "/metrics", testSubmitRequest("athlete-1"))
+		assert.Equal(mt, http.StatusForbidden, w.Code)
+		assert.Contains(mt, w.Body.String(), "guardian_consent_required")
+	})
+}
+
+func TestHandler_UpdateProfile(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("flags minors from the age", func(mt *mtest.T) {
+		s := newTestStore(mt)
+		s.SetEncryptor(testEncryptor(mt.T, "v1", 1, nil))
+		h := NewHandler(s, zap.NewNop())
+		mt.AddMockResponses(
+			findResponse(AthleteProfilesCollection), // access, no profile yet
+			findResponse(AthleteProfilesCollection), // profile
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}),
+		)
+
+		w := serveAs(h, "user-1", nil, http.MethodPut, "/athletes/user-1/profile", UpdateProfileRequest{Name: "Jordan", Age: 15, Height: 172})
+		require.Equal(mt, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(mt, w.Body.String(), `"minor":true`)
+
+		var update bson.Raw
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "update" {
+				update = event.Command.Lookup("updates", "0").Document()
+			}
+		}
+		require.NotNil(mt, update)
+		assert.True(mt, update.Lookup("upsert").Boolean())
+		set := update.Lookup("u", "$set").Document()
+		assert.True(mt, set.Lookup("minor").Boolean())
+		assert.Equal(mt, "user-1", set.Lookup("user_id").StringValue())
+		assert.Equal(mt, int32(0), set.Lookup("age").Int32(), "the age is encrypted")
+		_, err := set.LookupErr("max_jump_height_cm")
+		assert.Error(mt, err, "baselines are kept as stored")
+	})
+
+	mt.Run("only the guardian changes the age of a minor", func(mt *mtest.T) {
+		h := NewHandler(newTestStore(mt), zap.NewNop())
+		profile := bson.D{
+			{Key: "_id", Value: "athlete-1"},
+			{Key: "user_id", Value: "user-1"},
+			{Key: "age", Value: 15},
+			{Key: "minor", Value: true},
+			{Key: "privacy", Value: bson.D{{Key: "guardian", Value: bson.D{{Key: "user_id", Value: "parent-1"}}}}},
+		}
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, profile), findResponse(AthleteProfilesCollection, profile))
+
+		w := serveAs(h, "user-1", nil, http.MethodPut, "/athletes/athlete-1/profile", UpdateProfileRequest{Name: "Jordan", Age: 18})
+		assert.Equal(mt, http.StatusForbidden, w.Code)
+
+		mt.AddMockResponses(
+			findResponse(AthleteProfilesCollection, profile),
+			findResponse(AthleteProfilesCollection, profile),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		w = serveAs(h, "parent-1", nil, http.MethodPut, "/athletes/athlete-1/profile", UpdateProfileRequest{Name: "Jordan", Age: 16})
+		require.Equal(mt, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(mt, w.Body.String(), `"minor":false`)
+	})
+}
+
+func TestStore_BackfillMinors(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("flags encrypted and plaintext minors", func(mt *mtest.T) {
+		s := newTestStore(mt)
+		s.SetEncryptor(testEncryptor(mt.T, "v1", 1, nil))
+
+		encryptedMinor, err := s.encodeProfile(AthleteProfile{ID: "athlete-1", Name: "Jordan", Age: 14})
+		require.NoError(mt, err)
+		encryptedAdult, err := s.encodeProfile(AthleteProfile{ID: "athlete-2", Name: "Sam", Age: 30})
+		require.NoError(mt, err)
+		plaintextMinor := AthleteProfile{ID: "athlete-3", Age: 12}
+
+		mt.AddMockResponses(
+			findResponse(AthleteProfilesCollection, toDocument(mt.T, encryptedMinor), toDocument(mt.T, encryptedAdult), toDocument(mt.T, plaintextMinor)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+
+		flagged, err := s.BackfillMinors(context.Background())
+		require.NoError(mt, err)
+		assert.Equal(mt, 2, flagged)
+
+		var ids []string
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "update" {
+				ids = append(ids, event.Command.Lookup("updates", "0", "q", "_id").StringValue())
+			}
+		}
+		assert.Equal(mt, []string{"athlete-1", "athlete-3"}, ids)
+	})
+}
+
+func TestStore_LeaderboardHidesMinors(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("minors are ranked but not named", func(mt *mtest.T) {
+		s := newTestStore(mt)
+		s.SetEncryptor(testEncryptor(mt.T, "v1", 1, nil))
+
+		// Encrypted before minor was derived, so only the age tells
+		unflagged, err := s.encodeProfile(AthleteProfile{ID: "athlete-1", Name: "Jordan", Age: 14})
+		require.NoError(mt, err)
+		flagged, err := s.encodeProfile(AthleteProfile{ID: "athlete-2", Name: "Sam", Age: 15, Minor: true})
+		require.NoError(mt, err)
+
+		mt.AddMockResponses(findResponse(MetricsCollection,
+			bson.D{{Key: "_id", Value: "athlete-1"}, {Key: "max_height", Value: 72.5}, {Key: "jumps", Value: 4}, {Key: "profile", Value: toDocument(mt.T, unflagged)}},
+			bson.D{{Key: "_id", Value: "athlete-2"}, {Key: "max_height", Value: 64.0}, {Key: "jumps", Value: 2}, {Key: "profile", Value: toDocument(mt.T, flagged)}},
+		))
+
+		entries, err := s.Leaderboard(context.Background(), time.Now().AddDate(0, 0, -7), 10)
+		require.NoError(mt, err)
+		assert.Equal(mt, []LeaderboardEntry{
+			{Rank: 1, MaxHeight: 72.5, Jumps: 4},
+			{Rank: 2, MaxHeight: 64.0, Jumps: 2},
+		}, entries)
+	})
+}
@@ -12,9 +12,11 @@ type JumpMetric struct {
 	Timestamp        time.Time `json:"timestamp" bson:"timestamp"`
 	
 	// Core jump metrics
-	HeightCm         float64   `json:"height_cm" bson:"height_cm"`
-	ContactTimeMs    int       `json:"contact_time_ms" bson:"contact_time_ms"`
-	FlightTimeMs     int       `json:"flight_time_ms" bson:"flight_time_ms"`
+	HeightCm         float64   `json:"height_cm" bson:"height_cm" schema:"minimum=0,maximum=200"`
+	ContactTimeMs    int       `json:"contact_time_ms" bson:"contact_time_ms" schema:"minimum=0"`
+	FlightTimeMs     int       `json:"flight_time_ms" bson:"flight_time_ms" schema:"minimum=0"`
+	TakeoffVelocity  float64   `json:"takeoff_velocity_mps" bson:"takeoff_velocity_mps" schema:"minimum=0"`
+	LandingForce     float64   `json:"landing_force_n" bson:"landing_force_n" schema:"minimum=0"`
 	
 	// Biomechanical analysis
 	ValgusAngleDeg   float64   `json:"valgus_angle_deg" bson:"valgus_angle_deg"`
@@ -22,29 +24,48 @@ type JumpMetric struct {
 	HipFlexionDeg    float64   `json:"hip_flexion_deg" bson:"hip_flexion_deg"`
 	
 	// Technique scores (0-100)
-	TakeoffScore     int       `json:"takeoff_score" bson:"takeoff_score"`
-	LandingScore     int       `json:"landing_score" bson:"landing_score"`
-	OverallScore     int       `json:"overall_score" bson:"overall_score"`
+	TakeoffScore     int       `json:"takeoff_score" bson:"takeoff_score" schema:"minimum=0,maximum=100"`
+	LandingScore     int       `json:"landing_score" bson:"landing_score" schema:"minimum=0,maximum=100"`
+	OverallScore     int       `json:"overall_score" bson:"overall_score" schema:"minimum=0,maximum=100"`
+	SymmetryScore    int       `json:"symmetry_score" bson:"symmetry_score" schema:"minimum=0,maximum=100"` // left and right leg balance
 	
 	// Device and processing info
 	DeviceType       string    `json:"device_type" bson:"device_type"`
 	AppVersion       string    `json:"app_version" bson:"app_version"`
 	ProcessingTimeMs int       `json:"processing_time_ms" bson:"processing_time_ms"`
-	Confidence       float64   `json:"confidence" bson:"confidence"`
+	Confidence       float64   `json:"confidence" bson:"confidence" schema:"minimum=0,maximum=1"`
+	DeviceID         string    `json:"device_id,omitempty" bson:"device_id,omitempty"`
+	DeviceVerified   bool      `json:"device_verified" bson:"device_verified"` // set by the server for signed uploads only
 	
 	// Additional metadata
 	Location         *Location `json:"location,omitempty" bson:"location,omitempty"`
 	Weather          *Weather  `json:"weather,omitempty" bson:"weather,omitempty"`
-	Notes            string    `json:"notes,omitempty" bson:"notes,omitempty"`
+	Pose             []PoseFrame `json:"pose,omitempty" bson:"pose,omitempty" schema:"maxItems=300"`
+	Notes            string    `json:"notes,omitempty" bson:"notes,omitempty" schema:"maxLength=2000"`
+	Anonymized       bool      `json:"-" bson:"anonymized,omitempty"` // detached from its athlete by an account deletion
 }
 
 // Location represents GPS coordinates
 type Location struct {
-	Latitude  float64 `json:"latitude" bson:"latitude"`
-	Longitude float64 `json:"longitude" bson:"longitude"`
+	Latitude  float64 `json:"latitude" bson:"latitude" schema:"minimum=-90,maximum=90"`
+	Longitude float64 `json:"longitude" bson:"longitude" schema:"minimum=-180,maximum=180"`
 	Altitude  float64 `json:"altitude" bson:"altitude"`
 }
 
+// PoseFrame is the pose estimated in one video frame of a jump
+type PoseFrame struct {
+	TimeMs     int64               `json:"time_ms" bson:"time_ms"` // on the clock of the capturing device
+	Keypoints  map[string]Keypoint `json:"keypoints" bson:"keypoints"` // by joint, e.g. left_knee
+	Confidence float64             `json:"confidence" bson:"confidence" schema:"minimum=0,maximum=1"`
+}
+
+// Keypoint is the position of a joint in normalized image coordinates
+type Keypoint struct {
+	X          float64 `json:"x" bson:"x"`
+	Y          float64 `json:"y" bson:"y"`
+	Confidence float64 `json:"confidence" bson:"confidence"`
+}
+
 // Weather represents environmental conditions
 type Weather struct {
 	Temperature float64 `json:"temperature" bson:"temperature"`
@@ -63,8 +84,9 @@ type JumpSession struct {
 	MaxHeight   float64      `json:"max_height_cm" bson:"max_height_cm"`
 	AvgHeight   float64      `json:"avg_height_cm" bson:"avg_height_cm"`
 	LoadScore   int          `json:"load_score" bson:"load_score"`
-	RPE         int          `json:"rpe" bson:"rpe"` // Rate of Perceived Exertion (1-10)
-	Jumps       []JumpMetric `json:"jumps" bson:"jumps"`
+	RPE         int          `json:"rpe" bson:"rpe" schema:"minimum=0,maximum=10"` // Rate of Perceived Exertion (1-10)
+	Jumps       []JumpMetric `json:"jumps" bson:"jumps" schema:"maxItems=500"`
+	Anonymized  bool         `json:"-" bson:"anonymized,omitempty"`
 }
 
 // AthleteProfile represents athlete information
@@ -76,6 +98,7 @@ type AthleteProfile struct {
 	Height       int       `json:"height_cm" bson:"height_cm"`
 	Weight       float64   `json:"weight_kg" bson:"weight_kg"`
 	SportLevel   string    `json:"sport_level" bson:"sport_level"` // beginner, intermediate, advanced, pro
+	Minor        bool      `json:"minor" bson:"minor"`             // under MinorAgeLimit, derived from Age on save
 	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at"`
 	
@@ -89,6 +112,55 @@ type AthleteProfile struct {
 	Goals             []string `json:"goals" bson:"goals"`
 	TrainingDays      []string `json:"training_days" bson:"training_days"`
 	PreferredDuration int      `json:"preferred_duration_min" bson:"preferred_duration_min"`
+	
+	// Consent, changed through Store.UpdatePrivacySettings only
+	Privacy           PrivacySettings `json:"privacy" bson:"privacy"`
+}
+
+// PrivacySettings records what an athlete consented to. Everything is off
+// until the athlete opts in; for minors, until their guardian does.
+type PrivacySettings struct {
+	LocationCapture    bool             `json:"location_capture" bson:"location_capture"`       // store GPS location with jumps
+	LeaderboardVisible bool             `json:"leaderboard_visible" bson:"leaderboard_visible"` // appear on public leaderboards
+	CoachAccess        bool             `json:"coach_access" bson:"coach_access"`               // let coaches read metrics
+	ModelTraining      bool             `json:"model_training" bson:"model_training"`           // include metrics in ML training exports
+	Guardian           *GuardianConsent `json:"guardian,omitempty" bson:"guardian,omitempty"`   // required for minors before data is collected
+	Version            int              `json:"version" bson:"version"`
+	UpdatedAt          time.Time        `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+// GuardianConsent records the guardian who consented on behalf of a minor
+type GuardianConsent struct {
+	UserID      string    `json:"user_id" bson:"user_id"`
+	ConsentedAt time.Time `json:"consented_at" bson:"consented_at"`
+}
+
+// GuardianInvite lets a guardian link to a minor athlete. The code is only
+// returned once.
+type GuardianInvite struct {
+	AthleteID string    `json:"athlete_id"`
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ConsentRecord is one version of an athlete's privacy settings
+type ConsentRecord struct {
+	ID        string          `json:"id" bson:"_id"`
+	AthleteID string          `json:"athlete_id" bson:"athlete_id"`
+	Version   int             `json:"version" bson:"version"`
+	Settings  PrivacySettings `json:"settings" bson:"settings"`
+	ChangedBy string          `json:"changed_by" bson:"changed_by"`
+	ChangedAt time.Time       `json:"changed_at" bson:"changed_at"`
+}
+
+// LeaderboardEntry is an athlete's best jump within the leaderboard period
+type LeaderboardEntry struct {
+	Rank       int     `json:"rank"`
+	AthleteID  string  `json:"athlete_id,omitempty"` // empty for minors
+	Name       string  `json:"name,omitempty"`       // empty for minors
+	SportLevel string  `json:"sport_level,omitempty"`
+	MaxHeight  float64 `json:"max_height_cm"`
+	Jumps      int     `json:"jumps"`
 }
 
 // MetricsSummary represents aggregated metrics for an athlete
@@ -127,9 +199,61 @@ type MetricsSummary struct {
 
 // SubmitRequest represents a request to submit metrics
 type SubmitRequest struct {
-	AthleteID string       `json:"athlete_id"`
+	AthleteID string       `json:"athlete_id" schema:"required,minLength=1,maxLength=128"`
 	Session   JumpSession  `json:"session"`
-	Metrics   []JumpMetric `json:"metrics"`
+	Metrics   []JumpMetric `json:"metrics" schema:"maxItems=500"` // keep in sync with MaxSubmitMetrics
+}
+
+// UpdatePrivacyRequest replaces the privacy settings of an athlete
+type UpdatePrivacyRequest struct {
+	LocationCapture    bool `json:"location_capture"`
+	LeaderboardVisible bool `json:"leaderboard_visible"`
+	CoachAccess        bool `json:"coach_access"`
+	ModelTraining      bool `json:"model_training"`
+}
+
+// UpdateProfileRequest replaces the personal details of an athlete
+type UpdateProfileRequest struct {
+	Name       string  `json:"name" schema:"maxLength=128"`
+	Age        int     `json:"age" schema:"minimum=0,maximum=120"`
+	Height     int     `json:"height_cm" schema:"minimum=0,maximum=300"`
+	Weight     float64 `json:"weight_kg" schema:"minimum=0,maximum=500"`
+	SportLevel string  `json:"sport_level,omitempty" schema:"enum=beginner|intermediate|advanced|pro"`
+}
+
+// AcceptGuardianInviteRequest links the caller as guardian of a minor athlete
+type AcceptGuardianInviteRequest struct {
+	Code    string `json:"code" schema:"required,minLength=1,maxLength=128"`
+	Consent bool   `json:"consent" schema:"required"` // must be true
+}
+
+// SubmitResponse acknowledges a submission
+type SubmitResponse struct {
+	SessionID      string `json:"session_id"`
+	Metrics        int    `json:"metrics"`
+	DeviceVerified bool   `json:"device_verified"`
+}
+
+// ConsentHistoryResponse lists every version of an athlete's privacy settings
+type ConsentHistoryResponse struct {
+	Consents []ConsentRecord `json:"consents"`
+}
+
+// GuardianAcceptResponse is the privacy of the athlete a guardian linked to
+type GuardianAcceptResponse struct {
+	AthleteID string           `json:"athlete_id"`
+	Privacy   *PrivacySettings `json:"privacy"`
+}
+
+// GuardianAthletesResponse lists the minors of a guardian
+type GuardianAthletesResponse struct {
+	Athletes []AthleteProfile `json:"athletes"`
+}
+
+// LeaderboardResponse ranks athletes within a period
+type LeaderboardResponse struct {
+	Period  string             `json:"period"`
+	Entries []LeaderboardEntry `json:"entries"`
 }
 
 // GetMetricsRequest represents a request to get metrics
@@ -0,0 +1,215 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/openapi"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+)
+
+const (
+	// MaxSubmitMetrics bounds the metrics of a single submission; see the schema tag on SubmitRequest
+	MaxSubmitMetrics = 500
+
+	// MaxSubmitBodyBytes bounds the body of a single submission
+	MaxSubmitBodyBytes = 1 << 20
+)
+
+// APIInfo describes the metrics API in its document
+var APIInfo = openapi.Info{
+	Title:       "DunkSense Metrics API",
+	Version:     "2.0.0",
+	Description: "Jump metrics, privacy settings and guardian consent of athletes. Describes API version 2, selected with the API-Version: 2 header; requests without it are served in the deprecated version 1 of older app builds.",
+}
+
+// APIRoutes documents the routes of RegisterRoutes mounted at base, e.g. /api/v1
+func APIRoutes(base string) []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodPost,
+			Path:        base + "/metrics",
+			OperationID: "submitMetrics",
+			Summary:     "Submit a session and its jump metrics",
+			Tags:        []string{"metrics"},
+			Parameters: []openapi.Parameter{
+				header("X-Device-ID", "Registered device that signed the upload"),
+				header("X-Signature-Timestamp", "Unix seconds the upload was signed at"),
+				header("X-Signature-Nonce", "Random single-use value, at least 16 characters"),
+				header("X-Signature", "hex(HMAC-SHA256(secret, METHOD\\nPATH?QUERY\\nTIMESTAMP\\nNONCE\\nhex(SHA256(body))))"),
+			},
+			Request:      SubmitRequest{},
+			MaxBodyBytes: MaxSubmitBodyBytes,
+			Responses: withErrors(map[int]interface{}{
+				http.StatusCreated: SubmitResponse{},
+			}, http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/athletes/:athlete_id/metrics",
+			OperationID: "getAthleteMetrics",
+			Summary:     "Recent metrics of an athlete",
+			Tags:        []string{"metrics"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK:          GetMetricsResponse{},
+				http.StatusNotModified: nil,
+			}, http.StatusForbidden),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/athletes/:athlete_id/summary",
+			OperationID: "getAthleteSummary",
+			Summary:     "Weekly summary of an athlete",
+			Tags:        []string{"metrics"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK:          MetricsSummary{},
+				http.StatusNotModified: nil,
+			}, http.StatusForbidden),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/athletes/:athlete_id/profile",
+			OperationID: "getAthleteProfile",
+			Summary:     "Profile of an athlete",
+			Tags:        []string{"athletes"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: AthleteProfile{},
+			}, http.StatusForbidden, http.StatusNotFound),
+		},
+		{
+			Method:       http.MethodPut,
+			Path:         base + "/athletes/:athlete_id/profile",
+			OperationID:  "updateAthleteProfile",
+			Summary:      "Create or replace the personal details of an athlete",
+			Tags:         []string{"athletes"},
+			Request:      UpdateProfileRequest{},
+			MaxBodyBytes: maxProfileBodyBytes,
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: AthleteProfile{},
+			}, http.StatusBadRequest, http.StatusForbidden, http.StatusRequestEntityTooLarge),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/athletes/:athlete_id/privacy",
+			OperationID: "getPrivacySettings",
+			Summary:     "Current privacy settings of an athlete",
+			Tags:        []string{"privacy"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: PrivacySettings{},
+			}, http.StatusForbidden),
+		},
+		{
+			Method:       http.MethodPut,
+			Path:         base + "/athletes/:athlete_id/privacy",
+			OperationID:  "updatePrivacySettings",
+			Summary:      "Store a new version of an athlete's privacy settings",
+			Tags:         []string{"privacy"},
+			Request:      UpdatePrivacyRequest{},
+			MaxBodyBytes: maxPrivacyBodyBytes,
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: PrivacySettings{},
+			}, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/athletes/:athlete_id/privacy/history",
+			OperationID: "getConsentHistory",
+			Summary:     "Every version of an athlete's privacy settings",
+			Tags:        []string{"privacy"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: ConsentHistoryResponse{},
+			}, http.StatusForbidden),
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        base + "/athletes/:athlete_id/guardian/invite",
+			OperationID: "createGuardianInvite",
+			Summary:     "Issue the code a minor's guardian consents with",
+			Tags:        []string{"guardians"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusCreated: GuardianInvite{},
+			}, http.StatusForbidden, http.StatusConflict),
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        base + "/athletes/:athlete_id/guardian",
+			OperationID: "revokeGuardianConsent",
+			Summary:     "Withdraw guardian consent",
+			Tags:        []string{"guardians"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: PrivacySettings{},
+			}, http.StatusForbidden, http.StatusConflict),
+		},
+		{
+			Method:       http.MethodPost,
+			Path:         base + "/guardian/accept",
+			OperationID:  "acceptGuardianInvite",
+			Summary:      "Link the caller as guardian of a minor athlete",
+			Tags:         []string{"guardians"},
+			Request:      AcceptGuardianInviteRequest{},
+			MaxBodyBytes: maxPrivacyBodyBytes,
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: GuardianAcceptResponse{},
+			}, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/guardian/athletes",
+			OperationID: "getGuardianAthletes",
+			Summary:     "Minors the caller is guardian of",
+			Tags:        []string{"guardians"},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: GuardianAthletesResponse{},
+			}),
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/leaderboard",
+			OperationID: "getLeaderboard",
+			Summary:     "Athletes who opted in, ranked by their best jump",
+			Tags:        []string{"leaderboard"},
+			Parameters: []openapi.Parameter{
+				{Name: "period", In: "query", Description: "Defaults to week", Schema: &schema.Schema{Type: "string", Enum: []string{"week", "month", "all"}}},
+				{Name: "limit", In: "query", Description: "Defaults to 20", Schema: &schema.Schema{Type: "integer", Minimum: float(1), Maximum: float(maxLeaderboardSize)}},
+			},
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: LeaderboardResponse{},
+			}, http.StatusBadRequest),
+		},
+	}
+}
+
+// TrainingRoutes documents the routes of RegisterTrainingRoutes mounted at base, e.g. /api/v1/admin
+func TrainingRoutes(base string) []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        base + "/training-data",
+			OperationID: "exportTrainingData",
+			Summary:     "Metrics of athletes who consented to model training, one JSON object per line",
+			Tags:        []string{"admin"},
+			Parameters: []openapi.Parameter{
+				{Name: "since", In: "query", Description: "Only metrics recorded after this time", Schema: &schema.Schema{Type: "string", Format: "date-time"}},
+			},
+			ContentType: "application/x-ndjson",
+			Responses: withErrors(map[int]interface{}{
+				http.StatusOK: JumpMetric{},
+			}, http.StatusBadRequest),
+		},
+	}
+}
+
+// withErrors adds ErrorResponse bodies for the given statuses and internal errors
+func withErrors(responses map[int]interface{}, statuses ...int) map[int]interface{} {
+	for _, status := range append(statuses, http.StatusInternalServerError) {
+		responses[status] = ErrorResponse{}
+	}
+	return responses
+}
+
+func header(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "header", Description: description, Schema: &schema.Schema{Type: "string"}}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
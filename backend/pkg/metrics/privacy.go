@@ -0,0 +1,368 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// ConsentHistoryCollection keeps every version of every athlete's privacy settings
+const ConsentHistoryCollection = "consent_history"
+
+// ErrConsentConflict is returned when privacy settings were changed concurrently
+var ErrConsentConflict = errors.New("privacy settings were changed concurrently")
+
+// locationFields hold the location of a jump, in plaintext or encrypted
+var locationFields = []string{"location", "location_enc"}
+
+// athleteAccess is what access checks need to know about an athlete
+type athleteAccess struct {
+	OwnerID string
+	Minor   bool
+	Privacy PrivacySettings
+}
+
+// guardianID returns the user who consented on behalf of a minor, if any
+func (a athleteAccess) guardianID() string {
+	if a.Privacy.Guardian == nil {
+		return ""
+	}
+	return a.Privacy.Guardian.UserID
+}
+
+// allows reports whether a caller may access the athlete's data: the owning
+// user, the guardian of a minor and admins always, coaches only when
+// allowCoach is set and the athlete granted coach access
+func (a athleteAccess) allows(caller security.Identity, athleteID string, allowCoach bool) bool {
+	userID := caller.UserID
+	switch {
+	case userID != "" && (userID == a.OwnerID || userID == athleteID || userID == a.guardianID()):
+		return true
+	case caller.HasRole("admin"):
+		return true
+	case allowCoach && a.Privacy.CoachAccess && caller.HasRole("coach"):
+		return true
+	}
+	return false
+}
+
+// athleteAccess returns the user owning an athlete and the athlete's consent.
+// Athletes without a profile are owned by the user with the same ID and have
+// not consented to anything.
+func (s *Store) athleteAccess(ctx context.Context, athleteID string) (athleteAccess, error) {
+	var doc struct {
+		UserID  string          `bson:"user_id"`
+		Minor   bool            `bson:"minor"`
+		Age     int             `bson:"age"`
+		Privacy PrivacySettings `bson:"privacy"`
+	}
+	err := s.database.Collection(AthleteProfilesCollection).FindOne(ctx,
+		bson.M{"_id": athleteID},
+		options.FindOne().SetProjection(bson.M{"user_id": 1, "minor": 1, "age": 1, "privacy": 1}),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return athleteAccess{OwnerID: athleteID}, nil
+	}
+	if err != nil {
+		return athleteAccess{}, fmt.Errorf("failed to load privacy settings: %w", err)
+	}
+	if doc.UserID == "" {
+		doc.UserID = athleteID
+	}
+	// Profiles written before minor was derived only carry the age; encrypted
+	// ones are flagged by BackfillMinors
+	minor := doc.Minor || isMinor(doc.Age)
+	return athleteAccess{OwnerID: doc.UserID, Minor: minor, Privacy: doc.Privacy}, nil
+}
+
+// GetPrivacySettings returns the current consent of an athlete
+func (s *Store) GetPrivacySettings(ctx context.Context, athleteID string) (PrivacySettings, error) {
+	access, err := s.athleteAccess(ctx, athleteID)
+	return access.Privacy, err
+}
+
+// UpdatePrivacySettings stores a new version of an athlete's consent and
+// appends it to the consent history. Withdrawing location consent also
+// removes the locations stored so far.
+func (s *Store) UpdatePrivacySettings(ctx context.Context, athleteID string, req UpdatePrivacyRequest, changedBy string) (*PrivacySettings, error) {
+	return s.updatePrivacy(ctx, athleteID, changedBy, func(current PrivacySettings) (PrivacySettings, error) {
+		return PrivacySettings{
+			LocationCapture:    req.LocationCapture,
+			LeaderboardVisible: req.LeaderboardVisible,
+			CoachAccess:        req.CoachAccess,
+			ModelTraining:      req.ModelTraining,
+			Guardian:           current.Guardian,
+		}, nil
+	})
+}
+
+// updatePrivacy applies change to the current consent of an athlete, stores
+// the result as the next version and appends it to the consent history
+func (s *Store) updatePrivacy(ctx context.Context, athleteID, changedBy string,
+	change func(PrivacySettings) (PrivacySettings, error)) (*PrivacySettings, error) {
+	profiles := s.database.Collection(AthleteProfilesCollection)
+
+	var doc struct {
+		Privacy PrivacySettings `bson:"privacy"`
+	}
+	err := profiles.FindOne(ctx, bson.M{"_id": athleteID}, options.FindOne().SetProjection(bson.M{"privacy": 1})).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrProfileNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load privacy settings: %w", err)
+	}
+	current := doc.Privacy
+
+	settings, err := change(current)
+	if err != nil {
+		return nil, err
+	}
+	settings.Version = current.Version + 1
+	settings.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	record := ConsentRecord{
+		ID:        primitive.NewObjectID().Hex(),
+		AthleteID: athleteID,
+		Version:   settings.Version,
+		Settings:  settings,
+		ChangedBy: changedBy,
+		ChangedAt: settings.UpdatedAt,
+	}
+
+	// Profiles created before consent was tracked have no version yet
+	var expectedVersion interface{} = current.Version
+	if current.Version == 0 {
+		expectedVersion = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	session, err := s.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		// The unique (athlete_id, version) index rejects a second writer of the same version
+		if _, err := s.database.Collection(ConsentHistoryCollection).InsertOne(sc, record); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, ErrConsentConflict
+			}
+			return nil, fmt.Errorf("failed to record consent: %w", err)
+		}
+
+		updated, err := profiles.UpdateOne(sc,
+			bson.M{"_id": athleteID, "privacy.version": expectedVersion},
+			bson.M{"$set": bson.M{"privacy": settings}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update privacy settings: %w", err)
+		}
+		if updated.MatchedCount == 0 {
+			return nil, ErrConsentConflict
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if current.LocationCapture && !settings.LocationCapture {
+		if err := s.purgeLocations(ctx, athleteID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.changed(ctx, athleteID)
+	return &settings, nil
+}
+
+// ConsentHistory returns every version of an athlete's privacy settings, newest first
+func (s *Store) ConsentHistory(ctx context.Context, athleteID string) ([]ConsentRecord, error) {
+	cursor, err := s.database.Collection(ConsentHistoryCollection).Find(ctx,
+		bson.M{"athlete_id": athleteID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consent history: %w", err)
+	}
+
+	records := make([]ConsentRecord, 0)
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode consent history: %w", err)
+	}
+	return records, nil
+}
+
+// purgeLocations removes stored locations from an athlete's metrics and sessions
+func (s *Store) purgeLocations(ctx context.Context, athleteID string) error {
+	unsetMetric := bson.M{}
+	unsetJumps := bson.M{}
+	for _, field := range locationFields {
+		unsetMetric[field] = ""
+		unsetJumps["jumps.$[]."+field] = ""
+	}
+
+	_, err := s.database.Collection(MetricsCollection).UpdateMany(ctx,
+		bson.M{"athlete_id": athleteID},
+		bson.M{"$unset": unsetMetric})
+	if err != nil {
+		return fmt.Errorf("failed to remove metric locations: %w", err)
+	}
+
+	_, err = s.database.Collection(SessionsCollection).UpdateMany(ctx,
+		bson.M{"athlete_id": athleteID, "jumps.0": bson.M{"$exists": true}},
+		bson.M{"$unset": unsetJumps})
+	if err != nil {
+		return fmt.Errorf("failed to remove session locations: %w", err)
+	}
+	return nil
+}
+
+// stripLocations drops locations from a submission whose athlete has not
+// consented to location capture
+func stripLocations(req *SubmitRequest) {
+	for i := range req.Metrics {
+		req.Metrics[i].Location = nil
+	}
+	for i := range req.Session.Jumps {
+		req.Session.Jumps[i].Location = nil
+	}
+}
+
+// consentingAthletes returns the athletes who opted in to the given privacy setting
+func (s *Store) consentingAthletes(ctx context.Context, setting string) ([]string, error) {
+	cursor, err := s.database.Collection(AthleteProfilesCollection).Find(ctx,
+		bson.M{"privacy." + setting: true},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consenting athletes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	ids := make([]string, 0)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode athlete profile: %w", err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
+
+// Leaderboard ranks the athletes who opted in to leaderboards by their best
+// jump since the given time. Adults are listed by name; minors are ranked but
+// neither named nor identified.
+func (s *Store) Leaderboard(ctx context.Context, since time.Time, limit int) ([]LeaderboardEntry, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"timestamp":  bson.M{"$gte": since},
+			"anonymized": bson.M{"$ne": true},
+		}},
+		{"$group": bson.M{
+			"_id":        "$athlete_id",
+			"max_height": bson.M{"$max": "$height_cm"},
+			"jumps":      bson.M{"$sum": 1},
+		}},
+		{"$lookup": bson.M{
+			"from":         AthleteProfilesCollection,
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "profile",
+		}},
+		{"$match": bson.M{"profile.privacy.leaderboard_visible": true}},
+		{"$sort": bson.D{{Key: "max_height", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+		{"$project": bson.M{
+			"max_height": 1,
+			"jumps":      1,
+			"profile":    bson.M{"$arrayElemAt": bson.A{"$profile", 0}},
+		}},
+	}
+
+	cursor, err := s.database.Collection(MetricsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate leaderboard: %w", err)
+	}
+
+	var results []struct {
+		AthleteID string               `bson:"_id"`
+		MaxHeight float64              `bson:"max_height"`
+		Jumps     int                  `bson:"jumps"`
+		Profile   storedAthleteProfile `bson:"profile"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode leaderboard: %w", err)
+	}
+
+	entries := make([]LeaderboardEntry, len(results))
+	for i, result := range results {
+		entries[i] = LeaderboardEntry{
+			Rank:       i + 1,
+			SportLevel: result.Profile.SportLevel,
+			MaxHeight:  result.MaxHeight,
+			Jumps:      result.Jumps,
+		}
+
+		profile, err := s.decodeProfile(result.Profile)
+		if err != nil {
+			return nil, err
+		}
+		if profile.Minor || isMinor(profile.Age) {
+			continue
+		}
+		entries[i].AthleteID = result.AthleteID
+		entries[i].Name = profile.Name
+	}
+	return entries, nil
+}
+
+// ExportTrainingData streams the metrics of athletes who consented to model
+// training, oldest first. Location, notes and device are never exported.
+func (s *Store) ExportTrainingData(ctx context.Context, since time.Time, fn func(JumpMetric) error) error {
+	ids, err := s.consentingAthletes(ctx, "model_training")
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	projection := bson.M{}
+	for _, field := range personalMetricFields {
+		projection[field] = 0
+	}
+	cursor, err := s.database.Collection(MetricsCollection).Find(ctx,
+		bson.M{
+			"athlete_id": bson.M{"$in": ids},
+			"timestamp":  bson.M{"$gte": since},
+			"anonymized": bson.M{"$ne": true},
+		},
+		options.Find().
+			SetSort(bson.D{{Key: "timestamp", Value: 1}}).
+			SetProjection(projection))
+	if err != nil {
+		return fmt.Errorf("failed to find training metrics: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var metric JumpMetric
+		if err := cursor.Decode(&metric); err != nil {
+			return fmt.Errorf("failed to decode training metric: %w", err)
+		}
+		if err := fn(metric); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
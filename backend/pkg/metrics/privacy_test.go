@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/security"
+)
+
+// insertedMetrics returns the metric documents the store inserted
+func insertedMetrics(mt *mtest.T) []bson.Raw {
+	var docs []bson.Raw
+	for _, event := range mt.GetAllStartedEvents() {
+		if event.CommandName != "insert" || event.Command.Lookup("insert").StringValue() != MetricsCollection {
+			continue
+		}
+		values, err := event.Command.Lookup("documents").Array().Values()
+		require.NoError(mt, err)
+		for _, value := range values {
+			docs = append(docs, value.Document())
+		}
+	}
+	return docs
+}
+
+func TestAthleteAccess_Allows(t *testing.T) {
+	access := athleteAccess{OwnerID: "user-1", Privacy: PrivacySettings{CoachAccess: true}}
+
+	assert.True(t, access.allows(security.Identity{UserID: "user-1"}, "athlete-1", false))
+	assert.True(t, access.allows(security.Identity{UserID: "admin-1", Roles: []string{"admin"}}, "athlete-1", false))
+	assert.False(t, access.allows(security.Identity{UserID: "user-2"}, "athlete-1", true))
+	assert.False(t, access.allows(security.Identity{}, "athlete-1", true))
+
+	// Coaches read, but only where the athlete granted it
+	coach := security.Identity{UserID: "coach-1", Roles: []string{"coach"}}
+	assert.True(t, access.allows(coach, "athlete-1", true))
+	assert.False(t, access.allows(coach, "athlete-1", false))
+	access.Privacy.CoachAccess = false
+	assert.False(t, access.allows(coach, "athlete-1", true))
+}
+
+func TestStore_SubmitStripsLocationsWithoutConsent(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	submit := func(mt *mtest.T, privacy bson.D) []bson.Raw {
+		s := newTestStore(mt)
+		mt.AddMockResponses(findResponse(AthleteProfilesCollection, bson.D{
+			{Key: "_id", Value: "athlete-1"},
+			{Key: "privacy", Value: privacy},
+		}))
+		mt.AddMockResponses(submitResponses()...)
+
+		req := testSubmitRequest("athlete-1")
+		req.Metrics[0].Location = &Location{Latitude: 43.2, Longitude: 76.9}
+		req.Session.Jumps = []JumpMetric{req.Metrics[0]}
+		require.NoError(mt, s.Submit(context.Background(), &req))
+		return insertedMetrics(mt)
+	}
+
+	mt.Run("without consent", func(mt *mtest.T) {
+		docs := submit(mt, bson.D{{Key: "location_capture", Value: false}})
+		require.Len(mt, docs, 1)
+		_, err := docs[0].LookupErr("location")
+		assert.Error(mt, err)
+		assert.Equal(mt, 61.5, docs[0].Lookup("height_cm").Double())
+	})
+
+	mt.Run("with consent", func(mt *mtest.T) {
+		docs := submit(mt, bson.D{{Key: "location_capture", Value: true}})
+		require.Len(mt, docs, 1)
+		assert.Equal(mt, 43.2, docs[0].Lookup("location", "latitude").Double())
+	})
+}
+
+func TestStripLocations(t *testing.T) {
+	location := &Location{Latitude: 43.2, Longitude: 76.9}
+	req := &SubmitRequest{
+		Session: JumpSession{Jumps: []JumpMetric{{Location: location}}},
+		Metrics: []JumpMetric{{Location: location, HeightCm: 61.5}, {Location: location}},
+	}
+
+	stripLocations(req)
+	for _, metric := range append(req.Metrics, req.Session.Jumps...) {
+		assert.Nil(t, metric.Location)
+	}
+	assert.Equal(t, 61.5, req.Metrics[0].HeightCm)
+}
+
+func TestStore_Leaderboard(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("ranks athletes who opted in", func(mt *mtest.T) {
+		s := newTestStore(mt)
+		mt.AddMockResponses(findResponse(MetricsCollection,
+			bson.D{
+				{Key: "_id", Value: "athlete-1"},
+				{Key: "max_height", Value: 72.5},
+				{Key: "jumps", Value: 12},
+				{Key: "profile", Value: bson.D{{Key: "_id", Value: "athlete-1"}, {Key: "name", Value: "Jordan"}, {Key: "sport_level", Value: "pro"}}},
+			},
+			bson.D{
+				{Key: "_id", Value: "athlete-2"},
+				{Key: "max_height", Value: 64.0},
+				{Key: "jumps", Value: 3},
+				{Key: "profile", Value: bson.D{{Key: "_id", Value: "athlete-2"}, {Key: "name", Value: "Sam"}}},
+			},
+		))
+
+		entries, err := s.Leaderboard(context.Background(), time.Now().AddDate(0, 0, -7), 10)
+		require.NoError(mt, err)
+		assert.Equal(mt, []LeaderboardEntry{
+			{Rank: 1, AthleteID: "athlete-1", Name: "Jordan", SportLevel: "pro", MaxHeight: 72.5, Jumps: 12},
+			{Rank: 2, AthleteID: "athlete-2", Name: "Sam", MaxHeight: 64.0, Jumps: 3},
+		}, entries)
+
+		// Only athletes who opted in are ranked, and anonymized jumps never count
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline").String()
+		assert.Contains(mt, pipeline, `"profile.privacy.leaderboard_visible": true`)
+		assert.Contains(mt, pipeline, `"anonymized": {"$ne": true}`)
+	})
+}
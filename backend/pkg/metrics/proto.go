@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	metricsv1 "github.com/Danchouvzv/DunkSense/backend/proto/metrics/v1"
+)
+
+// Conversions between the models and their protobuf messages. Zero times are
+// sent as unset timestamps and unset timestamps read as zero times.
+
+func timestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func jumpMetricToProto(m JumpMetric) *metricsv1.JumpMetric {
+	pb := &metricsv1.JumpMetric{
+		Id:                 m.ID,
+		AthleteId:          m.AthleteID,
+		SessionId:          m.SessionID,
+		Timestamp:          timestampToProto(m.Timestamp),
+		HeightCm:           m.HeightCm,
+		ContactTimeMs:      int32(m.ContactTimeMs),
+		FlightTimeMs:       int32(m.FlightTimeMs),
+		TakeoffVelocityMps: m.TakeoffVelocity,
+		LandingForceN:      m.LandingForce,
+		ValgusAngleDeg:     m.ValgusAngleDeg,
+		KneeFlexionDeg:     m.KneeFlexionDeg,
+		HipFlexionDeg:      m.HipFlexionDeg,
+		TakeoffScore:       int32(m.TakeoffScore),
+		LandingScore:       int32(m.LandingScore),
+		OverallScore:       int32(m.OverallScore),
+		SymmetryScore:      int32(m.SymmetryScore),
+		DeviceType:         m.DeviceType,
+		AppVersion:         m.AppVersion,
+		ProcessingTimeMs:   int32(m.ProcessingTimeMs),
+		Confidence:         m.Confidence,
+		DeviceId:           m.DeviceID,
+		DeviceVerified:     m.DeviceVerified,
+		Notes:              m.Notes,
+	}
+	if m.Location != nil {
+		pb.Location = &metricsv1.Location{Latitude: m.Location.Latitude, Longitude: m.Location.Longitude, Altitude: m.Location.Altitude}
+	}
+	if m.Weather != nil {
+		pb.Weather = &metricsv1.Weather{Temperature: m.Weather.Temperature, Humidity: m.Weather.Humidity, Pressure: m.Weather.Pressure}
+	}
+	for _, frame := range m.Pose {
+		pbFrame := &metricsv1.PoseFrame{TimeMs: frame.TimeMs, Confidence: frame.Confidence, Keypoints: make(map[string]*metricsv1.Keypoint, len(frame.Keypoints))}
+		for joint, keypoint := range frame.Keypoints {
+			pbFrame.Keypoints[joint] = &metricsv1.Keypoint{X: keypoint.X, Y: keypoint.Y, Confidence: keypoint.Confidence}
+		}
+		pb.Pose = append(pb.Pose, pbFrame)
+	}
+	return pb
+}
+
+// jumpMetricFromProto converts a submitted jump. Device provenance is left to
+// the server.
+func jumpMetricFromProto(pb *metricsv1.JumpMetric) JumpMetric {
+	m := JumpMetric{
+		ID:               pb.GetId(),
+		AthleteID:        pb.GetAthleteId(),
+		SessionID:        pb.GetSessionId(),
+		Timestamp:        timestampFromProto(pb.GetTimestamp()),
+		HeightCm:         pb.GetHeightCm(),
+		ContactTimeMs:    int(pb.GetContactTimeMs()),
+		FlightTimeMs:     int(pb.GetFlightTimeMs()),
+		TakeoffVelocity:  pb.GetTakeoffVelocityMps(),
+		LandingForce:     pb.GetLandingForceN(),
+		ValgusAngleDeg:   pb.GetValgusAngleDeg(),
+		KneeFlexionDeg:   pb.GetKneeFlexionDeg(),
+		HipFlexionDeg:    pb.GetHipFlexionDeg(),
+		TakeoffScore:     int(pb.GetTakeoffScore()),
+		LandingScore:     int(pb.GetLandingScore()),
+		OverallScore:     int(pb.GetOverallScore()),
+		SymmetryScore:    int(pb.GetSymmetryScore()),
+		DeviceType:       pb.GetDeviceType(),
+		AppVersion:       pb.GetAppVersion(),
+		ProcessingTimeMs: int(pb.GetProcessingTimeMs()),
+		Confidence:       pb.GetConfidence(),
+		Notes:            pb.GetNotes(),
+	}
+	if location := pb.GetLocation(); location != nil {
+		m.Location = &Location{Latitude: location.Latitude, Longitude: location.Longitude, Altitude: location.Altitude}
+	}
+	if weather := pb.GetWeather(); weather != nil {
+		m.Weather = &Weather{Temperature: weather.Temperature, Humidity: weather.Humidity, Pressure: weather.Pressure}
+	}
+	for _, pbFrame := range pb.GetPose() {
+		frame := PoseFrame{TimeMs: pbFrame.GetTimeMs(), Confidence: pbFrame.GetConfidence(), Keypoints: make(map[string]Keypoint, len(pbFrame.GetKeypoints()))}
+		for joint, keypoint := range pbFrame.GetKeypoints() {
+			frame.Keypoints[joint] = Keypoint{X: keypoint.GetX(), Y: keypoint.GetY(), Confidence: keypoint.GetConfidence()}
+		}
+		m.Pose = append(m.Pose, frame)
+	}
+	return m
+}
+
+func jumpSessionFromProto(pb *metricsv1.JumpSession) JumpSession {
+	session := JumpSession{
+		ID:        pb.GetId(),
+		AthleteID: pb.GetAthleteId(),
+		StartTime: timestampFromProto(pb.GetStartTime()),
+		EndTime:   timestampFromProto(pb.GetEndTime()),
+		Duration:  int(pb.GetDurationSeconds()),
+		JumpCount: int(pb.GetJumpCount()),
+		MaxHeight: pb.GetMaxHeightCm(),
+		AvgHeight: pb.GetAvgHeightCm(),
+		LoadScore: int(pb.GetLoadScore()),
+		RPE:       int(pb.GetRpe()),
+	}
+	for _, jump := range pb.GetJumps() {
+		session.Jumps = append(session.Jumps, jumpMetricFromProto(jump))
+	}
+	return session
+}
+
+func submitRequestFromProto(pb *metricsv1.SubmitMetricsRequest) SubmitRequest {
+	req := SubmitRequest{
+		AthleteID: pb.GetAthleteId(),
+		Session:   jumpSessionFromProto(pb.GetSession()),
+	}
+	for _, metric := range pb.GetMetrics() {
+		req.Metrics = append(req.Metrics, jumpMetricFromProto(metric))
+	}
+	return req
+}
+
+func summaryToProto(s MetricsSummary) *metricsv1.MetricsSummary {
+	return &metricsv1.MetricsSummary{
+		AthleteId:           s.AthleteID,
+		Period:              s.Period,
+		StartDate:           timestampToProto(s.StartDate),
+		EndDate:             timestampToProto(s.EndDate),
+		TotalJumps:          int32(s.TotalJumps),
+		TotalSessions:       int32(s.TotalSessions),
+		MaxHeightCm:         s.MaxHeight,
+		AvgHeightCm:         s.AvgHeight,
+		HeightImprovementCm: s.HeightImprovement,
+		AvgTakeoffScore:     int32(s.AvgTakeoffScore),
+		AvgLandingScore:     int32(s.AvgLandingScore),
+		AvgOverallScore:     int32(s.AvgOverallScore),
+		AvgValgusAngleDeg:   s.AvgValgusAngle,
+		MaxValgusAngleDeg:   s.MaxValgusAngle,
+		RiskScore:           int32(s.RiskScore),
+		TotalLoadScore:      int32(s.TotalLoadScore),
+		AvgRpe:              s.AvgRPE,
+		HeightTrend:         s.HeightTrend,
+		TechniqueTrend:      s.TechniqueTrend,
+		LoadTrend:           s.LoadTrend,
+	}
+}
+
+func privacyToProto(p PrivacySettings) *metricsv1.PrivacySettings {
+	pb := &metricsv1.PrivacySettings{
+		LocationCapture:    p.LocationCapture,
+		LeaderboardVisible: p.LeaderboardVisible,
+		CoachAccess:        p.CoachAccess,
+		ModelTraining:      p.ModelTraining,
+		Version:            int32(p.Version),
+		UpdatedAt:          timestampToProto(p.UpdatedAt),
+	}
+	if p.Guardian != nil {
+		pb.Guardian = &metricsv1.GuardianConsent{UserId: p.Guardian.UserID, ConsentedAt: timestampToProto(p.Guardian.ConsentedAt)}
+	}
+	return pb
+}
+
+func athleteProfileToProto(p AthleteProfile) *metricsv1.AthleteProfile {
+	return &metricsv1.AthleteProfile{
+		Id:                   p.ID,
+		UserId:               p.UserID,
+		Name:                 p.Name,
+		Age:                  int32(p.Age),
+		HeightCm:             int32(p.Height),
+		WeightKg:             p.Weight,
+		SportLevel:           p.SportLevel,
+		Minor:                p.Minor,
+		CreatedAt:            timestampToProto(p.CreatedAt),
+		UpdatedAt:            timestampToProto(p.UpdatedAt),
+		MaxJumpHeightCm:      p.MaxJumpHeight,
+		AvgJumpHeightCm:      p.AvgJumpHeight,
+		BestContactTimeMs:    int32(p.BestContactTime),
+		Rsi:                  p.RSI,
+		Goals:                p.Goals,
+		TrainingDays:         p.TrainingDays,
+		PreferredDurationMin: int32(p.PreferredDuration),
+		Privacy:              privacyToProto(p.Privacy),
+	}
+}
+
+func leaderboardEntryToProto(e LeaderboardEntry) *metricsv1.LeaderboardEntry {
+	return &metricsv1.LeaderboardEntry{
+		Rank:        int32(e.Rank),
+		AthleteId:   e.AthleteID,
+		Name:        e.Name,
+		SportLevel:  e.SportLevel,
+		MaxHeightCm: e.MaxHeight,
+		Jumps:       int32(e.Jumps),
+	}
+}
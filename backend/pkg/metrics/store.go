@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,8 +10,16 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/encryption"
 )
 
+// ErrProfileNotFound is returned when an athlete has no profile
+var ErrProfileNotFound = errors.New("athlete profile not found")
+
+// ErrSessionNotFound is returned when an athlete has no session with the given ID
+var ErrSessionNotFound = errors.New("session not found")
+
 const (
 	DatabaseName           = "dunksense"
 	MetricsCollection      = "jump_metrics"
@@ -20,8 +29,10 @@ const (
 
 // Store handles all metrics-related database operations
 type Store struct {
-	client   *mongo.Client
-	database *mongo.Database
+	client    *mongo.Client
+	database  *mongo.Database
+	encryptor *encryption.FieldEncryptor
+	onChanged []func(ctx context.Context, athleteID string) error
 }
 
 // NewStore creates a new metrics store
@@ -106,6 +117,27 @@ func (s *Store) createIndexes() error {
 		return fmt.Errorf("failed to create sessions indexes: %w", err)
 	}
 
+	// One consent record per athlete and version
+	_, err = s.database.Collection(ConsentHistoryCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "athlete_id", Value: 1},
+			{Key: "version", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create consent history indexes: %w", err)
+	}
+
+	// Guardian lookups
+	_, err = s.database.Collection(AthleteProfilesCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "privacy.guardian.user_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "guardian_invite.code_hash", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create athlete profile indexes: %w", err)
+	}
+
 	return nil
 }
 
@@ -115,6 +147,19 @@ func (s *Store) Submit(ctx context.Context, req *SubmitRequest) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Minors need guardian consent, and locations are only kept for athletes
+	// who consented to it
+	access, err := s.athleteAccess(ctx, req.AthleteID)
+	if err != nil {
+		return err
+	}
+	if access.Minor && access.guardianID() == "" {
+		return ErrGuardianConsentRequired
+	}
+	if !access.Privacy.LocationCapture {
+		stripLocations(req)
+	}
+
 	// Start a transaction
 	session, err := s.client.StartSession()
 	if err != nil {
@@ -131,7 +176,12 @@ func (s *Store) Submit(ctx context.Context, req *SubmitRequest) error {
 			req.Session.ID = primitive.NewObjectID().Hex()
 		}
 
-		_, err := sessionsCollection.InsertOne(sc, req.Session)
+		sessionDoc, err := s.encodeSession(req.Session)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = sessionsCollection.InsertOne(sc, sessionDoc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert session: %w", err)
 		}
@@ -149,7 +199,12 @@ func (s *Store) Submit(ctx context.Context, req *SubmitRequest) error {
 				}
 				// Set session ID
 				metric.SessionID = req.Session.ID
-				docs[i] = metric
+
+				doc, err := s.encodeMetric(metric)
+				if err != nil {
+					return nil, err
+				}
+				docs[i] = doc
 			}
 
 			_, err := metricsCollection.InsertMany(sc, docs)
@@ -165,8 +220,28 @@ func (s *Store) Submit(ctx context.Context, req *SubmitRequest) error {
 
 		return nil, nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.changed(ctx, req.AthleteID)
+	return nil
+}
+
+// OnAthleteChanged registers a hook that runs after the metrics or privacy
+// settings of an athlete changed, e.g. to invalidate cached responses
+func (s *Store) OnAthleteChanged(hook func(ctx context.Context, athleteID string) error) {
+	s.onChanged = append(s.onChanged, hook)
+}
 
-	return err
+// changed runs the change hooks. The write already succeeded, so hook errors
+// are not returned; hooks report their own failures.
+func (s *Store) changed(ctx context.Context, athleteIDs ...string) {
+	for _, athleteID := range athleteIDs {
+		for _, hook := range s.onChanged {
+			hook(ctx, athleteID)
+		}
+	}
 }
 
 // GetByAthleteID retrieves metrics for a specific athlete
@@ -202,11 +277,20 @@ func (s *Store) GetByAthleteID(ctx context.Context, athleteID string) (*GetMetri
 	}
 	defer cursor.Close(ctx)
 
-	var metrics []JumpMetric
-	if err := cursor.All(ctx, &metrics); err != nil {
+	var docs []storedJumpMetric
+	if err := cursor.All(ctx, &docs); err != nil {
 		return nil, fmt.Errorf("failed to decode metrics: %w", err)
 	}
 
+	metrics := make([]JumpMetric, 0, len(docs))
+	for _, doc := range docs {
+		metric, err := s.decodeMetric(doc)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+
 	// Generate summary
 	summary, err := s.generateSummary(ctx, athleteID, startDate, endDate)
 	if err != nil {
@@ -221,6 +305,43 @@ func (s *Store) GetByAthleteID(ctx context.Context, athleteID string) (*GetMetri
 	}, nil
 }
 
+// SessionJumps calls fn with every jump of an athlete's session, in the order
+// they were made, without loading the whole session at once
+func (s *Store) SessionJumps(ctx context.Context, athleteID, sessionID string, fn func(JumpMetric) error) error {
+	count, err := s.database.Collection(SessionsCollection).CountDocuments(ctx,
+		bson.M{"_id": sessionID, "athlete_id": athleteID},
+		options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to find session: %w", err)
+	}
+	if count == 0 {
+		return ErrSessionNotFound
+	}
+
+	cursor, err := s.database.Collection(MetricsCollection).Find(ctx,
+		bson.M{"athlete_id": athleteID, "session_id": sessionID},
+		options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	if err != nil {
+		return fmt.Errorf("failed to find session jumps: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc storedJumpMetric
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("failed to decode session jump: %w", err)
+		}
+		metric, err := s.decodeMetric(doc)
+		if err != nil {
+			return err
+		}
+		if err := fn(metric); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 // GetSummary retrieves aggregated metrics summary for an athlete
 func (s *Store) GetSummary(ctx context.Context, athleteID string) (*MetricsSummary, error) {
 	// Default to last 7 days
@@ -352,6 +473,67 @@ func (s *Store) updateAthleteProfile(ctx context.Context, athleteID string, metr
 	return nil
 }
 
+// SaveAthleteProfile creates or updates an athlete profile.
+// Privacy settings are kept as stored; change them with UpdatePrivacySettings.
+// Performance baselines are maintained by Submit and kept as stored too.
+func (s *Store) SaveAthleteProfile(ctx context.Context, profile *AthleteProfile) error {
+	if profile.ID == "" {
+		profile.ID = primitive.NewObjectID().Hex()
+	}
+	profile.Minor = isMinor(profile.Age)
+
+	now := time.Now()
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = now
+	}
+	profile.UpdatedAt = now
+
+	encoded, err := s.encodeProfile(*profile)
+	if err != nil {
+		return err
+	}
+	data, err := bson.Marshal(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode athlete profile: %w", err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to encode athlete profile: %w", err)
+	}
+	delete(doc, "_id")
+	delete(doc, "privacy")
+	for _, field := range []string{"max_jump_height_cm", "avg_jump_height_cm", "best_contact_time_ms", "rsi"} {
+		delete(doc, field)
+	}
+
+	collection := s.database.Collection(AthleteProfilesCollection)
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": profile.ID}, bson.M{"$set": doc}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save athlete profile: %w", err)
+	}
+
+	return nil
+}
+
+// GetAthleteProfile retrieves an athlete profile
+func (s *Store) GetAthleteProfile(ctx context.Context, athleteID string) (*AthleteProfile, error) {
+	collection := s.database.Collection(AthleteProfilesCollection)
+
+	var doc storedAthleteProfile
+	if err := collection.FindOne(ctx, bson.M{"_id": athleteID}).Decode(&doc); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrProfileNotFound
+		}
+		return nil, fmt.Errorf("failed to find athlete profile: %w", err)
+	}
+
+	profile, err := s.decodeProfile(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
 // calculateRiskScore calculates injury risk score based on biomechanical data
 func (s *Store) calculateRiskScore(avgValgus, maxValgus float64) int {
 	// Simplified risk calculation
@@ -393,6 +575,10 @@ func (s *Store) validateSubmitRequest(req *SubmitRequest) error {
 		return fmt.Errorf("session athlete_id must match request athlete_id")
 	}
 
+	if len(req.Metrics) > MaxSubmitMetrics {
+		return fmt.Errorf("at most %d metrics can be submitted at once", MaxSubmitMetrics)
+	}
+
 	for i, metric := range req.Metrics {
 		if metric.AthleteID == "" {
 			req.Metrics[i].AthleteID = req.AthleteID
@@ -414,6 +600,11 @@ func (s *Store) validateSubmitRequest(req *SubmitRequest) error {
 	return nil
 }
 
+// Database returns the underlying database, e.g. for collections owned by other packages
+func (s *Store) Database() *mongo.Database {
+	return s.database
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	return s.client.Disconnect(context.Background())
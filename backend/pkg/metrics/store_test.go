@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// newTestStore returns a store on the mock deployment of mt. Every database
+// call consumes the next response added with mt.AddMockResponses.
+func newTestStore(mt *mtest.T) *Store {
+	return &Store{client: mt.Client, database: mt.DB}
+}
+
+// findResponse answers a Find or FindOne on collection with docs
+func findResponse(collection string, docs ...bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "test."+collection, mtest.FirstBatch, docs...)
+}
+
+// submitResponses answer the writes of a Submit that passed the access checks
+func submitResponses() []bson.D {
+	return []bson.D{
+		mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),                                     // session
+		mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),                                     // metrics
+		mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}), // profile
+		mtest.CreateSuccessResponse(),                                                               // commit
+	}
+}
@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/apiversion"
+)
+
+// API versions of the routes of RegisterRoutes. Version 1 is the schema of app
+// builds released before the API was versioned, which upload and decode jumps
+// in the app's own model, see jumpMetricV1. Version 2 is the models of this
+// package.
+const (
+	APIVersion1 apiversion.Version = 1
+	APIVersion2 apiversion.Version = 2
+)
+
+// apiVersion1Deprecated is when version 2 was released
+var apiVersion1Deprecated = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+
+// appleReferenceDate is the epoch of dates encoded by Swift's JSONEncoder
+var appleReferenceDate = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// submittedJumpKey is the Gin context key of the jump a version 1 client
+// uploaded, which it expects back
+const submittedJumpKey = "metrics_submitted_jump_v1"
+
+// APIVersions configures the versions of the routes of RegisterRoutes mounted
+// at base, e.g. /api/v1. Requests without an API-Version header are served as
+// version 1, so app builds that never send one keep working; new clients send
+// API-Version: 2. Version 1 is deprecated but no sunset is scheduled while
+// those builds are in use.
+func APIVersions(base string) apiversion.Config {
+	return apiversion.Config{
+		Latest:  APIVersion2,
+		Default: APIVersion1,
+		Deprecated: map[apiversion.Version]apiversion.Deprecation{
+			APIVersion1: {Since: apiVersion1Deprecated},
+		},
+		Changes: map[apiversion.Version][]apiversion.Change{
+			APIVersion2: {
+				{
+					Method:    http.MethodPost,
+					Path:      base + "/metrics",
+					Upgrade:   upgradeSubmitV1,
+					Downgrade: downgradeSubmitV1,
+				},
+				{
+					Method:    http.MethodGet,
+					Path:      base + "/athletes/:athlete_id/metrics",
+					Downgrade: downgradeMetricsV1,
+				},
+			},
+		},
+	}
+}
+
+// jumpMetricV1 is a jump as the app's JumpMetric model encodes it: camelCase
+// fields, durations in seconds, scores from 0 to 1 and the timestamp in
+// seconds since 2001-01-01, the default of Swift's JSONEncoder
+type jumpMetricV1 struct {
+	ID              string       `json:"id"`
+	AthleteID       string       `json:"athleteId"`
+	Timestamp       float64      `json:"timestamp"`
+	JumpHeight      float64      `json:"jumpHeight"`      // cm
+	ContactTime     float64      `json:"contactTime"`     // s
+	FlightTime      float64      `json:"flightTime"`      // s
+	TakeoffVelocity float64      `json:"takeoffVelocity"` // m/s
+	LandingForce    float64      `json:"landingForce"`    // N
+	SymmetryScore   float64      `json:"symmetryScore"`
+	TechniqueScore  float64      `json:"techniqueScore"`
+	VideoURL        *string      `json:"videoURL,omitempty"` // a file on the device, not stored
+	PoseData        []poseDataV1 `json:"poseData"`
+}
+
+// poseDataV1 is a pose frame of the app's PoseData model
+type poseDataV1 struct {
+	Timestamp  float64               `json:"timestamp"` // s
+	Keypoints  map[string]keypointV1 `json:"keypoints"`
+	Confidence float64               `json:"confidence"`
+}
+
+type keypointV1 struct {
+	Location   [2]float64 `json:"location"` // a CGPoint, encoded as [x, y]
+	Confidence float64    `json:"confidence"`
+}
+
+// upgradeSubmitV1 stores a jump uploaded by version 1 as a session of that
+// one jump
+func upgradeSubmitV1(c *gin.Context, body []byte) ([]byte, error) {
+	var jump jumpMetricV1
+	if err := json.Unmarshal(body, &jump); err != nil {
+		return nil, err
+	}
+	if jump.ID == "" {
+		jump.ID = primitive.NewObjectID().Hex()
+	}
+	c.Set(submittedJumpKey, jump)
+
+	metric := jump.toModel()
+	return json.Marshal(SubmitRequest{
+		AthleteID: metric.AthleteID,
+		Session: JumpSession{
+			AthleteID: metric.AthleteID,
+			StartTime: metric.Timestamp,
+			EndTime:   metric.Timestamp,
+			JumpCount: 1,
+			MaxHeight: metric.HeightCm,
+			AvgHeight: metric.HeightCm,
+		},
+		Metrics: []JumpMetric{metric},
+	})
+}
+
+// downgradeSubmitV1 answers a version 1 upload with the saved jump
+func downgradeSubmitV1(c *gin.Context, body []byte) ([]byte, error) {
+	value, _ := c.Get(submittedJumpKey)
+	jump, ok := value.(jumpMetricV1)
+	if !ok {
+		return nil, errors.New("submitted jump not found")
+	}
+	return json.Marshal(jump)
+}
+
+// downgradeMetricsV1 lists an athlete's metrics as the bare array of jumps
+// version 1 decodes
+func downgradeMetricsV1(c *gin.Context, body []byte) ([]byte, error) {
+	var resp GetMetricsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	jumps := make([]jumpMetricV1, len(resp.Metrics))
+	for i, metric := range resp.Metrics {
+		jumps[i] = jumpMetricV1FromModel(metric)
+	}
+	return json.Marshal(jumps)
+}
+
+func (j jumpMetricV1) toModel() JumpMetric {
+	metric := JumpMetric{
+		ID:              j.ID,
+		AthleteID:       j.AthleteID,
+		Timestamp:       appleReferenceDate.Add(time.Duration(j.Timestamp * float64(time.Second))),
+		HeightCm:        j.JumpHeight,
+		ContactTimeMs:   int(secondsToMs(j.ContactTime)),
+		FlightTimeMs:    int(secondsToMs(j.FlightTime)),
+		TakeoffVelocity: j.TakeoffVelocity,
+		LandingForce:    j.LandingForce,
+		OverallScore:    fractionToScore(j.TechniqueScore),
+		SymmetryScore:   fractionToScore(j.SymmetryScore),
+	}
+	for _, pose := range j.PoseData {
+		frame := PoseFrame{
+			TimeMs:     secondsToMs(pose.Timestamp),
+			Keypoints:  make(map[string]Keypoint, len(pose.Keypoints)),
+			Confidence: pose.Confidence,
+		}
+		for joint, keypoint := range pose.Keypoints {
+			frame.Keypoints[joint] = Keypoint{X: keypoint.Location[0], Y: keypoint.Location[1], Confidence: keypoint.Confidence}
+		}
+		metric.Pose = append(metric.Pose, frame)
+	}
+	return metric
+}
+
+func jumpMetricV1FromModel(m JumpMetric) jumpMetricV1 {
+	jump := jumpMetricV1{
+		ID:              m.ID,
+		AthleteID:       m.AthleteID,
+		Timestamp:       m.Timestamp.Sub(appleReferenceDate).Seconds(),
+		JumpHeight:      m.HeightCm,
+		ContactTime:     float64(m.ContactTimeMs) / 1000,
+		FlightTime:      float64(m.FlightTimeMs) / 1000,
+		TakeoffVelocity: m.TakeoffVelocity,
+		LandingForce:    m.LandingForce,
+		TechniqueScore:  float64(m.OverallScore) / 100,
+		SymmetryScore:   float64(m.SymmetryScore) / 100,
+		PoseData:        make([]poseDataV1, len(m.Pose)), // the app cannot decode null
+	}
+	for i, frame := range m.Pose {
+		pose := poseDataV1{
+			Timestamp:  float64(frame.TimeMs) / 1000,
+			Keypoints:  make(map[string]keypointV1, len(frame.Keypoints)),
+			Confidence: frame.Confidence,
+		}
+		for joint, keypoint := range frame.Keypoints {
+			pose.Keypoints[joint] = keypointV1{Location: [2]float64{keypoint.X, keypoint.Y}, Confidence: keypoint.Confidence}
+		}
+		jump.PoseData[i] = pose
+	}
+	return jump
+}
+
+func secondsToMs(seconds float64) int64 {
+	return int64(math.Round(seconds * 1000))
+}
+
+// fractionToScore converts a score from 0 to 1 to the 0-100 scores of the models
+func fractionToScore(fraction float64) int {
+	return int(math.Round(fraction * 100))
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJumpV1 is a jump as the app uploads it, 2026-10-18 12:00:00 UTC
+func testJumpV1() jumpMetricV1 {
+	return jumpMetricV1{
+		ID:              "jump-1",
+		AthleteID:       "athlete-1",
+		Timestamp:       814017600.25,
+		JumpHeight:      61.5,
+		ContactTime:     0.2184,
+		FlightTime:      0.708,
+		TakeoffVelocity: 3.47,
+		LandingForce:    2150,
+		SymmetryScore:   0.914,
+		TechniqueScore:  0.825,
+		PoseData: []poseDataV1{{
+			Timestamp:  1.5,
+			Keypoints:  map[string]keypointV1{"left_knee": {Location: [2]float64{0.42, 0.71}, Confidence: 0.93}},
+			Confidence: 0.88,
+		}},
+	}
+}
+
+func TestJumpMetricV1_ToModel(t *testing.T) {
+	metric := testJumpV1().toModel()
+
+	assert.Equal(t, "jump-1", metric.ID)
+	assert.Equal(t, "athlete-1", metric.AthleteID)
+	assert.Equal(t, time.Date(2026, time.October, 18, 12, 0, 0, 250_000_000, time.UTC), metric.Timestamp.UTC())
+	assert.Equal(t, 61.5, metric.HeightCm)
+	// Seconds become rounded milliseconds
+	assert.Equal(t, 218, metric.ContactTimeMs)
+	assert.Equal(t, 708, metric.FlightTimeMs)
+	assert.Equal(t, 3.47, metric.TakeoffVelocity)
+	assert.Equal(t, 2150.0, metric.LandingForce)
+	// Fractions become scores out of 100
+	assert.Equal(t, 83, metric.OverallScore)
+	assert.Equal(t, 91, metric.SymmetryScore)
+
+	require.Len(t, metric.Pose, 1)
+	assert.Equal(t, int64(1500), metric.Pose[0].TimeMs)
+	assert.Equal(t, 0.88, metric.Pose[0].Confidence)
+	assert.Equal(t, map[string]Keypoint{"left_knee": {X: 0.42, Y: 0.71, Confidence: 0.93}}, metric.Pose[0].Keypoints)
+}
+
+func TestJumpMetricV1_FromModel(t *testing.T) {
+	jump := jumpMetricV1FromModel(testJumpV1().toModel())
+
+	assert.Equal(t, "jump-1", jump.ID)
+	assert.Equal(t, "athlete-1", jump.AthleteID)
+	assert.InDelta(t, 814017600.25, jump.Timestamp, 1e-6)
+	assert.Equal(t, 61.5, jump.JumpHeight)
+	assert.Equal(t, 0.218, jump.ContactTime)
+	assert.Equal(t, 0.708, jump.FlightTime)
+	assert.Equal(t, 0.83, jump.TechniqueScore)
+	assert.Equal(t, 0.91, jump.SymmetryScore)
+	assert.Nil(t, jump.VideoURL)
+	assert.Equal(t, []poseDataV1{{
+		Timestamp:  1.5,
+		Keypoints:  map[string]keypointV1{"left_knee": {Location: [2]float64{0.42, 0.71}, Confidence: 0.93}},
+		Confidence: 0.88,
+	}}, jump.PoseData)
+
+	// The app cannot decode a null pose
+	data, err := json.Marshal(jumpMetricV1FromModel(JumpMetric{Timestamp: appleReferenceDate}))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"poseData":[]`)
+	assert.Contains(t, string(data), `"timestamp":0`)
+}
+
+func TestUpgradeSubmitV1(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	body, err := json.Marshal(testJumpV1())
+	require.NoError(t, err)
+	upgraded, err := upgradeSubmitV1(c, body)
+	require.NoError(t, err)
+
+	var req SubmitRequest
+	require.NoError(t, json.Unmarshal(upgraded, &req))
+	assert.Equal(t, "athlete-1", req.AthleteID)
+	assert.Equal(t, "athlete-1", req.Session.AthleteID)
+	assert.Equal(t, 1, req.Session.JumpCount)
+	assert.Equal(t, 61.5, req.Session.MaxHeight)
+	assert.Equal(t, req.Session.StartTime, req.Session.EndTime)
+	require.Len(t, req.Metrics, 1)
+	assert.Equal(t, 218, req.Metrics[0].ContactTimeMs)
+
+	// The upload is answered with the jump as sent
+	answer, err := downgradeSubmitV1(c, []byte(`{"session_id": "session-1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(body), string(answer))
+
+	// Jumps without an ID get one
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	_, err = upgradeSubmitV1(c, []byte(`{"athleteId": "athlete-1", "timestamp": 0, "poseData": []}`))
+	require.NoError(t, err)
+	answer, err = downgradeSubmitV1(c, nil)
+	require.NoError(t, err)
+	var jump jumpMetricV1
+	require.NoError(t, json.Unmarshal(answer, &jump))
+	assert.Len(t, jump.ID, 24)
+
+	_, err = upgradeSubmitV1(c, []byte(`not json`))
+	assert.Error(t, err)
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	_, err = downgradeSubmitV1(c, nil)
+	assert.Error(t, err)
+}
+
+func TestDowngradeMetricsV1(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/athletes/athlete-1/metrics", nil)
+
+	body, err := json.Marshal(GetMetricsResponse{Metrics: []JumpMetric{testJumpV1().toModel()}, TotalCount: 1})
+	require.NoError(t, err)
+	downgraded, err := downgradeMetricsV1(c, body)
+	require.NoError(t, err)
+
+	// Version 1 decodes a bare array of jumps
+	var jumps []jumpMetricV1
+	require.NoError(t, json.Unmarshal(downgraded, &jumps))
+	require.Len(t, jumps, 1)
+	assert.Equal(t, "jump-1", jumps[0].ID)
+	assert.Equal(t, 0.708, jumps[0].FlightTime)
+
+	empty, err := json.Marshal(GetMetricsResponse{})
+	require.NoError(t, err)
+	downgraded, err = downgradeMetricsV1(c, empty)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(downgraded))
+}
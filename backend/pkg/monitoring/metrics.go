@@ -5,16 +5,32 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// EndpointKey is the gin context key under which handlers serving requests
+// without a registered route, such as the gateway's proxy, store the route
+// template to label the request with
+const EndpointKey = "monitoring.endpoint"
+
+// unmatchedEndpoint labels requests no route template was found for, so that
+// scanners cannot create a series per path
+const unmatchedEndpoint = "unmatched"
+
 // Metrics holds all Prometheus metrics
 type Metrics struct {
 	// HTTP metrics
 	HTTPRequestsTotal     *prometheus.CounterVec
 	HTTPRequestDuration   *prometheus.HistogramVec
 	HTTPResponseSize      *prometheus.HistogramVec
+	HTTPRequestsInFlight  prometheus.Gauge
+	
+	// Proxy metrics
+	UpstreamRequestsTotal   *prometheus.CounterVec
+	UpstreamRequestDuration *prometheus.HistogramVec
+	ProxyErrorsTotal        *prometheus.CounterVec
 	
 	// Business metrics
 	JumpsProcessedTotal   *prometheus.CounterVec
@@ -60,6 +76,34 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"method", "endpoint"},
 		),
+		HTTPRequestsInFlight: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "Number of HTTP requests currently being served",
+			},
+		),
+		UpstreamRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "upstream_requests_total",
+				Help: "Total number of requests sent to upstreams, by status code or error",
+			},
+			[]string{"route", "upstream", "status_code"},
+		),
+		UpstreamRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "upstream_request_duration_seconds",
+				Help:    "Time until an upstream returned response headers in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"route", "upstream"},
+		),
+		ProxyErrorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "proxy_errors_total",
+				Help: "Total number of proxied requests that failed, by error class",
+			},
+			[]string{"route", "class"},
+		),
 		JumpsProcessedTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "jumps_processed_total",
@@ -146,6 +190,10 @@ func NewMetrics() *Metrics {
 		m.HTTPRequestsTotal,
 		m.HTTPRequestDuration,
 		m.HTTPResponseSize,
+		m.HTTPRequestsInFlight,
+		m.UpstreamRequestsTotal,
+		m.UpstreamRequestDuration,
+		m.ProxyErrorsTotal,
 		m.JumpsProcessedTotal,
 		m.JumpProcessingTime,
 		m.ActiveUsers,
@@ -162,6 +210,12 @@ func NewMetrics() *Metrics {
 	return m
 }
 
+// RegisterCollectors registers additional collectors owned by other packages,
+// such as the security middleware, on the same registry as the core metrics
+func (m *Metrics) RegisterCollectors(collectors ...prometheus.Collector) {
+	prometheus.MustRegister(collectors...)
+}
+
 // HTTPMiddleware wraps HTTP handlers with metrics collection
 func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -182,6 +236,36 @@ func (m *Metrics) HTTPMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// GinMiddleware records requests served by a Gin router. Requests are labelled
+// with their route template, e.g. /api/v1/athletes/:id/summary, rather than the
+// path, to keep the number of series bounded.
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.HTTPRequestsInFlight.Inc()
+		defer m.HTTPRequestsInFlight.Dec()
+
+		c.Next()
+
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = c.GetString(EndpointKey)
+		}
+		if endpoint == "" {
+			endpoint = unmatchedEndpoint
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		method := c.Request.Method
+		m.HTTPRequestsTotal.WithLabelValues(method, endpoint, strconv.Itoa(c.Writer.Status())).Inc()
+		m.HTTPRequestDuration.WithLabelValues(method, endpoint).Observe(time.Since(start).Seconds())
+		m.HTTPResponseSize.WithLabelValues(method, endpoint).Observe(float64(size))
+	}
+}
+
 // responseWriter wraps http.ResponseWriter to capture metrics
 type responseWriter struct {
 	http.ResponseWriter
@@ -200,6 +284,16 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Proxy metric helpers
+func (m *Metrics) RecordUpstreamRequest(route, upstream, statusCode string, duration time.Duration) {
+	m.UpstreamRequestsTotal.WithLabelValues(route, upstream, statusCode).Inc()
+	m.UpstreamRequestDuration.WithLabelValues(route, upstream).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordProxyError(route, class string) {
+	m.ProxyErrorsTotal.WithLabelValues(route, class).Inc()
+}
+
 // Business metric helpers
 func (m *Metrics) RecordJumpProcessed(userID, status string) {
 	m.JumpsProcessedTotal.WithLabelValues(userID, status).Inc()
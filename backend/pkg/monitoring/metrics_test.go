@@ -0,0 +1,41 @@
+package monitoring
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	m := NewMetrics()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(m.GinMiddleware())
+	router.GET("/athletes/:id/summary", func(c *gin.Context) {
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.HTTPRequestsInFlight))
+		c.JSON(http.StatusOK, gin.H{"athlete": c.Param("id")})
+	})
+	router.NoRoute(func(c *gin.Context) {
+		if c.Request.URL.Path == "/api/v1/ml/models" {
+			c.Set(EndpointKey, "/api/v1/ml/*")
+			c.Status(http.StatusBadGateway)
+			return
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/athletes/a1/summary", "/athletes/a2/summary", "/api/v1/ml/models", "/wp-login.php"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", "/athletes/:id/summary", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", "/api/v1/ml/*", "502")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.HTTPRequestsTotal.WithLabelValues("GET", unmatchedEndpoint, "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.HTTPRequestDuration))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.HTTPRequestsInFlight))
+}
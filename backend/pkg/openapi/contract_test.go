@@ -0,0 +1,85 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/metrics"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/openapi"
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+)
+
+// documentPath is the committed document clients are generated from
+const documentPath = "../../api/openapi.json"
+
+var update = flag.Bool("update", false, "rewrite api/openapi.json from the routes")
+
+// metricsRouter mounts the metrics routes as metrics-svc does. Requests that
+// reach the store would fail, the route table is all the contract needs.
+func metricsRouter(t *testing.T) (*gin.Engine, []openapi.Route) {
+	routes := append(metrics.APIRoutes("/api/v1"), metrics.TrainingRoutes("/api/v1/admin")...)
+	validator := openapi.NewValidator(routes, openapi.ValidatorConfig{Strict: true, Responses: true}, zap.NewNop())
+	validator.OnResponseViolation(func(route openapi.Route, status int, violations []schema.FieldError) {
+		t.Errorf("%s %s answered %d not matching the document: %v", route.Method, route.Path, status, violations)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := metrics.NewHandler(nil, zap.NewNop())
+	handler.RegisterRoutes(router.Group("/api/v1", validator.Middleware()))
+	handler.RegisterTrainingRoutes(router.Group("/api/v1/admin", validator.Middleware()))
+	return router, routes
+}
+
+// TestContract_Document fails when routes or models change without the
+// committed document being regenerated with
+//
+//	go test ./pkg/openapi -run TestContract_Document -update
+func TestContract_Document(t *testing.T) {
+	router, routes := metricsRouter(t)
+	assert.Empty(t, openapi.Check(router.Routes(), routes))
+
+	generated, err := json.MarshalIndent(openapi.Generate(metrics.APIInfo, router.Routes(), routes), "", "  ")
+	require.NoError(t, err)
+	generated = append(generated, '\n')
+
+	if *update {
+		require.NoError(t, os.WriteFile(documentPath, generated, 0o644))
+	}
+	committed, err := os.ReadFile(documentPath)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(committed), string(generated), "api/openapi.json is out of date, regenerate it with -update")
+}
+
+func TestContract_Responses(t *testing.T) {
+	router, _ := metricsRouter(t)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	// Answered by the handler before it reaches the store
+	w := serve(http.MethodPost, "/api/v1/guardian/accept", `{"code": "abc", "consent": false}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Answered by the validator from the document
+	w = serve(http.MethodGet, "/api/v1/leaderboard?period=year", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodGet, "/api/v1/admin/training-data?since=yesterday", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(http.MethodPost, "/api/v1/metrics", `{"athlete_id": "a1", "metrics": [{"jumpHeight": 61.5, "symmetryScore": 0.9}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"metrics[0].jumpHeight"`)
+}
@@ -0,0 +1,252 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Security schemes referenced by routes that require authentication
+const (
+	SecurityBearer = "bearerAuth"
+	SecurityAPIKey = "apiKey"
+)
+
+// Document is an OpenAPI 3 document. Schemas are inlined.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+// Operation describes one method of a path
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *schema.Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes the response for one status code
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *schema.Schema `json:"schema"`
+}
+
+// Components holds the security schemes
+type Components struct {
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how callers authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Route documents a Gin route. Request and the values of Responses are models
+// whose schemas are generated from their json and schema tags; a nil response
+// has no body.
+type Route struct {
+	Method       string
+	Path         string // in Gin syntax, e.g. /api/v1/athletes/:athlete_id/summary
+	OperationID  string
+	Summary      string
+	Tags         []string
+	Public       bool        // served without authentication
+	Parameters   []Parameter // query and header parameters; path parameters are taken from Path
+	Request      interface{}
+	MaxBodyBytes int64 // larger request bodies are rejected, 0 for no limit
+	Responses    map[int]interface{}
+	ContentType  string // of response bodies, application/json when empty
+}
+
+func (r Route) key() string {
+	return r.Method + " " + r.Path
+}
+
+func (r Route) contentType() string {
+	if r.ContentType == "" {
+		return "application/json"
+	}
+	return r.ContentType
+}
+
+// Generate builds the document of every route in the route table. Routes
+// without a declaration are listed with their path parameters only, so the
+// document always covers the whole API.
+func Generate(info Info, table gin.RoutesInfo, routes []Route) *Document {
+	declared := make(map[string]Route, len(routes))
+	for _, rt := range routes {
+		declared[rt.key()] = rt
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{SecuritySchemes: map[string]SecurityScheme{
+			SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			SecurityAPIKey: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		}},
+	}
+	for _, ri := range table {
+		path, params := convertPath(ri.Path)
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+
+		rt, ok := declared[ri.Method+" "+ri.Path]
+		if !ok {
+			item[strings.ToLower(ri.Method)] = &Operation{
+				Parameters: params,
+				Responses:  map[string]*Response{"default": {Description: "Undocumented"}},
+			}
+			continue
+		}
+		item[strings.ToLower(ri.Method)] = operation(rt, params)
+	}
+	return doc
+}
+
+func operation(rt Route, params []Parameter) *Operation {
+	op := &Operation{
+		OperationID: rt.OperationID,
+		Summary:     rt.Summary,
+		Tags:        rt.Tags,
+		Parameters:  append(params, rt.Parameters...),
+		Responses:   make(map[string]*Response, len(rt.Responses)),
+	}
+	if !rt.Public {
+		op.Security = []map[string][]string{{SecurityBearer: {}}, {SecurityAPIKey: {}}}
+		// Answered by the authentication middleware before the handler runs
+		op.Responses[strconv.Itoa(http.StatusUnauthorized)] = &Response{Description: http.StatusText(http.StatusUnauthorized)}
+	}
+	if rt.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: schema.Generate(rt.Request)}},
+		}
+	}
+	for status, model := range rt.Responses {
+		response := &Response{Description: http.StatusText(status)}
+		if model != nil {
+			contentType := rt.contentType()
+			if status >= http.StatusBadRequest {
+				contentType = "application/json"
+			}
+			response.Content = map[string]MediaType{contentType: {Schema: schema.Generate(model)}}
+		}
+		op.Responses[strconv.Itoa(status)] = response
+	}
+	return op
+}
+
+// convertPath turns a Gin path into an OpenAPI path and its path parameters
+func convertPath(path string) (string, []Parameter) {
+	var params []Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &schema.Schema{Type: "string"}})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// Check compares a route table with the declarations. It describes every
+// route without a declaration, every declaration without a route and every
+// operation ID that is missing or used twice.
+func Check(table gin.RoutesInfo, routes []Route) []string {
+	var problems []string
+	registered := make(map[string]bool, len(table))
+	for _, info := range table {
+		registered[info.Method+" "+info.Path] = true
+	}
+
+	declared := make(map[string]bool, len(routes))
+	ids := make(map[string]string, len(routes))
+	for _, rt := range routes {
+		declared[rt.key()] = true
+		if !registered[rt.key()] {
+			problems = append(problems, fmt.Sprintf("%s is documented but not registered", rt.key()))
+		}
+		if rt.OperationID == "" {
+			problems = append(problems, fmt.Sprintf("%s has no operation ID", rt.key()))
+		} else if other, exists := ids[rt.OperationID]; exists {
+			problems = append(problems, fmt.Sprintf("%s and %s share operation ID %q", other, rt.key(), rt.OperationID))
+		}
+		ids[rt.OperationID] = rt.key()
+	}
+	for _, info := range table {
+		if key := info.Method + " " + info.Path; !declared[key] {
+			problems = append(problems, fmt.Sprintf("%s is registered but not documented", key))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// Handler serves the document as JSON
+func (d *Document) Handler() gin.HandlerFunc {
+	body, err := json.Marshal(d)
+	return func(c *gin.Context) {
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode the API document"})
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}
+}
@@ -0,0 +1,192 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+)
+
+type testJump struct {
+	HeightCm float64 `json:"height_cm" schema:"required,minimum=0,maximum=200"`
+}
+
+type testSummary struct {
+	AthleteID string  `json:"athlete_id"`
+	MaxHeight float64 `json:"max_height_cm"`
+}
+
+type testError struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+func testRoutes() []Route {
+	return []Route{
+		{
+			Method:       http.MethodPost,
+			Path:         "/api/v1/jumps",
+			OperationID:  "createJump",
+			Request:      testJump{},
+			MaxBodyBytes: 64,
+			Responses:    map[int]interface{}{http.StatusCreated: nil},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/api/v1/athletes/:athlete_id/summary",
+			OperationID: "getSummary",
+			Parameters: []Parameter{
+				{Name: "period", In: "query", Schema: &schema.Schema{Type: "string", Enum: []string{"week", "month"}}},
+				{Name: "limit", In: "query", Schema: &schema.Schema{Type: "integer", Maximum: func(f float64) *float64 { return &f }(100)}},
+			},
+			Responses: map[int]interface{}{http.StatusOK: testSummary{}, http.StatusNotFound: testError{}},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/jumps", func(c *gin.Context) {})
+	router.GET("/api/v1/athletes/:athlete_id/summary", func(c *gin.Context) {})
+	router.GET("/health", func(c *gin.Context) {})
+
+	doc := Generate(Info{Title: "Test", Version: "1"}, router.Routes(), testRoutes())
+	assert.Equal(t, Version, doc.OpenAPI)
+	require.Contains(t, doc.Paths, "/api/v1/athletes/{athlete_id}/summary")
+
+	summary := doc.Paths["/api/v1/athletes/{athlete_id}/summary"]["get"]
+	assert.Equal(t, "getSummary", summary.OperationID)
+	require.Len(t, summary.Parameters, 3)
+	assert.Equal(t, Parameter{Name: "athlete_id", In: "path", Required: true, Schema: &schema.Schema{Type: "string"}}, summary.Parameters[0])
+	assert.Equal(t, schema.Generate(testSummary{}), summary.Responses["200"].Content["application/json"].Schema)
+	assert.Contains(t, summary.Responses, "401")
+	assert.Len(t, summary.Security, 2)
+
+	create := doc.Paths["/api/v1/jumps"]["post"]
+	assert.True(t, create.RequestBody.Required)
+	assert.Empty(t, create.Responses["201"].Content)
+
+	// Undocumented routes are still listed
+	assert.Contains(t, doc.Paths["/health"]["get"].Responses, "default")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	doc.Handler()(c)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &decoded))
+	assert.Equal(t, "3.0.3", decoded["openapi"])
+}
+
+func TestCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/jumps", func(c *gin.Context) {})
+	router.DELETE("/api/v1/jumps/:id", func(c *gin.Context) {})
+
+	routes := append(testRoutes(), Route{Method: http.MethodGet, Path: "/api/v1/jumps", OperationID: "createJump"})
+	assert.Equal(t, []string{
+		"DELETE /api/v1/jumps/:id is registered but not documented",
+		"GET /api/v1/athletes/:athlete_id/summary is documented but not registered",
+		"GET /api/v1/jumps is documented but not registered",
+		`POST /api/v1/jumps and GET /api/v1/jumps share operation ID "createJump"`,
+	}, Check(router.Routes(), routes))
+}
+
+func newValidatedRouter(t *testing.T, config ValidatorConfig, summary gin.H) (*Validator, *gin.Engine) {
+	validator := NewValidator(testRoutes(), config, zap.NewNop())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(validator.Middleware())
+	router.POST("/api/v1/jumps", func(c *gin.Context) {
+		var jump testJump
+		require.NoError(t, c.ShouldBindJSON(&jump))
+		c.Status(http.StatusCreated)
+	})
+	router.GET("/api/v1/athletes/:athlete_id/summary", func(c *gin.Context) {
+		c.JSON(http.StatusOK, summary)
+	})
+	router.GET("/api/v1/undocumented", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"anything": true})
+	})
+	return validator, router
+}
+
+func TestValidator_Requests(t *testing.T) {
+	_, router := newValidatedRouter(t, ValidatorConfig{Strict: true}, gin.H{})
+
+	request := func(method, target, body string) (*httptest.ResponseRecorder, ErrorResponse) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var resp ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	w, _ := request(http.MethodPost, "/api/v1/jumps", `{"height_cm": 61.5}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w, resp := request(http.MethodPost, "/api/v1/jumps", `{"height_cm": 250, "jump_height": 1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "validation_failed", resp.Code)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "height_cm", Message: "must be at most 200"},
+		{Field: "jump_height", Message: "is not a known field"},
+	}, resp.Validations)
+
+	w, resp = request(http.MethodPost, "/api/v1/jumps", `{"height_cm":`)
+	assert.Equal(t, "invalid_body", resp.Code)
+
+	w, resp = request(http.MethodPost, "/api/v1/jumps", `{"height_cm": 1}`+strings.Repeat(" ", 64))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "body_too_large", resp.Code)
+
+	w, resp = request(http.MethodGet, "/api/v1/athletes/a1/summary?period=year&limit=ten", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "invalid_query", resp.Code)
+	require.Len(t, resp.Validations, 2)
+	assert.Equal(t, "period", resp.Validations[0].Field)
+	assert.Equal(t, schema.FieldError{Field: "limit", Message: "must be an integer"}, resp.Validations[1])
+
+	w, _ = request(http.MethodGet, "/api/v1/athletes/a1/summary?period=week&limit=10", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w, _ = request(http.MethodGet, "/api/v1/undocumented?period=year", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestValidator_Responses(t *testing.T) {
+	validator, router := newValidatedRouter(t, ValidatorConfig{Responses: true}, gin.H{"athlete_id": "a1", "max_height_cm": "high", "rank": 1})
+	var reported []schema.FieldError
+	validator.OnResponseViolation(func(route Route, status int, violations []schema.FieldError) {
+		assert.Equal(t, "getSummary", route.OperationID)
+		reported = append(reported, violations...)
+	})
+
+	// The response is sent unchanged
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/athletes/a1/summary", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"athlete_id": "a1", "max_height_cm": "high", "rank": 1}`, w.Body.String())
+
+	assert.Equal(t, []schema.FieldError{
+		{Field: "max_height_cm", Message: "must be a number"},
+		{Field: "rank", Message: "is not a known field"},
+	}, reported)
+	assert.Equal(t, float64(1), testutil.ToFloat64(validator.violations.WithLabelValues(http.MethodGet, "/api/v1/athletes/:athlete_id/summary")))
+
+	// Bodiless responses are not checked
+	reported = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/jumps", strings.NewReader(`{"height_cm": 61.5}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, reported)
+}
@@ -0,0 +1,277 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/schema"
+)
+
+// maxValidatedResponseBytes bounds the response bodies kept for validation;
+// larger responses are passed through unchecked
+const maxValidatedResponseBytes = 1 << 20
+
+// ErrorResponse is the body of rejected requests, in the format the services
+// use for their own errors
+type ErrorResponse struct {
+	Error       string              `json:"error"`
+	Code        string              `json:"code"`
+	Validations []schema.FieldError `json:"validations,omitempty"`
+}
+
+// ValidatorConfig configures a Validator
+type ValidatorConfig struct {
+	// Strict rejects request fields that are not part of the models
+	Strict bool
+	// Responses checks the responses of handlers against the document.
+	// Mismatches are logged and counted, the response is sent unchanged.
+	Responses bool
+}
+
+// Validator checks requests, and optionally responses, of declared routes
+// against the schemas the document is generated from
+type Validator struct {
+	routes      map[string]Route
+	config      ValidatorConfig
+	violations  *prometheus.CounterVec
+	onViolation []func(route Route, status int, violations []schema.FieldError)
+	logger      *zap.Logger
+}
+
+// NewValidator creates a validator for the declared routes
+func NewValidator(routes []Route, config ValidatorConfig, logger *zap.Logger) *Validator {
+	v := &Validator{
+		routes: make(map[string]Route, len(routes)),
+		config: config,
+		violations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "openapi_response_violations_total",
+				Help: "Responses that did not match the API document, by route",
+			},
+			[]string{"method", "route"},
+		),
+		logger: logger,
+	}
+	for _, rt := range routes {
+		v.routes[rt.key()] = rt
+	}
+	return v
+}
+
+// OnResponseViolation registers a hook called for every response that does not
+// match the document. It must be called before the validator serves requests.
+func (v *Validator) OnResponseViolation(hook func(route Route, status int, violations []schema.FieldError)) {
+	v.onViolation = append(v.onViolation, hook)
+}
+
+// Collectors returns the validator's Prometheus collectors
+func (v *Validator) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{v.violations}
+}
+
+// Middleware validates the requests of declared routes before the handler
+// runs. Routes are looked up by their Gin path, so the middleware can be
+// installed on a group, e.g. after authentication.
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rt, declared := v.routes[c.Request.Method+" "+c.FullPath()]
+		if !declared {
+			c.Next()
+			return
+		}
+
+		if !v.validateQuery(c, rt) {
+			return
+		}
+		if rt.Request != nil && !v.validateBody(c, rt) {
+			return
+		}
+		if !v.config.Responses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+		v.validateResponse(rt, recorder)
+	}
+}
+
+// validateQuery checks the query parameters of a route
+func (v *Validator) validateQuery(c *gin.Context, rt Route) bool {
+	var fieldErrors []schema.FieldError
+	query := c.Request.URL.Query()
+	for _, param := range rt.Parameters {
+		if param.In != "query" {
+			continue
+		}
+		raw, present := query[param.Name]
+		if !present {
+			if param.Required {
+				fieldErrors = append(fieldErrors, schema.FieldError{Field: param.Name, Message: "is required"})
+			}
+			continue
+		}
+		if param.Schema == nil {
+			continue
+		}
+		for _, fieldErr := range param.Schema.Validate(queryValue(param.Schema, raw[0]), false) {
+			fieldErrors = append(fieldErrors, schema.FieldError{Field: param.Name, Message: fieldErr.Message})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:       "Query parameters do not match the API document",
+			Code:        "invalid_query",
+			Validations: fieldErrors,
+		})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// queryValue converts a query string value to the JSON value its schema expects
+func queryValue(s *schema.Schema, raw string) interface{} {
+	switch s.Type {
+	case "integer", "number":
+		return json.Number(raw)
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// validateBody caps the request body and validates it against the route's
+// request model. The body is restored so the handler can bind it as usual.
+func (v *Validator) validateBody(c *gin.Context, rt Route) bool {
+	body := c.Request.Body
+	if rt.MaxBodyBytes > 0 {
+		if c.Request.ContentLength > rt.MaxBodyBytes {
+			abortBodyTooLarge(c)
+			return false
+		}
+		body = http.MaxBytesReader(c.Writer, body, rt.MaxBodyBytes)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			abortBodyTooLarge(c)
+			return false
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read request body", Code: "invalid_body"})
+		c.Abort()
+		return false
+	}
+
+	fieldErrors, err := schema.Generate(rt.Request).Decode(data, v.config.Strict)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body", Code: "invalid_body"})
+		c.Abort()
+		return false
+	}
+	if len(fieldErrors) > 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:       "Request body does not match the schema",
+			Code:        "validation_failed",
+			Validations: fieldErrors,
+		})
+		c.Abort()
+		return false
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	return true
+}
+
+func abortBodyTooLarge(c *gin.Context) {
+	c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Request body too large", Code: "body_too_large"})
+	c.Abort()
+}
+
+// validateResponse checks a recorded response against the route's response
+// model for its status. Responses of other content types are not checked.
+func (v *Validator) validateResponse(rt Route, recorder *responseRecorder) {
+	status := recorder.Status()
+	model, documented := rt.Responses[status]
+
+	var violations []schema.FieldError
+	switch {
+	case !documented:
+		violations = []schema.FieldError{{Field: "$", Message: fmt.Sprintf("status %d is not documented", status)}}
+	case model == nil || recorder.truncated || !isJSON(recorder.Header().Get("Content-Type")):
+		return
+	default:
+		// Responses are strict: a field missing from the model is drift as well
+		var err error
+		violations, err = schema.Generate(model).Decode(recorder.body.Bytes(), true)
+		if err != nil {
+			violations = []schema.FieldError{{Field: "$", Message: err.Error()}}
+		}
+	}
+	if len(violations) == 0 {
+		return
+	}
+
+	v.violations.WithLabelValues(rt.Method, rt.Path).Inc()
+	v.logger.Error("Response does not match the API document",
+		zap.String("method", rt.Method),
+		zap.String("route", rt.Path),
+		zap.Int("status", status),
+		zap.Any("violations", violations),
+	)
+	for _, hook := range v.onViolation {
+		hook(rt, status, violations)
+	}
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// responseRecorder keeps a copy of the response body while writing it through
+type responseRecorder struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.record(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.record([]byte(s))
+	return r.ResponseWriter.WriteString(s)
+}
+
+func (r *responseRecorder) record(data []byte) {
+	if r.truncated {
+		return
+	}
+	if r.body.Len()+len(data) > maxValidatedResponseBytes {
+		r.truncated = true
+		r.body.Reset()
+		return
+	}
+	r.body.Write(data)
+}
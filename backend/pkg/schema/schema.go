@@ -0,0 +1,209 @@
+package schema
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema is the subset of JSON Schema generated from Go structs.
+// Nullable follows the OpenAPI 3.0 convention for pointer fields.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	// order keeps properties in struct order so validation errors are stable
+	order []string
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	cache    sync.Map // reflect.Type -> *Schema
+)
+
+// Generate builds the schema of a value's type from its json tags.
+// Constraints come from the schema tag, e.g.
+//
+//	HeightCm float64 `json:"height_cm" schema:"minimum=0,maximum=200"`
+//	Metrics  []JumpMetric `json:"metrics" schema:"required,maxItems=500"`
+//
+// Supported keys are required, minimum, maximum, minLength, maxLength,
+// minItems, maxItems, enum (values separated by |) and description.
+func Generate(v interface{}) *Schema {
+	return ForType(reflect.TypeOf(v))
+}
+
+// ForType builds the schema of a type. Results are cached.
+func ForType(t reflect.Type) *Schema {
+	if cached, ok := cache.Load(t); ok {
+		return cached.(*Schema)
+	}
+	s := generate(t, map[reflect.Type]bool{})
+	cache.Store(t, s)
+	return s
+}
+
+func generate(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		s = generateObject(t, seen)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s = &Schema{Type: "string", Format: "byte"}
+		} else {
+			s = &Schema{Type: "array", Items: generate(t.Elem(), seen)}
+		}
+		nullable = nullable || t.Kind() == reflect.Slice
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object"}
+		nullable = true
+	case t.Kind() == reflect.String:
+		s = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		// interface{} and anything else accepts any JSON value
+		s = &Schema{}
+	}
+
+	s.Nullable = nullable
+	return s
+}
+
+func generateObject(t reflect.Type, seen map[reflect.Type]bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if seen[t] {
+		// Recursive types are not expanded a second time
+		return s
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, like encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := generateObject(embedded, seen)
+				for _, key := range inner.order {
+					s.addProperty(key, inner.Properties[key])
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := generate(field.Type, seen)
+		if applyTag(property, field.Tag.Get("schema")) {
+			s.Required = append(s.Required, name)
+		}
+		s.addProperty(name, property)
+	}
+	return s
+}
+
+func (s *Schema) addProperty(name string, property *Schema) {
+	if _, exists := s.Properties[name]; !exists {
+		s.order = append(s.order, name)
+	}
+	s.Properties[name] = property
+}
+
+// jsonName returns the json name of a field and whether it is skipped
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+// applyTag applies schema tag constraints and reports whether the field is required
+func applyTag(s *Schema, tag string) bool {
+	required := false
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "required":
+			required = true
+		case "minimum":
+			s.Minimum = parseFloat(value)
+		case "maximum":
+			s.Maximum = parseFloat(value)
+		case "minLength":
+			s.MinLength = parseInt(value)
+		case "maxLength":
+			s.MaxLength = parseInt(value)
+		case "minItems":
+			s.MinItems = parseInt(value)
+		case "maxItems":
+			s.MaxItems = parseInt(value)
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		case "description":
+			s.Description = value
+		}
+	}
+	return required
+}
+
+func parseFloat(value string) *float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func parseInt(value string) *int {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
@@ -0,0 +1,124 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLocation struct {
+	Latitude float64 `json:"latitude" schema:"minimum=-90,maximum=90"`
+}
+
+type testJump struct {
+	ID        string        `json:"id"`
+	HeightCm  float64       `json:"height_cm" schema:"minimum=0,maximum=200"`
+	Score     int           `json:"score"`
+	Timestamp time.Time     `json:"timestamp"`
+	Location  *testLocation `json:"location,omitempty"`
+	internal  string
+}
+
+type testRequest struct {
+	AthleteID string     `json:"athlete_id" schema:"required,minLength=1"`
+	Level     string     `json:"level,omitempty" schema:"enum=beginner|pro"`
+	Jumps     []testJump `json:"jumps" schema:"maxItems=2"`
+	Ignored   string     `json:"-"`
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(testRequest{})
+
+	assert.Equal(t, "object", s.Type)
+	assert.Equal(t, []string{"athlete_id"}, s.Required)
+	assert.NotContains(t, s.Properties, "Ignored")
+	require.Contains(t, s.Properties, "jumps")
+
+	jumps := s.Properties["jumps"]
+	assert.Equal(t, "array", jumps.Type)
+	assert.Equal(t, 2, *jumps.MaxItems)
+
+	jump := jumps.Items
+	assert.Equal(t, "number", jump.Properties["height_cm"].Type)
+	assert.Equal(t, "integer", jump.Properties["score"].Type)
+	assert.Equal(t, "date-time", jump.Properties["timestamp"].Format)
+	assert.True(t, jump.Properties["location"].Nullable)
+	assert.NotContains(t, jump.Properties, "internal")
+
+	assert.Same(t, s, Generate(testRequest{}), "schemas should be cached")
+}
+
+func TestDecode(t *testing.T) {
+	s := Generate(testRequest{})
+
+	tests := []struct {
+		name   string
+		body   string
+		strict bool
+		errors []FieldError
+	}{
+		{
+			name: "valid",
+			body: `{"athlete_id":"a1","jumps":[{"height_cm":55.5,"score":80,"timestamp":"2024-01-01T10:00:00Z","location":null}]}`,
+		},
+		{
+			name:   "missing required field",
+			body:   `{"jumps":[]}`,
+			errors: []FieldError{{Field: "athlete_id", Message: "is required"}},
+		},
+		{
+			name: "wrong types and ranges",
+			body: `{"athlete_id":"a1","level":"elite","jumps":[{"height_cm":250,"score":1.5,"timestamp":"yesterday","location":{"latitude":"north"}}]}`,
+			errors: []FieldError{
+				{Field: "level", Message: "must be one of [beginner pro]"},
+				{Field: "jumps[0].height_cm", Message: "must be at most 200"},
+				{Field: "jumps[0].score", Message: "must be an integer"},
+				{Field: "jumps[0].timestamp", Message: "must be an RFC 3339 date-time"},
+				{Field: "jumps[0].location.latitude", Message: "must be a number"},
+			},
+		},
+		{
+			name:   "too many items",
+			body:   `{"athlete_id":"a1","jumps":[{},{},{}]}`,
+			errors: []FieldError{{Field: "jumps", Message: "must contain at most 2 items"}},
+		},
+		{
+			name: "unknown fields allowed when not strict",
+			body: `{"athlete_id":"a1","extra":true}`,
+		},
+		{
+			name:   "unknown fields rejected when strict",
+			body:   `{"athlete_id":"a1","extra":true,"jumps":[{"bogus":1}]}`,
+			strict: true,
+			errors: []FieldError{
+				{Field: "jumps[0].bogus", Message: "is not a known field"},
+				{Field: "extra", Message: "is not a known field"},
+			},
+		},
+		{
+			name:   "wrong top-level type",
+			body:   `[]`,
+			errors: []FieldError{{Field: "$", Message: "must be an object"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors, err := s.Decode([]byte(tt.body), tt.strict)
+			require.NoError(t, err)
+			assert.Equal(t, tt.errors, errors)
+		})
+	}
+}
+
+func TestDecode_InvalidJSON(t *testing.T) {
+	s := Generate(testRequest{})
+
+	_, err := s.Decode([]byte(`{"athlete_id":`), false)
+	assert.Error(t, err)
+
+	_, err = s.Decode([]byte(`{"athlete_id":"a1"} {}`), false)
+	assert.Error(t, err)
+}
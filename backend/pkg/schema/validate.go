@@ -0,0 +1,238 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxErrors caps the number of errors reported for a single document
+const MaxErrors = 50
+
+// FieldError describes a value that does not match the schema.
+// Field is a path such as "metrics[3].height_cm".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validate checks a decoded JSON document against the schema. Numbers must
+// be decoded as json.Number (see Decoder.UseNumber). In strict mode
+// properties that are not part of the schema are reported as errors.
+func (s *Schema) Validate(document interface{}, strict bool) []FieldError {
+	v := &validator{strict: strict}
+	v.validate(s, document, "")
+	return v.errors
+}
+
+type validator struct {
+	strict bool
+	errors []FieldError
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	if len(v.errors) >= MaxErrors {
+		return
+	}
+	if path == "" {
+		path = "$"
+	}
+	v.errors = append(v.errors, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validate(s *Schema, value interface{}, path string) {
+	if len(v.errors) >= MaxErrors {
+		return
+	}
+	if value == nil {
+		if !s.Nullable && s.Type != "" {
+			v.fail(path, "must be %s, not null", article(s.Type))
+		}
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		v.validateObject(s, value, path)
+	case "array":
+		v.validateArray(s, value, path)
+	case "string":
+		v.validateString(s, value, path)
+	case "integer", "number":
+		v.validateNumber(s, value, path)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "must be a boolean")
+		}
+	}
+}
+
+func (v *validator) validateObject(s *Schema, value interface{}, path string) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		v.fail(path, "must be an object")
+		return
+	}
+
+	for _, name := range s.Required {
+		if _, exists := object[name]; !exists {
+			v.fail(join(path, name), "is required")
+		}
+	}
+
+	// Schemas without properties (maps, interface{}) accept any keys
+	if s.Properties == nil {
+		return
+	}
+
+	for _, name := range s.order {
+		if property, exists := object[name]; exists {
+			v.validate(s.Properties[name], property, join(path, name))
+		}
+	}
+
+	if v.strict || (s.AdditionalProperties != nil && !*s.AdditionalProperties) {
+		unknown := make([]string, 0)
+		for name := range object {
+			if _, known := s.Properties[name]; !known {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		for _, name := range unknown {
+			v.fail(join(path, name), "is not a known field")
+		}
+	}
+}
+
+func (v *validator) validateArray(s *Schema, value interface{}, path string) {
+	items, ok := value.([]interface{})
+	if !ok {
+		v.fail(path, "must be an array")
+		return
+	}
+
+	if s.MinItems != nil && len(items) < *s.MinItems {
+		v.fail(path, "must contain at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && len(items) > *s.MaxItems {
+		// Validating every item of an oversized array is wasted work
+		v.fail(path, "must contain at most %d items", *s.MaxItems)
+		return
+	}
+
+	if s.Items == nil {
+		return
+	}
+	for i, item := range items {
+		v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+	}
+}
+
+func (v *validator) validateString(s *Schema, value interface{}, path string) {
+	str, ok := value.(string)
+	if !ok {
+		v.fail(path, "must be a string")
+		return
+	}
+
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		v.fail(path, "must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(path, "must be at most %d characters", *s.MaxLength)
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, option := range s.Enum {
+			if str == option {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			v.fail(path, "must be one of %v", s.Enum)
+		}
+	}
+
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			v.fail(path, "must be an RFC 3339 date-time")
+		}
+	}
+}
+
+func (v *validator) validateNumber(s *Schema, value interface{}, path string) {
+	var number float64
+	switch n := value.(type) {
+	case json.Number:
+		if s.Type == "integer" {
+			if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+				v.fail(path, "must be an integer")
+				return
+			}
+		}
+		f, err := n.Float64()
+		if err != nil {
+			v.fail(path, "must be a number")
+			return
+		}
+		number = f
+	case float64:
+		if s.Type == "integer" && n != float64(int64(n)) {
+			v.fail(path, "must be an integer")
+			return
+		}
+		number = n
+	default:
+		v.fail(path, "must be %s", article(s.Type))
+		return
+	}
+
+	if s.Minimum != nil && number < *s.Minimum {
+		v.fail(path, "must be at least %s", formatFloat(*s.Minimum))
+	}
+	if s.Maximum != nil && number > *s.Maximum {
+		v.fail(path, "must be at most %s", formatFloat(*s.Maximum))
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func article(typ string) string {
+	if typ == "integer" || typ == "object" || typ == "array" {
+		return "an " + typ
+	}
+	return "a " + typ
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Decode parses a JSON document and validates it against the schema.
+// A syntax error is returned as an error; schema violations as FieldErrors.
+func (s *Schema) Decode(body []byte, strict bool) ([]FieldError, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the top-level value")
+	}
+	return s.Validate(document, strict), nil
+}
@@ -0,0 +1,313 @@
+package security
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/audit"
+)
+
+// RevokeTokenRequest represents a request to revoke a single token
+type RevokeTokenRequest struct {
+	JTI       string    `json:"jti" binding:"required"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Reason    string    `json:"reason"`
+}
+
+// RevokeUserTokensRequest represents a request to revoke all tokens of a user
+type RevokeUserTokensRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RotateAPIKeyRequest represents a request to rotate an API key
+type RotateAPIKeyRequest struct {
+	GracePeriod string `json:"grace_period"` // e.g. "24h", empty revokes the old key immediately
+}
+
+// APIKeyResponse is returned when a key is created or rotated.
+// It is the only time the plaintext key is visible.
+type APIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// RequireRole middleware allows only authenticated callers holding one of the given roles.
+// It must run after JWTAuth.
+func (sm *SecurityMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("jwt_claims")
+		claims, ok := value.(jwt.MapClaims)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		if HasRole(c, roles...) {
+			c.Next()
+			return
+		}
+
+		sm.logger.Warn("Insufficient role for request",
+			zap.String("client_ip", sm.ClientIP(c)),
+			zap.Any("user_id", claims["sub"]),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// RegisterAdminRoutes mounts the security administration endpoints.
+// The group must already be protected by JWTAuth and RequireRole.
+func (sm *SecurityMiddleware) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.POST("/tokens/revoke", sm.revokeToken)
+	rg.POST("/users/:user_id/tokens/revoke", sm.revokeUserTokens)
+
+	rg.POST("/api-keys", sm.createAPIKey)
+	rg.GET("/api-keys", sm.listAPIKeys)
+	rg.POST("/api-keys/:id/rotate", sm.rotateAPIKey)
+	rg.DELETE("/api-keys/:id", sm.revokeAPIKey)
+
+	rg.GET("/bans", sm.listBans)
+	rg.DELETE("/bans/:kind/:subject", sm.liftBan)
+
+	rg.GET("/audit", sm.queryAudit)
+	rg.GET("/audit/verify", sm.verifyAudit)
+}
+
+// revokeToken revokes a single token by its jti
+func (sm *SecurityMiddleware) revokeToken(c *gin.Context) {
+	var req RevokeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Without a known expiry keep the revocation for the longest token lifetime
+	if req.ExpiresAt.IsZero() && sm.config.JWTExpiration > 0 {
+		req.ExpiresAt = time.Now().Add(sm.config.JWTExpiration)
+	}
+
+	if err := sm.revocations.RevokeToken(c.Request.Context(), req.JTI, req.ExpiresAt); err != nil {
+		sm.logger.Error("Failed to revoke token", zap.String("jti", req.JTI), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	sm.logger.Info("Token revoked",
+		zap.String("jti", req.JTI),
+		zap.String("revoked_by", c.GetString("user_id")),
+		zap.String("reason", req.Reason),
+	)
+	sm.auditRequest(c, audit.EventTokenRevoked, "", map[string]string{"jti": req.JTI, "reason": req.Reason})
+	c.JSON(http.StatusOK, gin.H{"jti": req.JTI, "revoked": true})
+}
+
+// revokeUserTokens invalidates every token issued to a user up to now
+func (sm *SecurityMiddleware) revokeUserTokens(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	var req RevokeUserTokensRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	revokedBefore := time.Now()
+	if err := sm.revocations.RevokeUserTokens(c.Request.Context(), userID, revokedBefore); err != nil {
+		sm.logger.Error("Failed to revoke user tokens", zap.String("user_id", userID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user tokens"})
+		return
+	}
+
+	sm.logger.Info("User tokens revoked",
+		zap.String("user_id", userID),
+		zap.String("revoked_by", c.GetString("user_id")),
+		zap.String("reason", req.Reason),
+	)
+	sm.auditRequest(c, audit.EventUserTokensRevoked, userID, map[string]string{"reason": req.Reason})
+	c.JSON(http.StatusOK, gin.H{"user_id": userID, "revoked_before": revokedBefore.UTC()})
+}
+
+// createAPIKey issues a new API key
+func (sm *SecurityMiddleware) createAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	key, secret, err := sm.apiKeys.Create(c.Request.Context(), req)
+	if err != nil {
+		sm.logger.Error("Failed to create API key", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	sm.logger.Info("API key created",
+		zap.String("api_key_id", key.ID),
+		zap.String("owner_id", key.OwnerID),
+		zap.Strings("scopes", key.Scopes),
+		zap.String("created_by", c.GetString("user_id")),
+	)
+	sm.auditRequest(c, audit.EventAPIKeyCreated, key.OwnerID, map[string]string{
+		"api_key_id": key.ID,
+		"scopes":     strings.Join(key.Scopes, ","),
+	})
+	c.JSON(http.StatusCreated, APIKeyResponse{APIKey: *key, Key: secret})
+}
+
+// listAPIKeys lists API keys, optionally filtered by owner_id
+func (sm *SecurityMiddleware) listAPIKeys(c *gin.Context) {
+	keys, err := sm.apiKeys.List(c.Request.Context(), c.Query("owner_id"))
+	if err != nil {
+		sm.logger.Error("Failed to list API keys", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// rotateAPIKey replaces an API key with a new one
+func (sm *SecurityMiddleware) rotateAPIKey(c *gin.Context) {
+	var req RotateAPIKeyRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	var grace time.Duration
+	if req.GracePeriod != "" {
+		parsed, err := time.ParseDuration(req.GracePeriod)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grace_period"})
+			return
+		}
+		grace = parsed
+	}
+
+	key, secret, err := sm.apiKeys.Rotate(c.Request.Context(), c.Param("id"), grace)
+	if err != nil {
+		sm.respondAPIKeyError(c, "Failed to rotate API key", err)
+		return
+	}
+
+	sm.logger.Info("API key rotated",
+		zap.String("old_api_key_id", c.Param("id")),
+		zap.String("api_key_id", key.ID),
+		zap.Duration("grace_period", grace),
+		zap.String("rotated_by", c.GetString("user_id")),
+	)
+	sm.auditRequest(c, audit.EventAPIKeyRotated, key.OwnerID, map[string]string{
+		"old_api_key_id": c.Param("id"),
+		"api_key_id":     key.ID,
+	})
+	c.JSON(http.StatusCreated, APIKeyResponse{APIKey: *key, Key: secret})
+}
+
+// revokeAPIKey permanently disables an API key
+func (sm *SecurityMiddleware) revokeAPIKey(c *gin.Context) {
+	if err := sm.apiKeys.Revoke(c.Request.Context(), c.Param("id")); err != nil {
+		sm.respondAPIKeyError(c, "Failed to revoke API key", err)
+		return
+	}
+
+	sm.logger.Info("API key revoked",
+		zap.String("api_key_id", c.Param("id")),
+		zap.String("revoked_by", c.GetString("user_id")),
+	)
+	sm.auditRequest(c, audit.EventAPIKeyRevoked, "", map[string]string{"api_key_id": c.Param("id")})
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "revoked": true})
+}
+
+// listBans lists the active temporary bans
+func (sm *SecurityMiddleware) listBans(c *gin.Context) {
+	bans, err := sm.bans.List(c.Request.Context())
+	if err != nil {
+		sm.logger.Error("Failed to list bans", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list bans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bans": bans})
+}
+
+// liftBan removes a temporary ban before it expires
+func (sm *SecurityMiddleware) liftBan(c *gin.Context) {
+	kind := BanKind(c.Param("kind"))
+	if kind != BanKindIP && kind != BanKindUser {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ban kind"})
+		return
+	}
+	subject := c.Param("subject")
+
+	if err := sm.bans.Lift(c.Request.Context(), kind, subject, c.GetString("user_id")); err != nil {
+		if err == ErrBanNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ban not found"})
+			return
+		}
+		sm.logger.Error("Failed to lift ban", zap.String("kind", string(kind)), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift ban"})
+		return
+	}
+
+	sm.auditRequest(c, audit.EventBanLifted, "", map[string]string{"kind": string(kind), "subject": subject})
+	c.JSON(http.StatusOK, gin.H{"kind": kind, "subject": subject, "lifted": true})
+}
+
+// respondAPIKeyError maps API key manager errors to HTTP responses
+func (sm *SecurityMiddleware) respondAPIKeyError(c *gin.Context, message string, err error) {
+	switch err {
+	case ErrAPIKeyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	case ErrAPIKeyRevoked:
+		c.JSON(http.StatusConflict, gin.H{"error": "API key already revoked"})
+	default:
+		sm.logger.Error(message, zap.String("api_key_id", c.Param("id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// HasRole reports whether the authenticated caller holds one of the given roles
+func HasRole(c *gin.Context, roles ...string) bool {
+	value, _ := c.Get("jwt_claims")
+	claims, ok := value.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	for _, have := range claimRoles(claims) {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimRoles extracts roles from either a "roles" array or a single "role" claim
+func claimRoles(claims jwt.MapClaims) []string {
+	var roles []string
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, r := range list {
+			if role, ok := r.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	if role, ok := claims["role"].(string); ok && role != "" {
+		roles = append(roles, role)
+	}
+	return roles
+}
@@ -0,0 +1,26 @@
+package security
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newContext := func(claims jwt.MapClaims) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if claims != nil {
+			c.Set("jwt_claims", claims)
+		}
+		return c
+	}
+
+	assert.False(t, HasRole(newContext(nil), "admin"))
+	assert.True(t, HasRole(newContext(jwt.MapClaims{"role": "admin"}), "admin"))
+	assert.True(t, HasRole(newContext(jwt.MapClaims{"roles": []interface{}{"athlete", "coach"}}), "admin", "coach"))
+	assert.False(t, HasRole(newContext(jwt.MapClaims{"roles": []interface{}{"athlete"}}), "coach"))
+}
@@ -0,0 +1,497 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	// APIKeyPrefix marks DunkSense API keys so they are recognizable in logs and secret scanners
+	APIKeyPrefix = "dsk"
+
+	apiKeyRedisPrefix         = "api_key:"
+	apiKeyLastUsedRedisPrefix = "api_key_last_used:"
+	apiKeyIndexKey            = "api_keys"
+	apiKeyLastUsedInterval    = time.Minute
+	// apiKeyUpdateAttempts bounds the retries of an update racing other changes
+	apiKeyUpdateAttempts = 5
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrAPIKeyMalformed = errors.New("api key malformed")
+	ErrAPIKeyInvalid   = errors.New("api key invalid")
+	ErrAPIKeyRevoked   = errors.New("api key revoked")
+	ErrAPIKeyExpired   = errors.New("api key expired")
+)
+
+// APIKey describes an API key issued to a partner integration.
+// The plaintext key is only returned once, at creation or rotation.
+type APIKey struct {
+	ID         string     `json:"id"` // visible prefix, e.g. "dsk_1a2b3c4d"
+	Name       string     `json:"name"`
+	OwnerID    string     `json:"owner_id"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"` // requests per window, 0 uses the default
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RotatedTo  string     `json:"rotated_to,omitempty"`
+}
+
+// HasScope reports whether the key grants the given scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// storedAPIKey is the persisted form of an API key
+type storedAPIKey struct {
+	APIKey
+	KeyHash string `json:"key_hash"`
+}
+
+// APIKeyStore persists hashed API keys. Last use is recorded apart from the
+// rest of the key, so recording it never undoes a concurrent revocation.
+type APIKeyStore interface {
+	Save(ctx context.Context, key *storedAPIKey) error
+	Get(ctx context.Context, id string) (*storedAPIKey, error)
+	List(ctx context.Context) ([]*storedAPIKey, error)
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+	// Update changes an existing key in one step, so concurrent changes are
+	// never lost. update may run more than once and aborts with its error.
+	Update(ctx context.Context, id string, update func(key *storedAPIKey) error) error
+}
+
+// NewAPIKeyStore creates a Redis-backed API key store, or an in-memory one when no Redis client is configured
+func NewAPIKeyStore(client *redis.Client) APIKeyStore {
+	if client == nil {
+		return NewMemoryAPIKeyStore()
+	}
+	return &RedisAPIKeyStore{client: client}
+}
+
+// MemoryAPIKeyStore keeps API keys in process memory
+type MemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]storedAPIKey
+}
+
+// NewMemoryAPIKeyStore creates an empty in-memory API key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{keys: make(map[string]storedAPIKey)}
+}
+
+// Save creates or replaces a key, keeping its recorded last use
+func (s *MemoryAPIKeyStore) Save(ctx context.Context, key *storedAPIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *key
+	saved.LastUsedAt = s.keys[key.ID].LastUsedAt
+	s.keys[key.ID] = saved
+	return nil
+}
+
+// TouchLastUsed records when a key was last used
+func (s *MemoryAPIKeyStore) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &at
+	s.keys[id] = key
+	return nil
+}
+
+// Update changes an existing key in one step
+func (s *MemoryAPIKeyStore) Update(ctx context.Context, id string, update func(key *storedAPIKey) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return ErrAPIKeyNotFound
+	}
+	lastUsed := key.LastUsedAt
+	if err := update(&key); err != nil {
+		return err
+	}
+	key.LastUsedAt = lastUsed
+	s.keys[id] = key
+	return nil
+}
+
+// Get returns a key by ID
+func (s *MemoryAPIKeyStore) Get(ctx context.Context, id string) (*storedAPIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return nil, ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+// List returns all keys
+func (s *MemoryAPIKeyStore) List(ctx context.Context) ([]*storedAPIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*storedAPIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		key := key
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+// RedisAPIKeyStore keeps API keys in Redis as JSON documents, with the last
+// use of each key in a key of its own
+type RedisAPIKeyStore struct {
+	client *redis.Client
+}
+
+// Save creates or replaces a key. Its last use is not part of the document.
+func (s *RedisAPIKeyStore) Save(ctx context.Context, key *storedAPIKey) error {
+	doc := *key
+	doc.LastUsedAt = nil
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, apiKeyRedisPrefix+key.ID, data, 0)
+	pipe.SAdd(ctx, apiKeyIndexKey, key.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store api key: %w", err)
+	}
+	return nil
+}
+
+// Get returns a key by ID
+func (s *RedisAPIKeyStore) Get(ctx context.Context, id string) (*storedAPIKey, error) {
+	values, err := s.client.MGet(ctx, apiKeyRedisPrefix+id, apiKeyLastUsedRedisPrefix+id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load api key: %w", err)
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	var key storedAPIKey
+	if err := json.Unmarshal([]byte(data), &key); err != nil {
+		return nil, fmt.Errorf("failed to decode api key: %w", err)
+	}
+	if lastUsed, ok := values[1].(string); ok {
+		if at, err := time.Parse(time.RFC3339Nano, lastUsed); err == nil {
+			key.LastUsedAt = &at
+		}
+	}
+	return &key, nil
+}
+
+// TouchLastUsed records when a key was last used
+func (s *RedisAPIKeyStore) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	if err := s.client.Set(ctx, apiKeyLastUsedRedisPrefix+id, at.UTC().Format(time.RFC3339Nano), 0).Err(); err != nil {
+		return fmt.Errorf("failed to store api key last used: %w", err)
+	}
+	return nil
+}
+
+// Update changes an existing key in a WATCH/MULTI transaction, retried when
+// the key changed in between
+func (s *RedisAPIKeyStore) Update(ctx context.Context, id string, update func(key *storedAPIKey) error) error {
+	redisKey := apiKeyRedisPrefix + id
+	for attempt := 0; attempt < apiKeyUpdateAttempts; attempt++ {
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			data, err := tx.Get(ctx, redisKey).Bytes()
+			if err == redis.Nil {
+				return ErrAPIKeyNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to load api key: %w", err)
+			}
+
+			var key storedAPIKey
+			if err := json.Unmarshal(data, &key); err != nil {
+				return fmt.Errorf("failed to decode api key: %w", err)
+			}
+			if err := update(&key); err != nil {
+				return err
+			}
+			key.LastUsedAt = nil
+			if data, err = json.Marshal(key); err != nil {
+				return fmt.Errorf("failed to encode api key: %w", err)
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, redisKey, data, 0)
+				return nil
+			})
+			return err
+		}, redisKey)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to update api key %s: changed concurrently too often", id)
+}
+
+// List returns all keys
+func (s *RedisAPIKeyStore) List(ctx context.Context) ([]*storedAPIKey, error) {
+	ids, err := s.client.SMembers(ctx, apiKeyIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]*storedAPIKey, 0, len(ids))
+	for _, id := range ids {
+		key, err := s.Get(ctx, id)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// CreateAPIKeyRequest represents a request to issue a new API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	OwnerID   string     `json:"owner_id" binding:"required"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rate_limit"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyManager issues, verifies, rotates and revokes API keys
+type APIKeyManager struct {
+	store  APIKeyStore
+	logger *zap.Logger
+}
+
+// NewAPIKeyManager creates a new API key manager
+func NewAPIKeyManager(store APIKeyStore, logger *zap.Logger) *APIKeyManager {
+	return &APIKeyManager{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Create issues a new key and returns its metadata together with the plaintext key
+func (m *APIKeyManager) Create(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, string, error) {
+	id, secret, err := m.generateUniqueKey(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	key := &storedAPIKey{
+		APIKey: APIKey{
+			ID:        id,
+			Name:      req.Name,
+			OwnerID:   req.OwnerID,
+			Scopes:    req.Scopes,
+			RateLimit: req.RateLimit,
+			CreatedAt: now,
+			ExpiresAt: req.ExpiresAt,
+		},
+		KeyHash: hashAPIKey(secret),
+	}
+
+	if err := m.store.Save(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return &key.APIKey, secret, nil
+}
+
+// Authenticate verifies a plaintext key and returns its metadata
+func (m *APIKeyManager) Authenticate(ctx context.Context, secret string) (*APIKey, error) {
+	id, err := parseAPIKeyID(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashAPIKey(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(key.KeyHash)) != 1 {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	now := time.Now().UTC()
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	// Avoid a write per request, last-used only needs minute precision
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedInterval {
+		key.LastUsedAt = &now
+		if err := m.store.TouchLastUsed(ctx, id, now); err != nil {
+			m.logger.Warn("Failed to update api key last used", zap.String("api_key_id", id), zap.Error(err))
+		}
+	}
+
+	return &key.APIKey, nil
+}
+
+// List returns all keys, optionally filtered by owner
+func (m *APIKeyManager) List(ctx context.Context, ownerID string) ([]APIKey, error) {
+	stored, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]APIKey, 0, len(stored))
+	for _, key := range stored {
+		if ownerID == "" || key.OwnerID == ownerID {
+			keys = append(keys, key.APIKey)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Rotate issues a replacement key with the same owner, scopes and limits.
+// The old key keeps working for the grace period so partners can roll over.
+func (m *APIKeyManager) Rotate(ctx context.Context, id string, grace time.Duration) (*APIKey, string, error) {
+	old, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if old.RevokedAt != nil {
+		return nil, "", ErrAPIKeyRevoked
+	}
+
+	req := CreateAPIKeyRequest{
+		Name:      old.Name,
+		OwnerID:   old.OwnerID,
+		Scopes:    old.Scopes,
+		RateLimit: old.RateLimit,
+		ExpiresAt: old.ExpiresAt,
+	}
+
+	key, secret, err := m.Create(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now().UTC()
+	err = m.store.Update(ctx, id, func(old *storedAPIKey) error {
+		if old.RevokedAt != nil {
+			return ErrAPIKeyRevoked
+		}
+		old.RotatedTo = key.ID
+		if grace > 0 {
+			expiresAt := now.Add(grace)
+			if old.ExpiresAt == nil || expiresAt.Before(*old.ExpiresAt) {
+				old.ExpiresAt = &expiresAt
+			}
+		} else {
+			old.RevokedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		// The replacement of a key revoked in the meantime must not work either
+		if revokeErr := m.Revoke(ctx, key.ID); revokeErr != nil {
+			m.logger.Error("Failed to revoke replacement api key", zap.String("api_key_id", key.ID), zap.Error(revokeErr))
+		}
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// Revoke permanently disables a key
+func (m *APIKeyManager) Revoke(ctx context.Context, id string) error {
+	return m.store.Update(ctx, id, func(key *storedAPIKey) error {
+		if key.RevokedAt == nil {
+			now := time.Now().UTC()
+			key.RevokedAt = &now
+		}
+		return nil
+	})
+}
+
+// generateUniqueKey generates a key whose ID is not in use yet
+func (m *APIKeyManager) generateUniqueKey(ctx context.Context) (string, string, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		id, secret, err := generateAPIKey()
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := m.store.Get(ctx, id); errors.Is(err, ErrAPIKeyNotFound) {
+			return id, secret, nil
+		} else if err != nil {
+			return "", "", err
+		}
+	}
+	return "", "", fmt.Errorf("failed to generate a unique api key id")
+}
+
+// generateAPIKey returns a new key ID and the full plaintext key "dsk_<id>_<secret>"
+func generateAPIKey() (string, string, error) {
+	idBytes := make([]byte, 4)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	id := APIKeyPrefix + "_" + hex.EncodeToString(idBytes)
+	return id, id + "_" + base64.RawURLEncoding.EncodeToString(secretBytes), nil
+}
+
+// parseAPIKeyID extracts the visible ID from a plaintext key
+func parseAPIKeyID(secret string) (string, error) {
+	parts := strings.SplitN(secret, "_", 3)
+	if len(parts) != 3 || parts[0] != APIKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", ErrAPIKeyMalformed
+	}
+	return parts[0] + "_" + parts[1], nil
+}
+
+// hashAPIKey hashes a plaintext key. Keys carry 256 bits of entropy, so a
+// plain SHA-256 is sufficient and keeps verification cheap on every request.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAPIKeyManager_Lifecycle(t *testing.T) {
+	manager := NewAPIKeyManager(NewMemoryAPIKeyStore(), zap.NewNop())
+	ctx := context.Background()
+
+	key, secret, err := manager.Create(ctx, CreateAPIKeyRequest{
+		Name:    "Force plate sync",
+		OwnerID: "club-42",
+		Scopes:  []string{"metrics:write"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, key.ID+"_"))
+
+	authenticated, err := manager.Authenticate(ctx, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "club-42", authenticated.OwnerID)
+	assert.True(t, authenticated.HasScope("metrics:write"))
+	assert.False(t, authenticated.HasScope("admin"))
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	_, err = manager.Authenticate(ctx, key.ID+"_wrong-secret")
+	assert.Equal(t, ErrAPIKeyInvalid, err)
+
+	_, err = manager.Authenticate(ctx, "not-a-key")
+	assert.Equal(t, ErrAPIKeyMalformed, err)
+
+	// Rotation with a grace period keeps the old key valid for now
+	rotated, newSecret, err := manager.Rotate(ctx, key.ID, time.Hour)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key.ID, rotated.ID)
+	assert.Equal(t, key.Scopes, rotated.Scopes)
+
+	_, err = manager.Authenticate(ctx, secret)
+	assert.NoError(t, err)
+	_, err = manager.Authenticate(ctx, newSecret)
+	assert.NoError(t, err)
+
+	assert.NoError(t, manager.Revoke(ctx, key.ID))
+	_, err = manager.Authenticate(ctx, secret)
+	assert.Equal(t, ErrAPIKeyRevoked, err)
+
+	keys, err := manager.List(ctx, "club-42")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestAPIKeyManager_Expired(t *testing.T) {
+	manager := NewAPIKeyManager(NewMemoryAPIKeyStore(), zap.NewNop())
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(-time.Minute)
+	_, secret, err := manager.Create(ctx, CreateAPIKeyRequest{
+		Name:      "Expired",
+		OwnerID:   "club-42",
+		ExpiresAt: &expiresAt,
+	})
+	assert.NoError(t, err)
+
+	_, err = manager.Authenticate(ctx, secret)
+	assert.Equal(t, ErrAPIKeyExpired, err)
+}
+
+// revokingStore revokes a key right after Authenticate read it, as a
+// concurrent admin request would
+type revokingStore struct {
+	*MemoryAPIKeyStore
+	revoke func()
+}
+
+func (s *revokingStore) Get(ctx context.Context, id string) (*storedAPIKey, error) {
+	key, err := s.MemoryAPIKeyStore.Get(ctx, id)
+	if revoke := s.revoke; revoke != nil {
+		s.revoke = nil
+		revoke()
+	}
+	return key, err
+}
+
+func TestAPIKeyManager_LastUsedKeepsConcurrentRevocation(t *testing.T) {
+	store := &revokingStore{MemoryAPIKeyStore: NewMemoryAPIKeyStore()}
+	manager := NewAPIKeyManager(store, zap.NewNop())
+	ctx := context.Background()
+
+	key, secret, err := manager.Create(ctx, CreateAPIKeyRequest{Name: "Force plate sync", OwnerID: "club-42"})
+	assert.NoError(t, err)
+
+	store.revoke = func() { assert.NoError(t, manager.Revoke(ctx, key.ID)) }
+	_, err = manager.Authenticate(ctx, secret)
+	assert.NoError(t, err, "the key was valid when it was read")
+
+	_, err = manager.Authenticate(ctx, secret)
+	assert.Equal(t, ErrAPIKeyRevoked, err)
+
+	stored, err := store.Get(ctx, key.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, stored.RevokedAt)
+	assert.NotNil(t, stored.LastUsedAt)
+}
+
+func TestAPIKeyManager_RotateKeepsConcurrentRevocation(t *testing.T) {
+	store := &revokingStore{MemoryAPIKeyStore: NewMemoryAPIKeyStore()}
+	manager := NewAPIKeyManager(store, zap.NewNop())
+	ctx := context.Background()
+
+	key, _, err := manager.Create(ctx, CreateAPIKeyRequest{Name: "Force plate sync", OwnerID: "club-42"})
+	assert.NoError(t, err)
+
+	store.revoke = func() { assert.NoError(t, manager.Revoke(ctx, key.ID)) }
+	_, _, err = manager.Rotate(ctx, key.ID, time.Hour)
+	assert.Equal(t, ErrAPIKeyRevoked, err)
+
+	stored, err := store.Get(ctx, key.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, stored.RevokedAt)
+	assert.Empty(t, stored.RotatedTo)
+
+	// The replacement created before the revocation was seen is revoked too
+	keys, err := store.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	for _, key := range keys {
+		assert.NotNil(t, key.RevokedAt)
+	}
+}
@@ -0,0 +1,577 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/logging"
+)
+
+// BanKind is the type of subject a ban applies to
+type BanKind string
+
+const (
+	BanKindIP   BanKind = "ip"
+	BanKindUser BanKind = "user"
+)
+
+// FailureCategory groups failures that count towards the same threshold
+type FailureCategory string
+
+const (
+	FailureAuth      FailureCategory = "auth"       // invalid JWTs, API keys and sign-in failures
+	FailureRateLimit FailureCategory = "rate_limit" // requests rejected by rate limiting
+)
+
+const (
+	banKeyPrefix     = "ban:"
+	banIndexKey      = "bans"
+	banFailurePrefix = "ban_failures:"
+	banStrikesPrefix = "ban_strikes:"
+
+	// maxMemoryBanCounters is the map size at which expired in-memory counters are swept
+	maxMemoryBanCounters = 10000
+	// maxBanEscalations is the strike from which bans stop growing even
+	// without a MaxDuration
+	maxBanEscalations = 16
+)
+
+// ErrBanNotFound is returned when lifting a ban that does not exist
+var ErrBanNotFound = errors.New("ban not found")
+
+// BanConfig controls automatic temporary bans
+type BanConfig struct {
+	Enabled          bool          `json:"enabled"`
+	MaxAuthFailures  int           `json:"max_auth_failures"`   // auth failures within Window before a ban
+	MaxRateLimitHits int           `json:"max_rate_limit_hits"` // rate-limit rejections within Window before a ban
+	Window           time.Duration `json:"window"`
+	BaseDuration     time.Duration `json:"base_duration"` // first ban, doubled for every repeat offence
+	MaxDuration      time.Duration `json:"max_duration"`
+	StrikeMemory     time.Duration `json:"strike_memory"` // how long previous bans count towards escalation
+}
+
+// DefaultBanConfig returns sensible fail2ban-style defaults
+func DefaultBanConfig() BanConfig {
+	return BanConfig{
+		Enabled:          true,
+		MaxAuthFailures:  10,
+		MaxRateLimitHits: 100,
+		Window:           10 * time.Minute,
+		BaseDuration:     15 * time.Minute,
+		MaxDuration:      24 * time.Hour,
+		StrikeMemory:     7 * 24 * time.Hour,
+	}
+}
+
+// Ban is an active temporary ban
+type Ban struct {
+	Kind      BanKind   `json:"kind"`
+	Subject   string    `json:"subject"`
+	Reason    string    `json:"reason"`
+	Strikes   int       `json:"strikes"`
+	BannedAt  time.Time `json:"banned_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BanStore persists failure counters, strikes and bans
+type BanStore interface {
+	// RecordFailure counts a failure in the current window and returns the total
+	RecordFailure(ctx context.Context, kind BanKind, subject string, category FailureCategory, window time.Duration) (int, error)
+	// ResetFailures clears the failure counters of a subject
+	ResetFailures(ctx context.Context, kind BanKind, subject string) error
+	// Ban bans a subject without an active ban in one step: it adds a strike,
+	// remembered for memory, stores ban with the strikes and the duration of
+	// the strike and resets the subject's failure counters. durations[i] is
+	// the duration of strike i+1, the last one also that of any later strike.
+	// It returns nil when the subject is already banned.
+	Ban(ctx context.Context, ban Ban, memory time.Duration, durations []time.Duration) (*Ban, error)
+	// Get returns the active ban of a subject, or nil
+	Get(ctx context.Context, kind BanKind, subject string) (*Ban, error)
+	// List returns all active bans
+	List(ctx context.Context) ([]Ban, error)
+	// Delete lifts a ban
+	Delete(ctx context.Context, kind BanKind, subject string) error
+}
+
+// NewBanStore creates a Redis-backed ban store, or an in-memory one when no Redis client is configured
+func NewBanStore(client *redis.Client) BanStore {
+	if client == nil {
+		return NewMemoryBanStore()
+	}
+	return &RedisBanStore{client: client}
+}
+
+func banSubjectKey(kind BanKind, subject string) string {
+	return string(kind) + ":" + subject
+}
+
+// expiringCounter is a fixed-window counter
+type expiringCounter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryBanStore keeps bans in process memory
+type MemoryBanStore struct {
+	mu       sync.Mutex
+	failures map[string]*expiringCounter
+	strikes  map[string]*expiringCounter
+	bans     map[string]Ban
+}
+
+// NewMemoryBanStore creates an empty in-memory ban store
+func NewMemoryBanStore() *MemoryBanStore {
+	return &MemoryBanStore{
+		failures: make(map[string]*expiringCounter),
+		strikes:  make(map[string]*expiringCounter),
+		bans:     make(map[string]Ban),
+	}
+}
+
+// RecordFailure counts a failure in the current window
+func (s *MemoryBanStore) RecordFailure(ctx context.Context, kind BanKind, subject string, category FailureCategory, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.increment(s.failures, banSubjectKey(kind, subject)+":"+string(category), window), nil
+}
+
+// ResetFailures clears the failure counters of a subject
+func (s *MemoryBanStore) ResetFailures(ctx context.Context, kind BanKind, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resetFailures(banSubjectKey(kind, subject))
+	return nil
+}
+
+// Ban bans a subject without an active ban
+func (s *MemoryBanStore) Ban(ctx context.Context, ban Ban, memory time.Duration, durations []time.Duration) (*Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := banSubjectKey(ban.Kind, ban.Subject)
+	if active, exists := s.bans[key]; exists && time.Now().Before(active.ExpiresAt) {
+		return nil, nil
+	}
+	ban.Strikes = s.increment(s.strikes, key, memory)
+	ban.ExpiresAt = ban.BannedAt.Add(strikeDuration(durations, ban.Strikes))
+	s.bans[key] = ban
+	s.resetFailures(key)
+	return &ban, nil
+}
+
+// Get returns the active ban of a subject, or nil
+func (s *MemoryBanStore) Get(ctx context.Context, kind BanKind, subject string) (*Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := banSubjectKey(kind, subject)
+	ban, exists := s.bans[key]
+	if !exists {
+		return nil, nil
+	}
+	if time.Now().After(ban.ExpiresAt) {
+		delete(s.bans, key)
+		return nil, nil
+	}
+	return &ban, nil
+}
+
+// List returns all active bans
+func (s *MemoryBanStore) List(ctx context.Context) ([]Ban, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	bans := make([]Ban, 0, len(s.bans))
+	for key, ban := range s.bans {
+		if now.After(ban.ExpiresAt) {
+			delete(s.bans, key)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	return bans, nil
+}
+
+// Delete lifts a ban
+func (s *MemoryBanStore) Delete(ctx context.Context, kind BanKind, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := banSubjectKey(kind, subject)
+	if _, exists := s.bans[key]; !exists {
+		return ErrBanNotFound
+	}
+	delete(s.bans, key)
+	return nil
+}
+
+// resetFailures clears the failure counters of a subject key. Callers must
+// hold the lock.
+func (s *MemoryBanStore) resetFailures(key string) {
+	prefix := key + ":"
+	for counter := range s.failures {
+		if strings.HasPrefix(counter, prefix) {
+			delete(s.failures, counter)
+		}
+	}
+}
+
+// increment bumps a fixed-window counter. Callers must hold the lock.
+func (s *MemoryBanStore) increment(counters map[string]*expiringCounter, key string, ttl time.Duration) int {
+	now := time.Now()
+	if len(counters) >= maxMemoryBanCounters {
+		for k, c := range counters {
+			if now.After(c.expiresAt) {
+				delete(counters, k)
+			}
+		}
+	}
+
+	counter, exists := counters[key]
+	if !exists || now.After(counter.expiresAt) {
+		counter = &expiringCounter{expiresAt: now.Add(ttl)}
+		counters[key] = counter
+	}
+	counter.count++
+	return counter.count
+}
+
+// RedisBanStore keeps bans in Redis so every replica enforces them
+type RedisBanStore struct {
+	client *redis.Client
+}
+
+// RecordFailure counts a failure in the current window
+func (s *RedisBanStore) RecordFailure(ctx context.Context, kind BanKind, subject string, category FailureCategory, window time.Duration) (int, error) {
+	return s.increment(ctx, banFailurePrefix+banSubjectKey(kind, subject)+":"+string(category), window)
+}
+
+// ResetFailures clears the failure counters of a subject
+func (s *RedisBanStore) ResetFailures(ctx context.Context, kind BanKind, subject string) error {
+	prefix := banFailurePrefix + banSubjectKey(kind, subject) + ":"
+	if err := s.client.Del(ctx, prefix+string(FailureAuth), prefix+string(FailureRateLimit)).Err(); err != nil {
+		return fmt.Errorf("failed to reset failures: %w", err)
+	}
+	return nil
+}
+
+// banScript bans a subject without an active ban: it adds a strike, stores
+// the ban with the duration of that strike and resets the failure counters in
+// one step, so concurrent failures crossing the threshold strike only once
+//
+// KEYS[1] = ban key, KEYS[2] = strikes key, KEYS[3] = ban index, KEYS[4...] = failure counters
+// ARGV[1] = index member, ARGV[2] = strike memory (milliseconds), ARGV[3] = ban without strikes and expiry (JSON),
+// ARGV[4...] = duration (milliseconds) and expiry (RFC 3339) of each strike
+var banScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return false
+end
+
+local strikes = redis.call('INCR', KEYS[2])
+if strikes == 1 or redis.call('PTTL', KEYS[2]) < 0 then
+	redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+
+local step = math.min(strikes, (#ARGV - 3) / 2)
+local ban = cjson.decode(ARGV[3])
+ban.strikes = strikes
+ban.expires_at = ARGV[3 + 2 * step]
+local data = cjson.encode(ban)
+redis.call('SET', KEYS[1], data, 'PX', ARGV[2 + 2 * step])
+redis.call('SADD', KEYS[3], ARGV[1])
+redis.call('DEL', unpack(KEYS, 4))
+return data
+`)
+
+// Ban bans a subject without an active ban
+func (s *RedisBanStore) Ban(ctx context.Context, ban Ban, memory time.Duration, durations []time.Duration) (*Ban, error) {
+	template, err := json.Marshal(ban)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ban: %w", err)
+	}
+
+	key := banSubjectKey(ban.Kind, ban.Subject)
+	failures := banFailurePrefix + key + ":"
+	args := []interface{}{key, memory.Milliseconds(), template}
+	for _, duration := range durations {
+		args = append(args, duration.Milliseconds(), ban.BannedAt.Add(duration).Format(time.RFC3339Nano))
+	}
+	data, err := banScript.Run(ctx, s.client,
+		[]string{banKeyPrefix + key, banStrikesPrefix + key, banIndexKey, failures + string(FailureAuth), failures + string(FailureRateLimit)},
+		args...,
+	).Text()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to store ban: %w", err)
+	}
+
+	var stored Ban
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode ban: %w", err)
+	}
+	return &stored, nil
+}
+
+// Get returns the active ban of a subject, or nil
+func (s *RedisBanStore) Get(ctx context.Context, kind BanKind, subject string) (*Ban, error) {
+	return s.get(ctx, banSubjectKey(kind, subject))
+}
+
+// List returns all active bans, pruning expired ones from the index
+func (s *RedisBanStore) List(ctx context.Context) ([]Ban, error) {
+	keys, err := s.client.SMembers(ctx, banIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+
+	bans := make([]Ban, 0, len(keys))
+	for _, key := range keys {
+		ban, err := s.get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if ban == nil {
+			s.client.SRem(ctx, banIndexKey, key)
+			continue
+		}
+		bans = append(bans, *ban)
+	}
+	return bans, nil
+}
+
+// Delete lifts a ban
+func (s *RedisBanStore) Delete(ctx context.Context, kind BanKind, subject string) error {
+	key := banSubjectKey(kind, subject)
+	deleted, err := s.client.Del(ctx, banKeyPrefix+key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to lift ban: %w", err)
+	}
+	s.client.SRem(ctx, banIndexKey, key)
+	if deleted == 0 {
+		return ErrBanNotFound
+	}
+	return nil
+}
+
+func (s *RedisBanStore) get(ctx context.Context, key string) (*Ban, error) {
+	data, err := s.client.Get(ctx, banKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ban: %w", err)
+	}
+
+	var ban Ban
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return nil, fmt.Errorf("failed to decode ban: %w", err)
+	}
+	return &ban, nil
+}
+
+// fixedWindowScript increments a counter and starts its window on the first
+// hit in one step, so a counter can never be left without an expiry. A counter
+// found without one, e.g. written by an older release, gets one too.
+//
+// KEYS[1] = counter key
+// ARGV[1] = window (milliseconds)
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 or redis.call('PTTL', KEYS[1]) < 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// increment bumps a fixed-window counter, starting the window on the first hit
+func (s *RedisBanStore) increment(ctx context.Context, key string, ttl time.Duration) (int, error) {
+	count, err := fixedWindowScript.Run(ctx, s.client, []string{key}, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment %s: %w", key, err)
+	}
+	return int(count), nil
+}
+
+// BanManager turns repeated failures into escalating temporary bans
+type BanManager struct {
+	store  BanStore
+	config BanConfig
+	logger *zap.Logger
+	events *logging.Logger
+}
+
+// NewBanManager creates a new ban manager
+func NewBanManager(store BanStore, config BanConfig, logger *zap.Logger) *BanManager {
+	return &BanManager{
+		store:  store,
+		config: config,
+		logger: logger,
+		events: logging.NewFromZap(logger),
+	}
+}
+
+// RecordFailure counts a failure against the client IP and, when known, the user.
+// Subjects that cross the threshold for the category are banned.
+func (m *BanManager) RecordFailure(ctx context.Context, category FailureCategory, reason, ip, userID string) {
+	if !m.config.Enabled {
+		return
+	}
+
+	if ip != "" {
+		m.recordFailure(ctx, BanKindIP, ip, category, reason, ip, userID)
+	}
+	if userID != "" {
+		m.recordFailure(ctx, BanKindUser, userID, category, reason, ip, userID)
+	}
+}
+
+// RecordSuccess clears the failure counters after a successful sign-in
+func (m *BanManager) RecordSuccess(ctx context.Context, ip, userID string) {
+	if !m.config.Enabled {
+		return
+	}
+
+	if ip != "" {
+		m.store.ResetFailures(ctx, BanKindIP, ip)
+	}
+	if userID != "" {
+		m.store.ResetFailures(ctx, BanKindUser, userID)
+	}
+}
+
+// Banned returns the active ban for a subject, or nil. Store errors fail open.
+func (m *BanManager) Banned(ctx context.Context, kind BanKind, subject string) *Ban {
+	if !m.config.Enabled || subject == "" {
+		return nil
+	}
+
+	ban, err := m.store.Get(ctx, kind, subject)
+	if err != nil {
+		m.logger.Error("Ban lookup failed", zap.String("kind", string(kind)), zap.Error(err))
+		return nil
+	}
+	return ban
+}
+
+// List returns all active bans, soonest expiry first
+func (m *BanManager) List(ctx context.Context) ([]Ban, error) {
+	bans, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].ExpiresAt.Before(bans[j].ExpiresAt) })
+	return bans, nil
+}
+
+// Lift removes a ban and its failure counters
+func (m *BanManager) Lift(ctx context.Context, kind BanKind, subject, liftedBy string) error {
+	if err := m.store.Delete(ctx, kind, subject); err != nil {
+		return err
+	}
+	m.store.ResetFailures(ctx, kind, subject)
+
+	ip, userID := banEventSubjects(kind, subject)
+	m.events.LogSecurityEvent(ctx, "ban_lifted", userID, ip, map[string]interface{}{
+		"ban_kind":  string(kind),
+		"lifted_by": liftedBy,
+	})
+	return nil
+}
+
+func (m *BanManager) recordFailure(ctx context.Context, kind BanKind, subject string, category FailureCategory, reason, ip, userID string) {
+	threshold := m.config.MaxAuthFailures
+	if category == FailureRateLimit {
+		threshold = m.config.MaxRateLimitHits
+	}
+	if threshold <= 0 {
+		return
+	}
+
+	count, err := m.store.RecordFailure(ctx, kind, subject, category, m.config.Window)
+	if err != nil {
+		m.logger.Error("Failed to record security failure", zap.String("kind", string(kind)), zap.Error(err))
+		return
+	}
+	if count < threshold {
+		return
+	}
+
+	ban, err := m.store.Ban(ctx, Ban{
+		Kind:     kind,
+		Subject:  subject,
+		Reason:   reason,
+		BannedAt: time.Now().UTC(),
+	}, m.config.StrikeMemory, m.banDurations())
+	if err != nil {
+		m.logger.Error("Failed to store ban", zap.String("kind", string(kind)), zap.Error(err))
+		return
+	}
+	if ban == nil {
+		// Banned by a concurrent failure already
+		return
+	}
+
+	m.events.LogSecurityEvent(ctx, "subject_banned", userID, ip, map[string]interface{}{
+		"ban_kind":   string(kind),
+		"reason":     reason,
+		"category":   string(category),
+		"failures":   count,
+		"strikes":    ban.Strikes,
+		"expires_at": ban.ExpiresAt,
+	})
+}
+
+// banDuration doubles the base duration for every strike, capped at MaxDuration
+func (m *BanManager) banDuration(strikes int) time.Duration {
+	duration := float64(m.config.BaseDuration) * math.Pow(2, float64(strikes-1))
+	if m.config.MaxDuration > 0 && duration > float64(m.config.MaxDuration) {
+		return m.config.MaxDuration
+	}
+	return time.Duration(duration)
+}
+
+// banDurations lists the ban duration of every strike up to the one that
+// reaches MaxDuration
+func (m *BanManager) banDurations() []time.Duration {
+	durations := make([]time.Duration, 0, maxBanEscalations)
+	for strikes := 1; strikes <= maxBanEscalations; strikes++ {
+		duration := m.banDuration(strikes)
+		durations = append(durations, duration)
+		if m.config.MaxDuration > 0 && duration >= m.config.MaxDuration {
+			break
+		}
+	}
+	return durations
+}
+
+// strikeDuration returns the duration of a strike from the durations of
+// BanStore.Ban
+func strikeDuration(durations []time.Duration, strikes int) time.Duration {
+	if strikes > len(durations) {
+		strikes = len(durations)
+	}
+	return durations[strikes-1]
+}
+
+// banEventSubjects maps a ban subject to the IP and user fields of a security event
+func banEventSubjects(kind BanKind, subject string) (string, string) {
+	if kind == BanKindIP {
+		return subject, ""
+	}
+	return "", subject
+}
@@ -0,0 +1,122 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestBanManager_EscalatingBans(t *testing.T) {
+	config := DefaultBanConfig()
+	config.MaxAuthFailures = 3
+	manager := NewBanManager(NewMemoryBanStore(), config, zap.NewNop())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		manager.RecordFailure(ctx, FailureAuth, "invalid_jwt", "10.0.0.1", "")
+	}
+	assert.Nil(t, manager.Banned(ctx, BanKindIP, "10.0.0.1"))
+
+	manager.RecordFailure(ctx, FailureAuth, "invalid_jwt", "10.0.0.1", "")
+	first := manager.Banned(ctx, BanKindIP, "10.0.0.1")
+	if assert.NotNil(t, first) {
+		assert.Equal(t, 1, first.Strikes)
+		assert.WithinDuration(t, time.Now().Add(config.BaseDuration), first.ExpiresAt, time.Second)
+	}
+
+	assert.NoError(t, manager.Lift(ctx, BanKindIP, "10.0.0.1", "admin"))
+	assert.Nil(t, manager.Banned(ctx, BanKindIP, "10.0.0.1"))
+	assert.Equal(t, ErrBanNotFound, manager.Lift(ctx, BanKindIP, "10.0.0.1", "admin"))
+
+	for i := 0; i < 3; i++ {
+		manager.RecordFailure(ctx, FailureAuth, "invalid_jwt", "10.0.0.1", "")
+	}
+	second := manager.Banned(ctx, BanKindIP, "10.0.0.1")
+	if assert.NotNil(t, second) {
+		assert.Equal(t, 2, second.Strikes)
+		assert.WithinDuration(t, time.Now().Add(2*config.BaseDuration), second.ExpiresAt, time.Second)
+	}
+
+	assert.Equal(t, config.MaxDuration, manager.banDuration(20))
+}
+
+func TestBanManager_ConcurrentFailuresStrikeOnce(t *testing.T) {
+	config := DefaultBanConfig()
+	config.MaxAuthFailures = 3
+	manager := NewBanManager(NewMemoryBanStore(), config, zap.NewNop())
+	ctx := context.Background()
+
+	// A burst well past the threshold is one offence, not one per failure
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.RecordFailure(ctx, FailureAuth, "invalid_jwt", "10.0.0.1", "")
+		}()
+	}
+	wg.Wait()
+
+	ban := manager.Banned(ctx, BanKindIP, "10.0.0.1")
+	if assert.NotNil(t, ban) {
+		assert.Equal(t, 1, ban.Strikes)
+		assert.WithinDuration(t, time.Now().Add(config.BaseDuration), ban.ExpiresAt, time.Second)
+	}
+}
+
+func TestBanManager_BanDurations(t *testing.T) {
+	manager := NewBanManager(NewMemoryBanStore(), DefaultBanConfig(), zap.NewNop())
+	durations := manager.banDurations()
+	assert.Equal(t, 15*time.Minute, durations[0])
+	assert.Equal(t, 24*time.Hour, durations[len(durations)-1])
+	assert.Equal(t, 24*time.Hour, strikeDuration(durations, 40))
+
+	manager.config.MaxDuration = 0
+	assert.Len(t, manager.banDurations(), maxBanEscalations)
+}
+
+func TestIPFilter_BannedIP(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	for i := 0; i < DefaultBanConfig().MaxAuthFailures; i++ {
+		assert.Equal(t, http.StatusUnauthorized, performJWTRequest(sm, "not-a-token"))
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ping", sm.IPFilter(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestJWTAuth_ReplayedRevokedTokenDoesNotBanOwner(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	token := signTestToken(t, jwt.MapClaims{
+		"sub": "user-123",
+		"jti": "stolen",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	ctx := context.Background()
+	assert.NoError(t, sm.Revocations().RevokeToken(ctx, "stolen", time.Now().Add(time.Hour)))
+
+	for i := 0; i < 2*DefaultBanConfig().MaxAuthFailures; i++ {
+		performJWTRequest(sm, token)
+	}
+	// The replaying client is banned, the owner of the token is not
+	assert.NotNil(t, sm.bans.Banned(ctx, BanKindIP, "192.0.2.1"))
+	assert.Nil(t, sm.bans.Banned(ctx, BanKindUser, "user-123"))
+}
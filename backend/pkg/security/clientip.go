@@ -0,0 +1,202 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clientIPContextKey is where TrustedProxies stores the resolved client address
+const clientIPContextKey = "client_ip"
+
+// ClientIPResolver determines the real client address of a request. Forwarding
+// headers are only honoured when they were added by a trusted proxy, so clients
+// cannot spoof their address by sending the headers themselves.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver creates a resolver trusting the given proxy CIDRs or plain IPs
+func NewClientIPResolver(proxies []string) (*ClientIPResolver, error) {
+	trusted, err := parseTrustedProxies(proxies)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientIPResolver{trusted: trusted}, nil
+}
+
+// Resolve returns the client IP of a request.
+// The proxy chain is walked from the nearest hop outwards and the first address
+// that is not a trusted proxy is the client. Forwarded (RFC 7239) takes precedence
+// over X-Forwarded-For, and X-Real-IP is used when neither is present.
+func (r *ClientIPResolver) Resolve(req *http.Request) string {
+	remote := parseForwardedIP(req.RemoteAddr)
+	if remote == nil {
+		return strings.TrimSpace(req.RemoteAddr)
+	}
+	if !r.IsTrusted(remote) {
+		return remote.String()
+	}
+
+	chain, ok := forwardedChain(req.Header)
+	if !ok {
+		chain, ok = xForwardedForChain(req.Header)
+	}
+	if !ok {
+		if realIP := parseForwardedIP(req.Header.Get("X-Real-IP")); realIP != nil {
+			return realIP.String()
+		}
+		return remote.String()
+	}
+
+	hop := remote
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseForwardedIP(chain[i])
+		if ip == nil {
+			// Everything beyond a malformed entry is untrustworthy
+			return hop.String()
+		}
+		hop = ip
+		if !r.IsTrusted(ip) {
+			return ip.String()
+		}
+	}
+
+	// Every hop is a trusted proxy, so the request originated inside the network
+	return hop.String()
+}
+
+// IsTrusted reports whether ip belongs to a trusted proxy
+func (r *ClientIPResolver) IsTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware resolves the client IP once per request. It stores the address in the
+// context and rewrites RemoteAddr so that c.ClientIP and any handler reading
+// RemoteAddr see the client rather than the proxy.
+func (r *ClientIPResolver) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientIP := r.Resolve(c.Request)
+		c.Set(clientIPContextKey, clientIP)
+
+		_, port, err := net.SplitHostPort(c.Request.RemoteAddr)
+		if err != nil {
+			port = "0"
+		}
+		c.Request.RemoteAddr = net.JoinHostPort(clientIP, port)
+
+		c.Next()
+	}
+}
+
+// ApplyTrustedProxies configures Gin's own client IP handling with the same proxy
+// list, so routes mounted outside the TrustedProxies middleware agree with it.
+// Gin trusts every proxy by default; an empty list makes it trust none.
+func ApplyTrustedProxies(engine *gin.Engine, proxies []string) error {
+	engine.ForwardedByClientIP = true
+	engine.RemoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+	var trusted []string
+	for _, proxy := range proxies {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	if err := engine.SetTrustedProxies(trusted); err != nil {
+		return fmt.Errorf("failed to set trusted proxies: %w", err)
+	}
+	return nil
+}
+
+// parseTrustedProxies turns CIDRs and plain IPs into networks
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	trusted := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			trusted = append(trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		trusted = append(trusted, network)
+	}
+	return trusted, nil
+}
+
+// forwardedChain extracts the for= addresses of all Forwarded headers, oldest hop first
+func forwardedChain(header http.Header) ([]string, bool) {
+	values := header.Values("Forwarded")
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	var chain []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			node := ""
+			for _, pair := range strings.Split(element, ";") {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(key, "for") {
+					node = strings.Trim(val, `"`)
+				}
+			}
+			// Elements without for= still count as a hop, and an unusable one
+			chain = append(chain, node)
+		}
+	}
+	return chain, true
+}
+
+// xForwardedForChain splits all X-Forwarded-For headers, oldest hop first
+func xForwardedForChain(header http.Header) ([]string, bool) {
+	values := header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	var chain []string
+	for _, value := range values {
+		for _, node := range strings.Split(value, ",") {
+			chain = append(chain, strings.TrimSpace(node))
+		}
+	}
+	return chain, true
+}
+
+// parseForwardedIP parses an address that may carry a port or IPv6 brackets.
+// Obfuscated identifiers and "unknown" yield nil.
+func parseForwardedIP(value string) net.IP {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	return net.ParseIP(value)
+}
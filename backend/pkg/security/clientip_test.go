@@ -0,0 +1,48 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIPResolver_Resolve(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer cannot spoof", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.7"},
+		{"x-forwarded-for via trusted proxy", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.9, 10.0.0.5"}, "198.51.100.9"},
+		{"x-real-ip via trusted proxy", "192.168.1.1:80", map[string]string{"X-Real-IP": "198.51.100.9"}, "198.51.100.9"},
+		{"forwarded takes precedence", "10.0.0.2:80", map[string]string{
+			"Forwarded":       `for="[2001:db8::17]:4711";proto=https, for=10.0.0.9`,
+			"X-Forwarded-For": "1.2.3.4",
+		}, "2001:db8::17"},
+		{"malformed hop stops the walk", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "1.2.3.4, garbage, 10.0.0.5"}, "10.0.0.5"},
+		{"all hops trusted", "10.0.0.2:80", map[string]string{"X-Forwarded-For": "10.1.1.1"}, "10.1.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, resolver.Resolve(req))
+		})
+	}
+}
+
+func TestNewClientIPResolver_InvalidProxy(t *testing.T) {
+	_, err := NewClientIPResolver([]string{"not-a-cidr"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,570 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/audit"
+)
+
+// Device signature headers. The signature is the hex HMAC-SHA256, keyed with the
+// device secret, of the canonical string built by DeviceSigningString.
+const (
+	HeaderDeviceID           = "X-Device-ID"
+	HeaderSignature          = "X-Signature"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureNonce     = "X-Signature-Nonce"
+)
+
+const (
+	deviceRedisPrefix         = "device:"
+	deviceLastSeenRedisPrefix = "device_last_seen:"
+	deviceUserIndexPrefix     = "devices:"
+	deviceNoncePrefix         = "device_nonce:"
+
+	defaultDeviceSignatureSkew = 5 * time.Minute
+	maxSignedBodyBytes         = 10 << 20
+	minNonceLength             = 16
+)
+
+var (
+	ErrDeviceNotFound     = errors.New("device not found")
+	ErrDeviceRevoked      = errors.New("device revoked")
+	ErrSignatureMissing   = errors.New("signature headers missing")
+	ErrSignatureInvalid   = errors.New("signature invalid")
+	ErrSignatureExpired   = errors.New("signature timestamp outside allowed skew")
+	ErrSignatureReplayed  = errors.New("signature nonce already used")
+	ErrSignatureMalformed = errors.New("signature headers malformed")
+)
+
+// Device is an app installation that signs its uploads
+type Device struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Platform   string     `json:"platform"`
+	AppVersion string     `json:"app_version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// storedDevice is the persisted form of a device. HMAC verification needs the
+// secret itself, so unlike API keys it cannot be stored as a hash.
+type storedDevice struct {
+	Device
+	Secret string `json:"secret"`
+}
+
+// DeviceStore persists devices and the nonces they have used. Last contact is
+// recorded apart from the rest of the device, so recording it never undoes a
+// concurrent revocation.
+type DeviceStore interface {
+	Save(ctx context.Context, device *storedDevice) error
+	Get(ctx context.Context, id string) (*storedDevice, error)
+	ListByUser(ctx context.Context, userID string) ([]*storedDevice, error)
+	TouchLastSeen(ctx context.Context, id string, at time.Time) error
+	// UseNonce records a nonce and reports whether it was unused
+	UseNonce(ctx context.Context, deviceID, nonce string, ttl time.Duration) (bool, error)
+}
+
+// NewDeviceStore creates a Redis-backed device store, or an in-memory one when no Redis client is configured
+func NewDeviceStore(client *redis.Client) DeviceStore {
+	if client == nil {
+		return NewMemoryDeviceStore()
+	}
+	return &RedisDeviceStore{client: client}
+}
+
+// MemoryDeviceStore keeps devices in process memory
+type MemoryDeviceStore struct {
+	mu      sync.Mutex
+	devices map[string]storedDevice
+	nonces  map[string]time.Time
+}
+
+// NewMemoryDeviceStore creates an empty in-memory device store
+func NewMemoryDeviceStore() *MemoryDeviceStore {
+	return &MemoryDeviceStore{
+		devices: make(map[string]storedDevice),
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+// Save creates or replaces a device, keeping its recorded last contact
+func (s *MemoryDeviceStore) Save(ctx context.Context, device *storedDevice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *device
+	saved.LastSeenAt = s.devices[device.ID].LastSeenAt
+	s.devices[device.ID] = saved
+	return nil
+}
+
+// TouchLastSeen records when a device last made a signed request
+func (s *MemoryDeviceStore) TouchLastSeen(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, exists := s.devices[id]
+	if !exists {
+		return ErrDeviceNotFound
+	}
+	device.LastSeenAt = &at
+	s.devices[id] = device
+	return nil
+}
+
+// Get returns a device by ID
+func (s *MemoryDeviceStore) Get(ctx context.Context, id string) (*storedDevice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	device, exists := s.devices[id]
+	if !exists {
+		return nil, ErrDeviceNotFound
+	}
+	return &device, nil
+}
+
+// ListByUser returns all devices of a user
+func (s *MemoryDeviceStore) ListByUser(ctx context.Context, userID string) ([]*storedDevice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var devices []*storedDevice
+	for _, device := range s.devices {
+		if device.UserID == userID {
+			device := device
+			devices = append(devices, &device)
+		}
+	}
+	return devices, nil
+}
+
+// UseNonce records a nonce and reports whether it was unused
+func (s *MemoryDeviceStore) UseNonce(ctx context.Context, deviceID, nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, expiresAt := range s.nonces {
+		if now.After(expiresAt) {
+			delete(s.nonces, key)
+		}
+	}
+
+	key := deviceID + ":" + nonce
+	if _, used := s.nonces[key]; used {
+		return false, nil
+	}
+	s.nonces[key] = now.Add(ttl)
+	return true, nil
+}
+
+// RedisDeviceStore keeps devices in Redis as JSON documents, with the last
+// contact of each device in a key of its own
+type RedisDeviceStore struct {
+	client *redis.Client
+}
+
+// Save creates or replaces a device. Its last contact is not part of the document.
+func (s *RedisDeviceStore) Save(ctx context.Context, device *storedDevice) error {
+	doc := *device
+	doc.LastSeenAt = nil
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode device: %w", err)
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, deviceRedisPrefix+device.ID, data, 0)
+	pipe.SAdd(ctx, deviceUserIndexPrefix+device.UserID, device.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store device: %w", err)
+	}
+	return nil
+}
+
+// Get returns a device by ID
+func (s *RedisDeviceStore) Get(ctx context.Context, id string) (*storedDevice, error) {
+	values, err := s.client.MGet(ctx, deviceRedisPrefix+id, deviceLastSeenRedisPrefix+id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load device: %w", err)
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+
+	var device storedDevice
+	if err := json.Unmarshal([]byte(data), &device); err != nil {
+		return nil, fmt.Errorf("failed to decode device: %w", err)
+	}
+	if lastSeen, ok := values[1].(string); ok {
+		if at, err := time.Parse(time.RFC3339Nano, lastSeen); err == nil {
+			device.LastSeenAt = &at
+		}
+	}
+	return &device, nil
+}
+
+// TouchLastSeen records when a device last made a signed request
+func (s *RedisDeviceStore) TouchLastSeen(ctx context.Context, id string, at time.Time) error {
+	if err := s.client.Set(ctx, deviceLastSeenRedisPrefix+id, at.UTC().Format(time.RFC3339Nano), 0).Err(); err != nil {
+		return fmt.Errorf("failed to store device last seen: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns all devices of a user
+func (s *RedisDeviceStore) ListByUser(ctx context.Context, userID string) ([]*storedDevice, error) {
+	ids, err := s.client.SMembers(ctx, deviceUserIndexPrefix+userID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	devices := make([]*storedDevice, 0, len(ids))
+	for _, id := range ids {
+		device, err := s.Get(ctx, id)
+		if errors.Is(err, ErrDeviceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// UseNonce records a nonce and reports whether it was unused
+func (s *RedisDeviceStore) UseNonce(ctx context.Context, deviceID, nonce string, ttl time.Duration) (bool, error) {
+	fresh, err := s.client.SetNX(ctx, deviceNoncePrefix+deviceID+":"+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record nonce: %w", err)
+	}
+	return fresh, nil
+}
+
+// RegisterDeviceRequest represents a request to register an app installation
+type RegisterDeviceRequest struct {
+	Name       string `json:"name"`
+	Platform   string `json:"platform" binding:"required"`
+	AppVersion string `json:"app_version"`
+}
+
+// DeviceResponse is returned when a device is registered.
+// It is the only time the signing secret is visible.
+type DeviceResponse struct {
+	Device
+	Secret string `json:"secret"`
+}
+
+// DeviceManager registers devices and verifies their request signatures
+type DeviceManager struct {
+	store   DeviceStore
+	maxSkew time.Duration
+	logger  *zap.Logger
+}
+
+// NewDeviceManager creates a new device manager
+func NewDeviceManager(store DeviceStore, maxSkew time.Duration, logger *zap.Logger) *DeviceManager {
+	if maxSkew <= 0 {
+		maxSkew = defaultDeviceSignatureSkew
+	}
+	return &DeviceManager{
+		store:   store,
+		maxSkew: maxSkew,
+		logger:  logger,
+	}
+}
+
+// Register creates a device for a user and returns it together with its signing secret
+func (m *DeviceManager) Register(ctx context.Context, userID string, req RegisterDeviceRequest) (*Device, string, error) {
+	idBytes := make([]byte, 12)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate device id: %w", err)
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", fmt.Errorf("failed to generate device secret: %w", err)
+	}
+
+	device := &storedDevice{
+		Device: Device{
+			ID:         "dev_" + hex.EncodeToString(idBytes),
+			UserID:     userID,
+			Name:       req.Name,
+			Platform:   req.Platform,
+			AppVersion: req.AppVersion,
+			CreatedAt:  time.Now().UTC(),
+		},
+		Secret: base64.RawURLEncoding.EncodeToString(secretBytes),
+	}
+	if err := m.store.Save(ctx, device); err != nil {
+		return nil, "", err
+	}
+	return &device.Device, device.Secret, nil
+}
+
+// List returns the devices of a user, oldest first
+func (m *DeviceManager) List(ctx context.Context, userID string) ([]Device, error) {
+	stored, err := m.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]Device, 0, len(stored))
+	for _, device := range stored {
+		devices = append(devices, device.Device)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].CreatedAt.Before(devices[j].CreatedAt) })
+	return devices, nil
+}
+
+// Revoke disables a device of the given user
+func (m *DeviceManager) Revoke(ctx context.Context, userID, id string) error {
+	device, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if device.UserID != userID {
+		return ErrDeviceNotFound
+	}
+	if device.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	device.RevokedAt = &now
+	return m.store.Save(ctx, device)
+}
+
+// Verify checks a signed request from a device belonging to userID, which
+// must be set
+func (m *DeviceManager) Verify(ctx context.Context, userID, deviceID, method, path, timestamp, nonce, signature string, body []byte) (*Device, error) {
+	if deviceID == "" || signature == "" || timestamp == "" || nonce == "" {
+		return nil, ErrSignatureMissing
+	}
+	if len(nonce) < minNonceLength {
+		return nil, ErrSignatureMalformed
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrSignatureMalformed
+	}
+	skew := time.Since(time.Unix(unix, 0))
+	if skew > m.maxSkew || skew < -m.maxSkew {
+		return nil, ErrSignatureExpired
+	}
+
+	provided, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, ErrSignatureMalformed
+	}
+
+	device, err := m.store.Get(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	// Signatures only vouch for uploads of the device's own user, never for
+	// requests without one
+	if userID == "" || device.UserID != userID {
+		return nil, ErrDeviceNotFound
+	}
+	if device.RevokedAt != nil {
+		return nil, ErrDeviceRevoked
+	}
+
+	mac := hmac.New(sha256.New, []byte(device.Secret))
+	mac.Write([]byte(DeviceSigningString(method, path, timestamp, nonce, body)))
+	if !hmac.Equal(mac.Sum(nil), provided) {
+		return nil, ErrSignatureInvalid
+	}
+
+	// Only a correctly signed request may burn a nonce; the nonce must outlive
+	// the whole window in which its timestamp is acceptable
+	fresh, err := m.store.UseNonce(ctx, deviceID, nonce, 2*m.maxSkew)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrSignatureReplayed
+	}
+
+	now := time.Now().UTC()
+	if device.LastSeenAt == nil || now.Sub(*device.LastSeenAt) > time.Minute {
+		device.LastSeenAt = &now
+		if err := m.store.TouchLastSeen(ctx, deviceID, now); err != nil {
+			m.logger.Warn("Failed to update device last seen", zap.String("device_id", deviceID), zap.Error(err))
+		}
+	}
+	return &device.Device, nil
+}
+
+// DeviceSigningString builds the canonical string a device signs:
+// method, request path with query, timestamp, nonce and the hex SHA-256 of the body,
+// separated by newlines
+func DeviceSigningString(method, path, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n" + hex.EncodeToString(bodyHash[:])
+}
+
+// DeviceSignature middleware verifies signed device uploads. It must run after JWTAuth.
+// Verified requests get "device_id" and "device_verified" set in the context. Unsigned
+// requests pass through unverified unless RequireDeviceSignature is set.
+func (sm *SecurityMiddleware) DeviceSignature() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("device_verified", false)
+
+		if c.GetHeader(HeaderSignature) == "" {
+			if sm.config.RequireDeviceSignature {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Device signature required"})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSignedBodyBytes+1))
+		if IsBodyTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		if len(body) > maxSignedBodyBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		owner := c.GetString("user_id")
+		device, err := sm.devices.Verify(c.Request.Context(), owner,
+			c.GetHeader(HeaderDeviceID),
+			c.Request.Method,
+			c.Request.URL.RequestURI(),
+			c.GetHeader(HeaderSignatureTimestamp),
+			c.GetHeader(HeaderSignatureNonce),
+			c.GetHeader(HeaderSignature),
+			body,
+		)
+		if err != nil {
+			switch err {
+			case ErrDeviceNotFound, ErrDeviceRevoked, ErrSignatureMissing, ErrSignatureInvalid,
+				ErrSignatureExpired, ErrSignatureReplayed, ErrSignatureMalformed:
+				sm.logger.Warn("Invalid device signature",
+					zap.String("client_ip", sm.ClientIP(c)),
+					zap.String("device_id", c.GetHeader(HeaderDeviceID)),
+					zap.String("user_id", owner),
+					zap.Error(err),
+				)
+				sm.bans.RecordFailure(c.Request.Context(), FailureAuth, "invalid_device_signature", sm.ClientIP(c), owner)
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid device signature"})
+			default:
+				sm.logger.Error("Device signature check failed", zap.Error(err))
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify device signature"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set("device_id", device.ID)
+		c.Set("device_verified", true)
+		c.Next()
+	}
+}
+
+// RegisterDeviceRoutes mounts device registration for the authenticated user.
+// The group must already be protected by JWTAuth.
+func (sm *SecurityMiddleware) RegisterDeviceRoutes(rg *gin.RouterGroup) {
+	rg.POST("", sm.RateLimitWith(RateLimitPolicyAuth), sm.registerDevice)
+	rg.GET("", sm.listDevices)
+	rg.DELETE("/:id", sm.revokeDevice)
+}
+
+// registerDevice registers an app installation at first launch
+func (sm *SecurityMiddleware) registerDevice(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	device, secret, err := sm.devices.Register(c.Request.Context(), userID, req)
+	if err != nil {
+		sm.logger.Error("Failed to register device", zap.String("user_id", userID), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device"})
+		return
+	}
+
+	sm.logger.Info("Device registered",
+		zap.String("device_id", device.ID),
+		zap.String("user_id", userID),
+		zap.String("platform", device.Platform),
+	)
+	sm.auditRequest(c, audit.EventDeviceRegistered, userID, map[string]string{"device_id": device.ID, "platform": device.Platform})
+	c.JSON(http.StatusCreated, DeviceResponse{Device: *device, Secret: secret})
+}
+
+// listDevices lists the devices of the authenticated user
+func (sm *SecurityMiddleware) listDevices(c *gin.Context) {
+	devices, err := sm.devices.List(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		sm.logger.Error("Failed to list devices", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list devices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"devices": devices})
+}
+
+// revokeDevice disables one of the authenticated user's devices
+func (sm *SecurityMiddleware) revokeDevice(c *gin.Context) {
+	err := sm.devices.Revoke(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err == ErrDeviceNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+	if err != nil {
+		sm.logger.Error("Failed to revoke device", zap.String("device_id", c.Param("id")), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke device"})
+		return
+	}
+
+	sm.auditRequest(c, audit.EventDeviceRevoked, c.GetString("user_id"), map[string]string{"device_id": c.Param("id")})
+	c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "revoked": true})
+}
@@ -0,0 +1,152 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func signDeviceRequest(req *http.Request, deviceID, secret, nonce string, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(DeviceSigningString(req.Method, req.URL.RequestURI(), timestamp, nonce, body)))
+
+	req.Header.Set(HeaderDeviceID, deviceID)
+	req.Header.Set(HeaderSignatureTimestamp, timestamp)
+	req.Header.Set(HeaderSignatureNonce, nonce)
+	req.Header.Set(HeaderSignature, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestDeviceSignature(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	device, secret, err := sm.Devices().Register(context.Background(), "user-123", RegisterDeviceRequest{Platform: "ios"})
+	require.NoError(t, err)
+
+	token := signTestToken(t, jwt.MapClaims{
+		"sub": "user-123",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/metrics", sm.JWTAuth(), sm.DeviceSignature(), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"verified": c.GetBool("device_verified"), "body": string(body)})
+	})
+
+	send := func(body []byte, sign func(*http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if sign != nil {
+			sign(req)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	body := []byte(`{"athlete_id":"user-123"}`)
+	nonce := "0123456789abcdef"
+
+	w := send(body, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"verified":false`)
+
+	w = send(body, func(req *http.Request) { signDeviceRequest(req, device.ID, secret, nonce, body) })
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"verified":true`)
+	assert.Contains(t, w.Body.String(), `athlete_id`)
+
+	// Same nonce again is a replay
+	w = send(body, func(req *http.Request) { signDeviceRequest(req, device.ID, secret, nonce, body) })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Body changed after signing
+	w = send([]byte(`{"athlete_id":"user-123","height_cm":199}`), func(req *http.Request) {
+		signDeviceRequest(req, device.ID, secret, "fedcba9876543210", body)
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Revoked devices are rejected
+	require.NoError(t, sm.Devices().Revoke(context.Background(), "user-123", device.ID))
+	w = send(body, func(req *http.Request) { signDeviceRequest(req, device.ID, secret, "aaaaaaaaaaaaaaaa", body) })
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// revokingDeviceStore revokes a device right after Verify read it, as a
+// concurrent request from its owner would
+type revokingDeviceStore struct {
+	*MemoryDeviceStore
+	revoke func()
+}
+
+func (s *revokingDeviceStore) Get(ctx context.Context, id string) (*storedDevice, error) {
+	device, err := s.MemoryDeviceStore.Get(ctx, id)
+	if revoke := s.revoke; revoke != nil {
+		s.revoke = nil
+		revoke()
+	}
+	return device, err
+}
+
+func TestDeviceManager_LastSeenKeepsConcurrentRevocation(t *testing.T) {
+	store := &revokingDeviceStore{MemoryDeviceStore: NewMemoryDeviceStore()}
+	manager := NewDeviceManager(store, time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	device, secret, err := manager.Register(ctx, "user-123", RegisterDeviceRequest{Platform: "ios"})
+	require.NoError(t, err)
+
+	verify := func(nonce string) error {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		signDeviceRequest(req, device.ID, secret, nonce, nil)
+		_, err := manager.Verify(ctx, "user-123", device.ID, http.MethodPost, "/metrics",
+			req.Header.Get(HeaderSignatureTimestamp), nonce, req.Header.Get(HeaderSignature), nil)
+		return err
+	}
+
+	store.revoke = func() { require.NoError(t, manager.Revoke(ctx, "user-123", device.ID)) }
+	require.NoError(t, verify("aaaaaaaaaaaaaaaa"), "the device was valid when it was read")
+	assert.ErrorIs(t, verify("bbbbbbbbbbbbbbbb"), ErrDeviceRevoked)
+
+	stored, err := store.Get(ctx, device.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.RevokedAt)
+	assert.NotNil(t, stored.LastSeenAt)
+}
+
+func TestDeviceManager_VerifyRequiresOwner(t *testing.T) {
+	manager := NewDeviceManager(NewMemoryDeviceStore(), time.Minute, zap.NewNop())
+	ctx := context.Background()
+
+	device, secret, err := manager.Register(ctx, "user-123", RegisterDeviceRequest{Platform: "ios"})
+	require.NoError(t, err)
+
+	verify := func(userID, nonce string) error {
+		req := httptest.NewRequest(http.MethodPost, "/metrics", nil)
+		signDeviceRequest(req, device.ID, secret, nonce, nil)
+		_, err := manager.Verify(ctx, userID, device.ID, http.MethodPost, "/metrics",
+			req.Header.Get(HeaderSignatureTimestamp), nonce, req.Header.Get(HeaderSignature), nil)
+		return err
+	}
+
+	// A valid signature vouches for nobody but the device's user
+	assert.ErrorIs(t, verify("", "aaaaaaaaaaaaaaaa"), ErrDeviceNotFound)
+	assert.ErrorIs(t, verify("user-456", "bbbbbbbbbbbbbbbb"), ErrDeviceNotFound)
+	assert.NoError(t, verify("user-123", "cccccccccccccccc"))
+}
@@ -0,0 +1,244 @@
+package security
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC metadata keys carrying the caller's credentials, the lower-case names
+// of the HTTP headers
+const (
+	grpcAuthorizationKey = "authorization"
+	grpcAPIKeyKey        = "x-api-key"
+)
+
+// GRPCAuthConfig configures the gRPC interceptors
+type GRPCAuthConfig struct {
+	// Public lists full method names, or service prefixes such as
+	// "/grpc.health.v1.Health/", that are served without credentials
+	Public []string
+	// WriteMethods are limited by the write policy, all other methods by the
+	// read policy, as RateLimitByMethod does for HTTP
+	WriteMethods []string
+}
+
+// identityKey is the context key of the identity of a gRPC call
+type identityKey struct{}
+
+// ContextWithIdentity returns a context carrying the caller of a gRPC call
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the caller authenticated by the gRPC interceptors
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// UnaryServerInterceptor authenticates unary gRPC calls like Authenticate does
+// HTTP requests, with an API key in the "x-api-key" metadata or a bearer token
+// in "authorization", and applies the same IP filter and rate limits. Handlers
+// find the caller with IdentityFromContext.
+func (sm *SecurityMiddleware) UnaryServerInterceptor(config GRPCAuthConfig) grpc.UnaryServerInterceptor {
+	auth := sm.newGRPCAuth(config)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := auth.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streaming gRPC calls, see UnaryServerInterceptor
+func (sm *SecurityMiddleware) StreamServerInterceptor(config GRPCAuthConfig) grpc.StreamServerInterceptor {
+	auth := sm.newGRPCAuth(config)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := auth.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream carries the caller's identity in the stream context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcAuth authenticates and rate limits gRPC calls
+type grpcAuth struct {
+	sm     *SecurityMiddleware
+	public []string
+	writes map[string]bool
+	read   RateLimitPolicy
+	write  RateLimitPolicy
+}
+
+func (sm *SecurityMiddleware) newGRPCAuth(config GRPCAuthConfig) *grpcAuth {
+	writes := make(map[string]bool, len(config.WriteMethods))
+	for _, method := range config.WriteMethods {
+		writes[method] = true
+	}
+	return &grpcAuth{
+		sm:     sm,
+		public: config.Public,
+		writes: writes,
+		read:   sm.RateLimitPolicyFor(RateLimitPolicyRead),
+		write:  sm.RateLimitPolicyFor(RateLimitPolicyWrite),
+	}
+}
+
+// authenticate verifies the credentials of a call and returns the context
+// carrying its identity
+func (a *grpcAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	// Banned and blocked clients are turned away from public methods too, as
+	// IPFilter does for HTTP
+	clientIP := peerIP(ctx)
+	if err := a.sm.filterIP(ctx, clientIP); err != nil {
+		return nil, grpcAuthError(err)
+	}
+
+	for _, prefix := range a.public {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var identity Identity
+	if apiKey := firstValue(md, grpcAPIKeyKey); apiKey != "" {
+		key, err := a.sm.verifyAPIKey(ctx, apiKey, clientIP)
+		if err != nil {
+			return nil, grpcAuthError(err)
+		}
+		if err := a.rateLimit(ctx, method, a.sm.apiKeyPolicy(key), "api_key:"+key.ID, clientIP, ""); err != nil {
+			return nil, err
+		}
+		identity = Identity{APIKeyID: key.ID, APIKeyOwner: key.OwnerID, Scopes: key.Scopes}
+	} else if token := firstValue(md, grpcAuthorizationKey); token != "" {
+		claims, err := a.sm.verifyJWT(ctx, token, clientIP)
+		if err != nil {
+			return nil, grpcAuthError(err)
+		}
+		identity.UserID, _ = claims.GetSubject()
+		identity.Roles = claimRoles(claims)
+	} else {
+		return nil, status.Error(codes.Unauthenticated, "Authentication required")
+	}
+
+	policy := a.read
+	if a.writes[method] {
+		policy = a.write
+	}
+	if err := a.rateLimit(ctx, method, policy, identityRateLimitKey(policy.KeyBy, identity, clientIP), clientIP, identity.UserID); err != nil {
+		return nil, err
+	}
+	return ContextWithIdentity(ctx, identity), nil
+}
+
+// rateLimit checks a policy like enforceRateLimit, answering ResourceExhausted
+// with a retry-after trailer when the caller is over budget
+func (a *grpcAuth) rateLimit(ctx context.Context, method string, policy RateLimitPolicy, key, clientIP, userID string) error {
+	if policy.Limit <= 0 {
+		return nil
+	}
+	if policy.Window <= 0 {
+		policy.Window = time.Minute
+	}
+
+	result, err := a.sm.allow(ctx, key, policy)
+	if err != nil {
+		a.sm.logger.Error("Rate limit error", zap.Error(err))
+		return nil // Allow calls on limiter error
+	}
+	if result.Allowed {
+		return nil
+	}
+
+	atomic.AddInt64(&a.sm.counters.rateLimitHits, 1)
+	a.sm.bans.RecordFailure(ctx, FailureRateLimit, "rate_limit_exceeded", clientIP, userID)
+	retryAfter := ceilSeconds(result.RetryAfter)
+	grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+	a.sm.logger.Warn("Rate limit exceeded",
+		zap.String("policy", policy.Name),
+		zap.String("rate_limit_key", key),
+		zap.String("client_ip", clientIP),
+		zap.String("method", method),
+	)
+	return status.Errorf(codes.ResourceExhausted, "Rate limit exceeded, retry after %ds", retryAfter)
+}
+
+// identityRateLimitKey resolves the caller a policy is keyed on, see rateLimitKey
+func identityRateLimitKey(keyBy RateLimitKey, identity Identity, clientIP string) string {
+	switch keyBy {
+	case RateLimitByUser:
+		if identity.UserID != "" {
+			return "user:" + identity.UserID
+		}
+		fallthrough
+	case RateLimitByAPIKey:
+		if identity.APIKeyID != "" {
+			return "api_key:" + identity.APIKeyID
+		}
+	}
+	return "ip:" + clientIP
+}
+
+// grpcAuthError converts rejected credentials to a gRPC status
+func grpcAuthError(err error) error {
+	authErr, ok := err.(*authError)
+	if !ok {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	switch {
+	case authErr.ban != nil:
+		return status.Errorf(codes.PermissionDenied, "%s until %s", authErr.message, authErr.ban.ExpiresAt.UTC().Format(time.RFC3339))
+	case authErr.status == http.StatusForbidden:
+		return status.Error(codes.PermissionDenied, authErr.message)
+	case authErr.status == http.StatusServiceUnavailable:
+		return status.Error(codes.Unavailable, authErr.message)
+	default:
+		return status.Error(codes.Unauthenticated, authErr.message)
+	}
+}
+
+// peerIP returns the address of the client of a call. gRPC clients connect
+// directly, so forwarding headers are not consulted.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package security
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialTestServer serves the health service behind the interceptors
+func dialTestServer(t *testing.T, sm *SecurityMiddleware, config GRPCAuthConfig) healthpb.HealthClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(sm.UnaryServerInterceptor(config)),
+		grpc.StreamInterceptor(sm.StreamServerInterceptor(config)),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	client := dialTestServer(t, sm, GRPCAuthConfig{})
+
+	check := func(pairs ...string) codes.Code {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), pairs...)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+		return status.Code(err)
+	}
+
+	token := signTestToken(t, jwt.MapClaims{"sub": "user-1", "iss": "dunksense"})
+	assert.Equal(t, codes.OK, check("authorization", "Bearer "+token))
+	assert.Equal(t, codes.Unauthenticated, check())
+	assert.Equal(t, codes.Unauthenticated, check("authorization", "Bearer not-a-token"))
+	assert.Equal(t, int64(1), sm.GetMetrics().InvalidJWTTokens)
+
+	_, secret, err := sm.APIKeys().Create(context.Background(), CreateAPIKeyRequest{Name: "ios", OwnerID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, check("x-api-key", secret))
+	assert.Equal(t, codes.Unauthenticated, check("x-api-key", "dk_unknown"))
+
+	// Streams are authenticated as well
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPCInterceptors_PublicMethods(t *testing.T) {
+	client := dialTestServer(t, newTestSecurityMiddleware(), GRPCAuthConfig{Public: []string{"/grpc.health.v1.Health/"}})
+
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
+
+func TestGRPCInterceptors_IdentityAndRateLimit(t *testing.T) {
+	sm := NewSecurityMiddleware(&SecurityConfig{
+		JWTSecret: "test-secret",
+		JWTIssuer: "dunksense",
+		RateLimitPolicies: map[string]RateLimitPolicy{
+			RateLimitPolicyWrite: {Limit: 1, Window: time.Minute, KeyBy: RateLimitByUser},
+		},
+	}, zap.NewNop(), nil)
+	auth := sm.newGRPCAuth(GRPCAuthConfig{WriteMethods: []string{"/test.Service/Write"}})
+
+	token := signTestToken(t, jwt.MapClaims{"sub": "coach-1", "iss": "dunksense", "roles": []string{"coach"}})
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+
+	ctx, err := auth.authenticate(incoming, "/test.Service/Write")
+	require.NoError(t, err)
+	identity, ok := IdentityFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, Identity{UserID: "coach-1", Roles: []string{"coach"}}, identity)
+	assert.True(t, identity.HasRole("admin", "coach"))
+
+	_, err = auth.authenticate(incoming, "/test.Service/Write")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Reads are limited by the read policy
+	_, err = auth.authenticate(incoming, "/test.Service/Read")
+	assert.NoError(t, err)
+}
+
+func TestGRPCInterceptors_IPFilter(t *testing.T) {
+	sm := NewSecurityMiddleware(&SecurityConfig{
+		JWTSecret:  "test-secret",
+		JWTIssuer:  "dunksense",
+		BlockedIPs: []string{"10.1.0.0/16"},
+	}, zap.NewNop(), nil)
+	auth := sm.newGRPCAuth(GRPCAuthConfig{Public: []string{"/grpc.health.v1.Health/"}})
+	from := func(ip string) context.Context {
+		token := signTestToken(t, jwt.MapClaims{"sub": "user-1", "iss": "dunksense"})
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50051}})
+	}
+
+	_, err := auth.authenticate(from("10.0.0.9"), "/test.Service/Read")
+	assert.NoError(t, err)
+	_, err = auth.authenticate(from("10.1.2.3"), "/test.Service/Read")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Banned clients are turned away, from public methods too
+	for i := 0; i < DefaultBanConfig().MaxAuthFailures; i++ {
+		sm.bans.RecordFailure(context.Background(), FailureAuth, "invalid_jwt", "10.0.0.9", "")
+	}
+	_, err = auth.authenticate(from("10.0.0.9"), "/test.Service/Read")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "Access temporarily blocked until")
+	_, err = auth.authenticate(from("10.0.0.9"), "/grpc.health.v1.Health/Check")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
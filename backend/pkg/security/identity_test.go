@@ -0,0 +1,161 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testIdentitySecret  = "0123456789abcdef0123456789abcdef"
+	otherIdentitySecret = "fedcba9876543210fedcba9876543210"
+)
+
+func TestIdentitySigner_SignAndVerify(t *testing.T) {
+	signer, err := NewIdentitySigner(testIdentitySecret, nil, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+
+	identity := Identity{UserID: "user-1", Roles: []string{"coach"}}
+	header := signer.Sign(identity, http.MethodGet, "/api/v1/jumps", "req-1", now)
+
+	verified, err := signer.Verify(header, http.MethodGet, "/api/v1/jumps", "req-1", now)
+	require.NoError(t, err)
+	assert.Equal(t, identity, *verified)
+
+	_, err = signer.Verify(header, http.MethodDelete, "/api/v1/jumps", "req-1", now)
+	assert.ErrorIs(t, err, ErrIdentityMismatch)
+	_, err = signer.Verify(header, http.MethodGet, "/api/v1/admin/bans", "req-1", now)
+	assert.ErrorIs(t, err, ErrIdentityMismatch)
+	_, err = signer.Verify(header, http.MethodGet, "/api/v1/jumps", "req-2", now)
+	assert.ErrorIs(t, err, ErrIdentityMismatch)
+	_, err = signer.Verify(header, http.MethodGet, "/api/v1/jumps", "req-1", now.Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrIdentityExpired)
+
+	// A forged payload does not match the signature
+	forged := signer.Sign(Identity{UserID: "user-1", Roles: []string{"admin"}}, http.MethodGet, "/api/v1/jumps", "req-1", now)
+	payload, _, _ := strings.Cut(forged, ".")
+	_, signature, _ := strings.Cut(header, ".")
+	_, err = signer.Verify(payload+"."+signature, http.MethodGet, "/api/v1/jumps", "req-1", now)
+	assert.ErrorIs(t, err, ErrIdentitySignature)
+
+	_, err = signer.Verify("garbage", http.MethodGet, "/api/v1/jumps", "req-1", now)
+	assert.ErrorIs(t, err, ErrIdentityMalformed)
+}
+
+func TestIdentitySigner_Rotation(t *testing.T) {
+	old, err := NewIdentitySigner(otherIdentitySecret, nil, time.Minute)
+	require.NoError(t, err)
+	rotated, err := NewIdentitySigner(testIdentitySecret, []string{otherIdentitySecret}, time.Minute)
+	require.NoError(t, err)
+
+	header := old.Sign(Identity{UserID: "user-1"}, http.MethodGet, "/", "req-1", time.Now())
+	_, err = rotated.Verify(header, http.MethodGet, "/", "req-1", time.Now())
+	assert.NoError(t, err)
+
+	signer, err := NewIdentitySigner("", nil, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, signer)
+	_, err = NewIdentitySigner("short", nil, 0)
+	assert.Error(t, err)
+}
+
+func TestGatewayIdentity(t *testing.T) {
+	signer, err := NewIdentitySigner(testIdentitySecret, nil, time.Minute)
+	require.NoError(t, err)
+	sm := NewSecurityMiddleware(&SecurityConfig{
+		JWTSecret: "test-secret",
+		JWTIssuer: "dunksense",
+		RateLimitPolicies: map[string]RateLimitPolicy{
+			RateLimitPolicyRead: {Limit: 1, Window: time.Minute, KeyBy: RateLimitByUser},
+		},
+		Identity: signer,
+	}, zap.NewNop(), nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(sm.GatewayIdentity(), sm.RateLimitByMethod())
+	router.GET("/admin", sm.JWTAuth(), sm.RequireRole("admin"), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+
+	request := func(identity *Identity, requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		req.Header.Set("X-Request-ID", requestID)
+		if identity != nil {
+			req.Header.Set(IdentityHeader, signer.Sign(*identity, http.MethodGet, "/admin", requestID, time.Now()))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The signed identity stands in for the token, and the gateway's rate limit for ours
+	admin := &Identity{UserID: "admin-1", Roles: []string{"admin"}}
+	for i := 0; i < 3; i++ {
+		w := request(admin, "req-admin")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "admin-1", w.Body.String())
+	}
+
+	w := request(&Identity{UserID: "user-1"}, "req-user")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Without the header the route's own authentication applies
+	w = request(nil, "req-none")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// A header replayed for another request is rejected
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("X-Request-ID", "req-other")
+	req.Header.Set(IdentityHeader, signer.Sign(*admin, http.MethodGet, "/admin", "req-admin", time.Now()))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthenticate(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	gin.SetMode(gin.TestMode)
+
+	authenticate := func(optional bool, header string) (int, Identity) {
+		var identity Identity
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			if !sm.Authenticate(c, optional) {
+				return
+			}
+			identity = CallerIdentity(c)
+			c.Status(http.StatusOK)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code, identity
+	}
+
+	token := signTestToken(t, map[string]interface{}{"sub": "user-1", "iss": "dunksense", "roles": []string{"coach"}})
+	code, identity := authenticate(false, "Bearer "+token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Identity{UserID: "user-1", Roles: []string{"coach"}}, identity)
+
+	code, _ = authenticate(false, "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, identity = authenticate(true, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, Identity{}, identity)
+
+	// Optional authentication still rejects bad credentials
+	code, _ = authenticate(true, "Bearer not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
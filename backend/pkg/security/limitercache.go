@@ -0,0 +1,203 @@
+package security
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultLimiterCacheEntries  = 100000
+	defaultLimiterCacheShards   = 32
+	defaultLimiterJanitorPeriod = time.Minute
+)
+
+// Limiter cache eviction reasons used as metric labels
+const (
+	evictionReasonExpired  = "expired"
+	evictionReasonCapacity = "capacity"
+)
+
+// LimiterCacheConfig bounds the in-memory rate limiter cache
+type LimiterCacheConfig struct {
+	MaxEntries      int           `json:"max_entries"`      // total limiters kept across all shards
+	JanitorInterval time.Duration `json:"janitor_interval"` // how often idle limiters are swept
+}
+
+// limiterEntry is a cached token bucket. It may be dropped once it has been idle
+// for longer than its policy window, because by then the bucket has refilled and
+// recreating it gives the client exactly the same budget.
+type limiterEntry struct {
+	key        string
+	limiter    *rate.Limiter
+	idleTTL    time.Duration
+	lastAccess time.Time
+}
+
+// limiterShard is an LRU list of limiters guarded by its own lock
+type limiterShard struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+}
+
+// LimiterCache is a sharded LRU of per-key token buckets with idle expiry.
+// Sharding keeps lock contention low when it absorbs all traffic during a Redis outage.
+type LimiterCache struct {
+	shards []*limiterShard
+
+	evictions *prometheus.CounterVec
+	size      prometheus.GaugeFunc
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLimiterCache creates a limiter cache and starts its background janitor
+func NewLimiterCache(config LimiterCacheConfig) *LimiterCache {
+	maxEntries := config.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultLimiterCacheEntries
+	}
+	interval := config.JanitorInterval
+	if interval <= 0 {
+		interval = defaultLimiterJanitorPeriod
+	}
+
+	perShard := maxEntries / defaultLimiterCacheShards
+	if perShard < 1 {
+		perShard = 1
+	}
+
+	c := &LimiterCache{
+		shards: make([]*limiterShard, defaultLimiterCacheShards),
+		evictions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "security_rate_limiter_evictions_total",
+				Help: "Total number of in-memory rate limiters evicted",
+			},
+			[]string{"reason"},
+		),
+		stop: make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i] = &limiterShard{
+			entries:    make(map[string]*list.Element),
+			lru:        list.New(),
+			maxEntries: perShard,
+		}
+	}
+	c.size = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "security_rate_limiter_entries",
+			Help: "Number of in-memory rate limiters currently cached",
+		},
+		func() float64 { return float64(c.Len()) },
+	)
+
+	go c.janitor(interval)
+	return c
+}
+
+// Get returns the limiter for key, creating it with newLimiter on first use
+func (c *LimiterCache) Get(key string, idleTTL time.Duration, newLimiter func() *rate.Limiter) *rate.Limiter {
+	shard := c.shard(key)
+	now := time.Now()
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if element, exists := shard.entries[key]; exists {
+		entry := element.Value.(*limiterEntry)
+		entry.lastAccess = now
+		shard.lru.MoveToFront(element)
+		return entry.limiter
+	}
+
+	entry := &limiterEntry{
+		key:        key,
+		limiter:    newLimiter(),
+		idleTTL:    idleTTL,
+		lastAccess: now,
+	}
+	shard.entries[key] = shard.lru.PushFront(entry)
+
+	for shard.lru.Len() > shard.maxEntries {
+		c.remove(shard, shard.lru.Back(), evictionReasonCapacity)
+	}
+	return entry.limiter
+}
+
+// Len returns the number of cached limiters
+func (c *LimiterCache) Len() int {
+	total := 0
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		total += shard.lru.Len()
+		shard.mu.Unlock()
+	}
+	return total
+}
+
+// PurgeExpired removes limiters that have been idle longer than their TTL
+func (c *LimiterCache) PurgeExpired() {
+	now := time.Now()
+	for _, shard := range c.shards {
+		shard.mu.Lock()
+		// Entries are ordered by last access, so idle ones collect at the back.
+		// TTLs differ per policy, so keep walking past entries that are not yet due.
+		for element := shard.lru.Back(); element != nil; {
+			prev := element.Prev()
+			entry := element.Value.(*limiterEntry)
+			if now.Sub(entry.lastAccess) > entry.idleTTL {
+				c.remove(shard, element, evictionReasonExpired)
+			}
+			element = prev
+		}
+		shard.mu.Unlock()
+	}
+}
+
+// Collectors returns the cache's Prometheus collectors
+func (c *LimiterCache) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.evictions, c.size}
+}
+
+// Close stops the background janitor
+func (c *LimiterCache) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// janitor periodically sweeps idle limiters until Close is called
+func (c *LimiterCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.PurgeExpired()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// remove drops an element from a shard. Callers must hold the shard lock.
+func (c *LimiterCache) remove(shard *limiterShard, element *list.Element, reason string) {
+	entry := shard.lru.Remove(element).(*limiterEntry)
+	delete(shard.entries, entry.key)
+	c.evictions.WithLabelValues(reason).Inc()
+}
+
+// shard picks the shard responsible for key
+func (c *LimiterCache) shard(key string) *limiterShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
@@ -0,0 +1,72 @@
+package security
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+)
+
+func newTestLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Every(time.Second), 1)
+}
+
+func TestLimiterCache_ReusesLimiter(t *testing.T) {
+	cache := NewLimiterCache(LimiterCacheConfig{MaxEntries: 1000})
+	defer cache.Close()
+
+	first := cache.Get("ip:10.0.0.1", time.Minute, newTestLimiter)
+	second := cache.Get("ip:10.0.0.1", time.Minute, newTestLimiter)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestLimiterCache_CapacityEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLimiterCache(LimiterCacheConfig{MaxEntries: defaultLimiterCacheShards})
+	defer cache.Close()
+
+	for i := 0; i < 500; i++ {
+		cache.Get(fmt.Sprintf("ip:10.0.%d.%d", i/256, i%256), time.Minute, newTestLimiter)
+	}
+
+	assert.LessOrEqual(t, cache.Len(), defaultLimiterCacheShards)
+	assert.Greater(t, testutil.ToFloat64(cache.evictions.WithLabelValues(evictionReasonCapacity)), 0.0)
+}
+
+func TestLimiterCache_PurgeExpiredKeepsActiveKeys(t *testing.T) {
+	cache := NewLimiterCache(LimiterCacheConfig{MaxEntries: 1000})
+	defer cache.Close()
+
+	cache.Get("idle", time.Millisecond, newTestLimiter)
+	active := cache.Get("active", time.Hour, newTestLimiter)
+
+	time.Sleep(5 * time.Millisecond)
+	cache.PurgeExpired()
+
+	assert.Equal(t, 1, cache.Len())
+	assert.Same(t, active, cache.Get("active", time.Hour, newTestLimiter))
+	assert.Equal(t, 1.0, testutil.ToFloat64(cache.evictions.WithLabelValues(evictionReasonExpired)))
+}
+
+func TestLimiterCache_ConcurrentAccess(t *testing.T) {
+	cache := NewLimiterCache(LimiterCacheConfig{MaxEntries: 256, JanitorInterval: time.Millisecond})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				cache.Get(fmt.Sprintf("user:%d-%d", worker, i%300), time.Millisecond, newTestLimiter).Allow()
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, cache.Len(), 256)
+}
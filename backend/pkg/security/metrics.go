@@ -0,0 +1,55 @@
+package security
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SecurityMetrics tracks security-related metrics
+type SecurityMetrics struct {
+	RateLimitHits    int64 `json:"rate_limit_hits"`
+	BlockedIPs       int64 `json:"blocked_ips"`
+	InvalidAPIKeys   int64 `json:"invalid_api_keys"`
+	InvalidJWTTokens int64 `json:"invalid_jwt_tokens"`
+	TotalRequests    int64 `json:"total_requests"`
+}
+
+// securityCounters holds the live counters behind SecurityMetrics.
+// All fields are accessed atomically.
+type securityCounters struct {
+	rateLimitHits    int64
+	blockedIPs       int64
+	invalidAPIKeys   int64
+	invalidJWTTokens int64
+	totalRequests    int64
+}
+
+// GetMetrics returns a snapshot of the security counters
+func (sm *SecurityMiddleware) GetMetrics() SecurityMetrics {
+	return SecurityMetrics{
+		RateLimitHits:    atomic.LoadInt64(&sm.counters.rateLimitHits),
+		BlockedIPs:       atomic.LoadInt64(&sm.counters.blockedIPs),
+		InvalidAPIKeys:   atomic.LoadInt64(&sm.counters.invalidAPIKeys),
+		InvalidJWTTokens: atomic.LoadInt64(&sm.counters.invalidJWTTokens),
+		TotalRequests:    atomic.LoadInt64(&sm.counters.totalRequests),
+	}
+}
+
+// counterCollectors exposes the atomic counters as Prometheus counters
+func (sm *SecurityMiddleware) counterCollectors() []prometheus.Collector {
+	counter := func(name, help string, value *int64) prometheus.Collector {
+		return prometheus.NewCounterFunc(
+			prometheus.CounterOpts{Name: name, Help: help},
+			func() float64 { return float64(atomic.LoadInt64(value)) },
+		)
+	}
+
+	return []prometheus.Collector{
+		counter("security_rate_limit_hits_total", "Total number of requests rejected by rate limiting", &sm.counters.rateLimitHits),
+		counter("security_blocked_ips_total", "Total number of requests rejected by IP filtering", &sm.counters.blockedIPs),
+		counter("security_invalid_api_keys_total", "Total number of requests with an invalid API key", &sm.counters.invalidAPIKeys),
+		counter("security_invalid_jwt_tokens_total", "Total number of requests with an invalid or revoked JWT", &sm.counters.invalidJWTTokens),
+		counter("security_requests_total", "Total number of requests seen by the security middleware", &sm.counters.totalRequests),
+	}
+}
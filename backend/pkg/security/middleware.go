@@ -2,20 +2,21 @@ package security
 
 import (
 	"context"
-	"crypto/subtle"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/audit"
 )
 
 // SecurityConfig holds security configuration
@@ -31,6 +32,8 @@ type SecurityConfig struct {
 	RateLimit          int           `json:"rate_limit"`          // requests per minute
 	RateLimitBurst     int           `json:"rate_limit_burst"`    // burst capacity
 	RateLimitWindow    time.Duration `json:"rate_limit_window"`   // time window
+	RateLimitPolicies  map[string]RateLimitPolicy `json:"rate_limit_policies"` // per-route-group overrides
+	RateLimiterCache   LimiterCacheConfig         `json:"rate_limiter_cache"`  // bounds for the in-memory limiters
 
 	// JWT settings
 	JWTSecret          string        `json:"jwt_secret"`
@@ -48,11 +51,22 @@ type SecurityConfig struct {
 	// IP filtering
 	AllowedIPs         []string      `json:"allowed_ips"`
 	BlockedIPs         []string      `json:"blocked_ips"`
-	TrustedProxies     []string      `json:"trusted_proxies"`
+	TrustedProxies     []string      `json:"trusted_proxies"`     // proxy CIDRs or IPs whose forwarding headers are honoured
+	Bans               *BanConfig    `json:"bans"`                // automatic temporary bans, DefaultBanConfig when nil
 
 	// API Key settings
 	RequireAPIKey      bool          `json:"require_api_key"`
-	ValidAPIKeys       []string      `json:"valid_api_keys"`
+	APIKeyRateLimit    int           `json:"api_key_rate_limit"`  // default requests per window for keys without their own limit
+
+	// Device signing
+	RequireDeviceSignature bool          `json:"require_device_signature"`  // reject unsigned requests on DeviceSignature routes
+	DeviceSignatureMaxSkew time.Duration `json:"device_signature_max_skew"` // allowed clock difference for signed requests
+
+	// Audit trail for sign-ins, revocations and administrative actions, disabled when nil
+	Audit *audit.Logger `json:"-"`
+	
+	// Verifies identity headers signed by the API gateway, see GatewayIdentity
+	Identity *IdentitySigner `json:"-"`
 }
 
 // SecurityMiddleware provides security middleware
@@ -60,18 +74,82 @@ type SecurityMiddleware struct {
 	config    *SecurityConfig
 	logger    *zap.Logger
 	redis     *redis.Client
-	limiters  map[string]*rate.Limiter
-	limiterMu sync.RWMutex
+
+	limiter         RateLimiter
+	fallbackLimiter *MemoryRateLimiter
+	redisRetryAt    int64 // unix nanos, accessed atomically
+	counters        securityCounters
+	revocations     RevocationStore
+	apiKeys         *APIKeyManager
+	bans            *BanManager
+	clientIPs       *ClientIPResolver
+	devices         *DeviceManager
+	audit           *audit.Logger
+	identity        *IdentitySigner
 }
 
 // NewSecurityMiddleware creates a new security middleware
 func NewSecurityMiddleware(config *SecurityConfig, logger *zap.Logger, redis *redis.Client) *SecurityMiddleware {
-	return &SecurityMiddleware{
-		config:   config,
-		logger:   logger,
-		redis:    redis,
-		limiters: make(map[string]*rate.Limiter),
+	sm := &SecurityMiddleware{
+		config:          config,
+		logger:          logger,
+		redis:           redis,
+		fallbackLimiter: NewMemoryRateLimiter(config.RateLimiterCache),
+		revocations:     NewRevocationStore(redis, logger, config.JWTExpiration),
+		apiKeys:         NewAPIKeyManager(NewAPIKeyStore(redis), logger),
+		devices:         NewDeviceManager(NewDeviceStore(redis), config.DeviceSignatureMaxSkew, logger),
+		audit:           config.Audit,
+		identity:        config.Identity,
+	}
+	
+	banConfig := DefaultBanConfig()
+	if config.Bans != nil {
+		banConfig = *config.Bans
+	}
+	sm.bans = NewBanManager(NewBanStore(redis), banConfig, logger)
+	
+	// Invalid proxy entries must not make us trust forwarding headers from anyone
+	clientIPs, err := NewClientIPResolver(config.TrustedProxies)
+	if err != nil {
+		logger.Error("Ignoring invalid trusted proxies", zap.Error(err))
+		clientIPs = &ClientIPResolver{}
+	}
+	sm.clientIPs = clientIPs
+	
+	// Use Redis-based rate limiting if available, in-memory otherwise
+	if redis != nil {
+		sm.limiter = NewRedisRateLimiter(redis)
+	} else {
+		sm.limiter = sm.fallbackLimiter
 	}
+	
+	return sm
+}
+
+// Revocations returns the store consulted by JWTAuth for revoked tokens
+func (sm *SecurityMiddleware) Revocations() RevocationStore {
+	return sm.revocations
+}
+
+// APIKeys returns the manager used by APIKeyAuth
+func (sm *SecurityMiddleware) APIKeys() *APIKeyManager {
+	return sm.apiKeys
+}
+
+// Devices returns the manager used by DeviceSignature
+func (sm *SecurityMiddleware) Devices() *DeviceManager {
+	return sm.devices
+}
+
+// Bans returns the manager behind the automatic temporary bans
+func (sm *SecurityMiddleware) Bans() *BanManager {
+	return sm.bans
+}
+
+// RecordSignInFailure counts a failed sign-in towards an automatic ban
+func (sm *SecurityMiddleware) RecordSignInFailure(ctx context.Context, ip, userID string) {
+	sm.bans.RecordFailure(ctx, FailureAuth, "sign_in_failure", ip, userID)
+	sm.recordAudit(ctx, audit.Event{Type: audit.EventSignInFailed, UserID: userID, IPAddress: ip})
 }
 
 // CORS middleware
@@ -137,143 +215,57 @@ func (sm *SecurityMiddleware) SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// RateLimit middleware implements rate limiting
+// RateLimit middleware applies the default per-IP rate limit policy
 func (sm *SecurityMiddleware) RateLimit() gin.HandlerFunc {
+	return sm.RateLimitWith(RateLimitPolicyDefault)
+}
+
+// IPFilter middleware filters requests based on IP addresses
+func (sm *SecurityMiddleware) IPFilter() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		
-		// Use Redis-based rate limiting if available
-		if sm.redis != nil {
-			if !sm.checkRedisRateLimit(c, clientIP) {
-				sm.logger.Warn("Rate limit exceeded", 
-					zap.String("client_ip", clientIP),
-					zap.String("path", c.Request.URL.Path),
-				)
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "Rate limit exceeded",
-					"retry_after": sm.config.RateLimitWindow.Seconds(),
-				})
-				c.Abort()
-				return
-			}
-		} else {
-			// Fallback to in-memory rate limiting
-			if !sm.checkInMemoryRateLimit(clientIP) {
-				sm.logger.Warn("Rate limit exceeded", 
-					zap.String("client_ip", clientIP),
-					zap.String("path", c.Request.URL.Path),
-				)
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "Rate limit exceeded",
-					"retry_after": sm.config.RateLimitWindow.Seconds(),
-				})
-				c.Abort()
-				return
-			}
+		if err := sm.filterIP(c.Request.Context(), sm.ClientIP(c)); err != nil {
+			sm.abortAuth(c, err)
+			return
 		}
-		
 		c.Next()
 	}
 }
 
-// checkRedisRateLimit checks rate limit using Redis
-func (sm *SecurityMiddleware) checkRedisRateLimit(c *gin.Context, clientIP string) bool {
-	ctx := c.Request.Context()
-	key := fmt.Sprintf("rate_limit:%s", clientIP)
-	
-	// Use Redis sliding window rate limiting
-	pipe := sm.redis.Pipeline()
-	now := time.Now().Unix()
-	window := int64(sm.config.RateLimitWindow.Seconds())
-	
-	// Remove old entries
-	pipe.ZRemRangeByScore(ctx, key, "0", fmt.Sprintf("%d", now-window))
-	
-	// Count current requests
-	pipe.ZCard(ctx, key)
-	
-	// Add current request
-	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now), Member: now})
-	
-	// Set expiration
-	pipe.Expire(ctx, key, sm.config.RateLimitWindow)
-	
-	results, err := pipe.Exec(ctx)
-	if err != nil {
-		sm.logger.Error("Redis rate limit error", zap.Error(err))
-		return true // Allow request on Redis error
-	}
-	
-	// Get count result
-	countCmd := results[1].(*redis.IntCmd)
-	count, err := countCmd.Result()
-	if err != nil {
-		sm.logger.Error("Redis rate limit count error", zap.Error(err))
-		return true
+// filterIP checks a client IP against temporary bans and the blocked and
+// allowed IPs, for HTTP requests and gRPC calls alike
+func (sm *SecurityMiddleware) filterIP(ctx context.Context, clientIP string) error {
+	// Check temporary bans
+	if ban := sm.bans.Banned(ctx, BanKindIP, clientIP); ban != nil {
+		atomic.AddInt64(&sm.counters.blockedIPs, 1)
+		return &authError{status: http.StatusForbidden, message: "Access temporarily blocked", ban: ban}
 	}
 	
-	return count < int64(sm.config.RateLimit)
-}
-
-// checkInMemoryRateLimit checks rate limit using in-memory limiters
-func (sm *SecurityMiddleware) checkInMemoryRateLimit(clientIP string) bool {
-	sm.limiterMu.RLock()
-	limiter, exists := sm.limiters[clientIP]
-	sm.limiterMu.RUnlock()
-	
-	if !exists {
-		sm.limiterMu.Lock()
-		// Double-check after acquiring write lock
-		if limiter, exists = sm.limiters[clientIP]; !exists {
-			limiter = rate.NewLimiter(rate.Every(sm.config.RateLimitWindow/time.Duration(sm.config.RateLimit)), sm.config.RateLimitBurst)
-			sm.limiters[clientIP] = limiter
+	// Check blocked IPs
+	for _, blockedIP := range sm.config.BlockedIPs {
+		if sm.matchIP(clientIP, blockedIP) {
+			sm.logger.Warn("Blocked IP attempted access", 
+				zap.String("client_ip", clientIP),
+				zap.String("blocked_pattern", blockedIP),
+			)
+			atomic.AddInt64(&sm.counters.blockedIPs, 1)
+			return &authError{status: http.StatusForbidden, message: "Access denied"}
 		}
-		sm.limiterMu.Unlock()
 	}
 	
-	return limiter.Allow()
-}
-
-// IPFilter middleware filters requests based on IP addresses
-func (sm *SecurityMiddleware) IPFilter() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		
-		// Check blocked IPs
-		for _, blockedIP := range sm.config.BlockedIPs {
-			if sm.matchIP(clientIP, blockedIP) {
-				sm.logger.Warn("Blocked IP attempted access", 
-					zap.String("client_ip", clientIP),
-					zap.String("blocked_pattern", blockedIP),
-				)
-				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-				c.Abort()
-				return
+	// Check allowed IPs (if configured)
+	if len(sm.config.AllowedIPs) > 0 {
+		for _, allowedIP := range sm.config.AllowedIPs {
+			if sm.matchIP(clientIP, allowedIP) {
+				return nil
 			}
 		}
-		
-		// Check allowed IPs (if configured)
-		if len(sm.config.AllowedIPs) > 0 {
-			allowed := false
-			for _, allowedIP := range sm.config.AllowedIPs {
-				if sm.matchIP(clientIP, allowedIP) {
-					allowed = true
-					break
-				}
-			}
-			
-			if !allowed {
-				sm.logger.Warn("Non-whitelisted IP attempted access", 
-					zap.String("client_ip", clientIP),
-				)
-				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-				c.Abort()
-				return
-			}
-		}
-		
-		c.Next()
+		sm.logger.Warn("Non-whitelisted IP attempted access", 
+			zap.String("client_ip", clientIP),
+		)
+		atomic.AddInt64(&sm.counters.blockedIPs, 1)
+		return &authError{status: http.StatusForbidden, message: "Access denied"}
 	}
+	return nil
 }
 
 // matchIP checks if an IP matches a pattern (supports CIDR)
@@ -297,40 +289,102 @@ func (sm *SecurityMiddleware) matchIP(clientIP, pattern string) bool {
 	return ipNet.Contains(ip)
 }
 
-// APIKeyAuth middleware validates API keys
+// APIKeyAuth middleware validates API keys sent in the X-API-Key header
 func (sm *SecurityMiddleware) APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !sm.config.RequireAPIKey {
+		// The gateway already verified the caller's key
+		if viaGateway(c) && c.GetString("api_key_id") != "" {
 			c.Next()
 			return
 		}
 		
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
-			apiKey = c.Query("api_key")
-		}
-		
-		if apiKey == "" {
+			if !sm.config.RequireAPIKey {
+				c.Next()
+				return
+			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
 			c.Abort()
 			return
 		}
 		
-		// Validate API key using constant-time comparison
-		valid := false
-		for _, validKey := range sm.config.ValidAPIKeys {
-			if subtle.ConstantTimeCompare([]byte(apiKey), []byte(validKey)) == 1 {
-				valid = true
-				break
-			}
+		if !sm.authenticateAPIKey(c, apiKey) {
+			return
 		}
-		
-		if !valid {
+		c.Next()
+	}
+}
+
+// authenticateAPIKey validates an API key and applies its rate limit.
+// It reports whether the request may proceed.
+func (sm *SecurityMiddleware) authenticateAPIKey(c *gin.Context, apiKey string) bool {
+	key, err := sm.verifyAPIKey(c.Request.Context(), apiKey, sm.ClientIP(c))
+	if err != nil {
+		sm.abortAuth(c, err)
+		return false
+	}
+	
+	// Apply the per-key rate limit
+	if !sm.enforceRateLimit(c, sm.apiKeyPolicy(key), "api_key:"+key.ID) {
+		return false
+	}
+	
+	c.Set("api_key", key)
+	c.Set("api_key_id", key.ID)
+	c.Set("api_key_owner", key.OwnerID)
+	
+	return true
+}
+
+// verifyAPIKey looks up an API key, recording failed attempts against the client IP
+func (sm *SecurityMiddleware) verifyAPIKey(ctx context.Context, apiKey, clientIP string) (*APIKey, error) {
+	key, err := sm.apiKeys.Authenticate(ctx, apiKey)
+	if err != nil {
+		switch err {
+		case ErrAPIKeyNotFound, ErrAPIKeyMalformed, ErrAPIKeyInvalid, ErrAPIKeyRevoked, ErrAPIKeyExpired:
 			sm.logger.Warn("Invalid API key attempted", 
-				zap.String("client_ip", c.ClientIP()),
-				zap.String("api_key_prefix", apiKey[:min(len(apiKey), 8)]),
+				zap.String("client_ip", clientIP),
+				zap.String("api_key_prefix", apiKey[:min(len(apiKey), 12)]),
+				zap.Error(err),
 			)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			atomic.AddInt64(&sm.counters.invalidAPIKeys, 1)
+			sm.bans.RecordFailure(ctx, FailureAuth, "invalid_api_key", clientIP, "")
+			return nil, &authError{status: http.StatusUnauthorized, message: "Invalid API key"}
+		default:
+			sm.logger.Error("API key lookup failed", zap.Error(err))
+			return nil, &authError{status: http.StatusServiceUnavailable, message: "Unable to verify API key"}
+		}
+	}
+	return key, nil
+}
+
+// apiKeyPolicy returns the rate limit of an API key
+func (sm *SecurityMiddleware) apiKeyPolicy(key *APIKey) RateLimitPolicy {
+	limit := key.RateLimit
+	if limit <= 0 {
+		limit = sm.config.APIKeyRateLimit
+	}
+	return RateLimitPolicy{
+		Name:   "api_key",
+		Limit:  limit,
+		Window: sm.config.RateLimitWindow,
+		KeyBy:  RateLimitByAPIKey,
+	}
+}
+
+// RequireScope middleware requires API-key callers to hold the given scope.
+// Requests authenticated by JWT instead of an API key are not affected.
+func (sm *SecurityMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("api_key")
+		if !exists {
+			c.Next()
+			return
+		}
+		
+		if key, ok := value.(*APIKey); !ok || !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks required scope", "scope": scope})
 			c.Abort()
 			return
 		}
@@ -342,62 +396,196 @@ func (sm *SecurityMiddleware) APIKeyAuth() gin.HandlerFunc {
 // JWTAuth middleware validates JWT tokens
 func (sm *SecurityMiddleware) JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+		// The gateway already verified the token and vouches for the user
+		if viaGateway(c) && c.GetString("user_id") != "" {
+			c.Next()
 			return
 		}
 		
-		// Remove "Bearer " prefix
-		if strings.HasPrefix(tokenString, "Bearer ") {
-			tokenString = tokenString[7:]
-		}
-		
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(sm.config.JWTSecret), nil
-		})
-		
-		if err != nil {
-			sm.logger.Warn("Invalid JWT token", 
-				zap.String("client_ip", c.ClientIP()),
-				zap.Error(err),
-			)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
 			return
 		}
 		
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Validate issuer
-			if iss, ok := claims["iss"].(string); ok && iss != sm.config.JWTIssuer {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token issuer"})
-				c.Abort()
-				return
-			}
-			
-			// Store claims in context
-			c.Set("jwt_claims", claims)
-			c.Set("user_id", claims["sub"])
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			c.Abort()
+		if !sm.authenticateJWT(c, tokenString) {
 			return
 		}
-		
 		c.Next()
 	}
 }
 
+// Authenticate verifies the caller's API key or bearer token, in that order, and
+// sets the same context values as APIKeyAuth and JWTAuth. Requests without
+// credentials are rejected unless optional is set. It reports whether the
+// request may proceed.
+func (sm *SecurityMiddleware) Authenticate(c *gin.Context, optional bool) bool {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return sm.authenticateAPIKey(c, apiKey)
+	}
+	if tokenString := c.GetHeader("Authorization"); tokenString != "" {
+		return sm.authenticateJWT(c, tokenString)
+	}
+	if optional {
+		return true
+	}
+	
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	c.Abort()
+	return false
+}
+
+// authenticateJWT validates a bearer token and stores its claims in the context.
+// It reports whether the request may proceed.
+func (sm *SecurityMiddleware) authenticateJWT(c *gin.Context, tokenString string) bool {
+	claims, err := sm.verifyJWT(c.Request.Context(), tokenString, sm.ClientIP(c))
+	if err != nil {
+		sm.abortAuth(c, err)
+		return false
+	}
+	
+	// Store claims in context
+	c.Set("jwt_claims", claims)
+	c.Set("user_id", claims["sub"])
+	return true
+}
+
+// verifyJWT validates a bearer token: its signature, issuer and revocation, and
+// that its user is not banned. Failed attempts are recorded against the client IP.
+func (sm *SecurityMiddleware) verifyJWT(ctx context.Context, tokenString, clientIP string) (jwt.MapClaims, error) {
+	// Remove "Bearer " prefix
+	if strings.HasPrefix(tokenString, "Bearer ") {
+		tokenString = tokenString[7:]
+	}
+	
+	// Parse and validate token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(sm.config.JWTSecret), nil
+	})
+	
+	if err != nil {
+		sm.logger.Warn("Invalid JWT token", 
+			zap.String("client_ip", clientIP),
+			zap.Error(err),
+		)
+		atomic.AddInt64(&sm.counters.invalidJWTTokens, 1)
+		sm.bans.RecordFailure(ctx, FailureAuth, "invalid_jwt", clientIP, "")
+		return nil, &authError{status: http.StatusUnauthorized, message: "Invalid token"}
+	}
+	
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		atomic.AddInt64(&sm.counters.invalidJWTTokens, 1)
+		return nil, &authError{status: http.StatusUnauthorized, message: "Invalid token claims"}
+	}
+	
+	// Validate issuer
+	if iss, ok := claims["iss"].(string); ok && iss != sm.config.JWTIssuer {
+		atomic.AddInt64(&sm.counters.invalidJWTTokens, 1)
+		return nil, &authError{status: http.StatusUnauthorized, message: "Invalid token issuer"}
+	}
+	
+	// Reject revoked tokens
+	if revoked, err := sm.isTokenRevoked(ctx, claims); err != nil {
+		sm.logger.Error("Token revocation check failed", zap.Error(err))
+		return nil, &authError{status: http.StatusServiceUnavailable, message: "Unable to verify token"}
+	} else if revoked {
+		sm.logger.Warn("Revoked JWT token", 
+			zap.String("client_ip", clientIP),
+			zap.Any("user_id", claims["sub"]),
+		)
+		atomic.AddInt64(&sm.counters.invalidJWTTokens, 1)
+		// Not against the subject: whoever replays a stolen token must not
+		// get its owner banned
+		sm.bans.RecordFailure(ctx, FailureAuth, "revoked_jwt", clientIP, "")
+		return nil, &authError{status: http.StatusUnauthorized, message: "Token has been revoked"}
+	}
+	
+	// Reject banned users
+	if userID, _ := claims.GetSubject(); userID != "" {
+		if ban := sm.bans.Banned(ctx, BanKindUser, userID); ban != nil {
+			return nil, &authError{status: http.StatusForbidden, message: "Access temporarily blocked", ban: ban}
+		}
+	}
+	
+	return claims, nil
+}
+
+// authError rejects the credentials of a request with the status it calls for
+type authError struct {
+	status  int
+	message string
+	ban     *Ban // set when the caller is banned
+}
+
+func (e *authError) Error() string {
+	return e.message
+}
+
+// abortAuth answers a request whose credentials were rejected
+func (sm *SecurityMiddleware) abortAuth(c *gin.Context, err error) {
+	authErr, ok := err.(*authError)
+	if !ok {
+		authErr = &authError{status: http.StatusUnauthorized, message: err.Error()}
+	}
+	if authErr.ban != nil {
+		sm.abortBanned(c, authErr.ban)
+		return
+	}
+	c.JSON(authErr.status, gin.H{"error": authErr.message})
+	c.Abort()
+}
+
+// abortBanned rejects a request from a banned subject, telling the client when to retry
+func (sm *SecurityMiddleware) abortBanned(c *gin.Context, ban *Ban) {
+	sm.logger.Warn("Banned client attempted access", 
+		zap.String("client_ip", sm.ClientIP(c)),
+		zap.String("ban_kind", string(ban.Kind)),
+		zap.String("reason", ban.Reason),
+	)
+	c.Header("Retry-After", strconv.Itoa(ceilSeconds(time.Until(ban.ExpiresAt))))
+	c.JSON(http.StatusForbidden, gin.H{"error": "Access temporarily blocked", "retry_at": ban.ExpiresAt})
+	c.Abort()
+}
+
+// isTokenRevoked checks the token's jti and the user's revocation watermark
+func (sm *SecurityMiddleware) isTokenRevoked(ctx context.Context, claims jwt.MapClaims) (bool, error) {
+	if jti, ok := claims["jti"].(string); ok && jti != "" {
+		revoked, err := sm.revocations.IsTokenRevoked(ctx, jti)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	
+	userID, err := claims.GetSubject()
+	if err != nil || userID == "" {
+		return false, nil
+	}
+	
+	watermark, err := sm.revocations.UserTokensRevokedBefore(ctx, userID)
+	if err != nil || watermark.IsZero() {
+		return false, err
+	}
+	
+	// Tokens without iat cannot prove they were issued after the watermark.
+	// iat has second precision, so a token issued in the same second is rejected too.
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return true, nil
+	}
+	return !issuedAt.Time.After(watermark), nil
+}
+
 // RequestLogger middleware logs security-relevant requests
 func (sm *SecurityMiddleware) RequestLogger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		atomic.AddInt64(&sm.counters.totalRequests, 1)
 		
 		// Process request
 		c.Next()
@@ -409,7 +597,7 @@ func (sm *SecurityMiddleware) RequestLogger() gin.HandlerFunc {
 		fields := []zap.Field{
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
-			zap.String("client_ip", c.ClientIP()),
+			zap.String("client_ip", sm.ClientIP(c)),
 			zap.String("user_agent", c.Request.UserAgent()),
 			zap.Int("status", status),
 			zap.Duration("duration", duration),
@@ -434,13 +622,24 @@ func (sm *SecurityMiddleware) RequestLogger() gin.HandlerFunc {
 	}
 }
 
-// TrustedProxies configures trusted proxy settings
+// TrustedProxies middleware resolves the real client IP from forwarding headers
+// set by trusted proxies. It should run before any middleware that reads the client IP.
 func (sm *SecurityMiddleware) TrustedProxies() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// This would typically be handled at the Gin engine level
-		// but we can add additional validation here
-		c.Next()
+	return sm.clientIPs.Middleware()
+}
+
+// ConfigureEngine applies the trusted proxy list to Gin's own client IP handling
+func (sm *SecurityMiddleware) ConfigureEngine(engine *gin.Engine) error {
+	return ApplyTrustedProxies(engine, sm.config.TrustedProxies)
+}
+
+// ClientIP returns the client IP resolved by TrustedProxies, resolving it on demand
+// for routes that are not behind the middleware
+func (sm *SecurityMiddleware) ClientIP(c *gin.Context) string {
+	if clientIP := c.GetString(clientIPContextKey); clientIP != "" {
+		return clientIP
 	}
+	return sm.clientIPs.Resolve(c.Request)
 }
 
 // Helper function for min
@@ -451,30 +650,18 @@ func min(a, b int) int {
 	return b
 }
 
-// SecurityMetrics tracks security-related metrics
-type SecurityMetrics struct {
-	RateLimitHits    int64 `json:"rate_limit_hits"`
-	BlockedIPs       int64 `json:"blocked_ips"`
-	InvalidAPIKeys   int64 `json:"invalid_api_keys"`
-	InvalidJWTTokens int64 `json:"invalid_jwt_tokens"`
-	TotalRequests    int64 `json:"total_requests"`
+// CleanupLimiters removes idle rate limiters. A background janitor already does
+// this periodically; calling it directly is only needed to force a sweep.
+func (sm *SecurityMiddleware) CleanupLimiters() {
+	sm.fallbackLimiter.Cleanup()
 }
 
-// GetMetrics returns security metrics
-func (sm *SecurityMiddleware) GetMetrics() SecurityMetrics {
-	// This would typically be implemented with atomic counters
-	// or metrics collection system like Prometheus
-	return SecurityMetrics{}
+// Collectors returns the Prometheus collectors owned by the security middleware
+func (sm *SecurityMiddleware) Collectors() []prometheus.Collector {
+	return append(sm.counterCollectors(), sm.fallbackLimiter.Collectors()...)
 }
 
-// CleanupLimiters removes old rate limiters to prevent memory leaks
-func (sm *SecurityMiddleware) CleanupLimiters() {
-	sm.limiterMu.Lock()
-	defer sm.limiterMu.Unlock()
-	
-	// In a production system, you'd implement proper cleanup logic
-	// based on last access time or use a TTL cache
-	if len(sm.limiters) > 10000 {
-		sm.limiters = make(map[string]*rate.Limiter)
-	}
-} 
\ No newline at end of file
+// Close stops background workers started by the security middleware
+func (sm *SecurityMiddleware) Close() {
+	sm.fallbackLimiter.Close()
+}
\ No newline at end of file
@@ -0,0 +1,385 @@
+package security
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitKey selects what a rate limit policy is keyed on
+type RateLimitKey string
+
+const (
+	// RateLimitByIP keys on the client IP address
+	RateLimitByIP RateLimitKey = "ip"
+	// RateLimitByUser keys on the authenticated user, then API key, then client IP
+	RateLimitByUser RateLimitKey = "user"
+	// RateLimitByAPIKey keys on the API key, then client IP
+	RateLimitByAPIKey RateLimitKey = "api_key"
+)
+
+// redisRetryBackoff is how long the in-memory fallback is used after a Redis error
+const redisRetryBackoff = 5 * time.Second
+
+// Well-known rate limit policy names
+const (
+	RateLimitPolicyDefault = "default"
+	RateLimitPolicyRead    = "read"
+	RateLimitPolicyWrite   = "write"
+	RateLimitPolicyUpload  = "upload"
+	RateLimitPolicyAuth    = "auth"
+
+	// RateLimitPolicyByMethod selects the read or write policy by request method
+	RateLimitPolicyByMethod = "by_method"
+)
+
+// RateLimitPolicy describes how many requests a client may make in a window
+type RateLimitPolicy struct {
+	Name   string        `json:"name"`
+	Limit  int           `json:"limit"` // requests per window, 0 disables the policy
+	Window time.Duration `json:"window"`
+	Burst  int           `json:"burst"` // in-memory token bucket capacity, defaults to Limit
+	KeyBy  RateLimitKey  `json:"key_by"`
+}
+
+// DefaultRateLimitPolicies returns the built-in per-route-group policies.
+// Uploads and authentication are deliberately stricter than reads.
+func DefaultRateLimitPolicies() map[string]RateLimitPolicy {
+	return map[string]RateLimitPolicy{
+		RateLimitPolicyRead:   {Name: RateLimitPolicyRead, Limit: 300, Window: time.Minute, Burst: 60, KeyBy: RateLimitByUser},
+		RateLimitPolicyWrite:  {Name: RateLimitPolicyWrite, Limit: 60, Window: time.Minute, Burst: 20, KeyBy: RateLimitByUser},
+		RateLimitPolicyUpload: {Name: RateLimitPolicyUpload, Limit: 20, Window: time.Hour, Burst: 5, KeyBy: RateLimitByUser},
+		RateLimitPolicyAuth:   {Name: RateLimitPolicyAuth, Limit: 10, Window: time.Minute, Burst: 5, KeyBy: RateLimitByIP},
+	}
+}
+
+// RateLimitResult is the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // until the full budget is available again
+	RetryAfter time.Duration // until the next request would be allowed, when denied
+}
+
+// RateLimiter checks requests against a policy
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// slidingWindowScript atomically trims, counts and records a request in a ZSET sliding-window log.
+// Rejected requests are not recorded so that a throttled client regains budget as the window slides.
+//
+// KEYS[1] = window key
+// ARGV[1] = now (microseconds), ARGV[2] = window (microseconds), ARGV[3] = limit, ARGV[4] = unique member
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, math.ceil(window / 1000))
+
+local reset = 0
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// RedisRateLimiter implements a sliding-window log shared by all replicas
+type RedisRateLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimiter creates a Redis-backed rate limiter
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client}
+}
+
+// Allow records a request for key and reports whether it fits the policy
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	member, err := uniqueMember()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	now := time.Now().UnixMicro()
+	window := policy.Window.Microseconds()
+	values, err := slidingWindowScript.Run(ctx, l.client,
+		[]string{fmt.Sprintf("rate_limit:%s:%s", policy.Name, key)},
+		now, window, policy.Limit, member,
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit script failed: %w", err)
+	}
+	if len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected redis rate limit reply: %v", values)
+	}
+
+	result := RateLimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      policy.Limit,
+		Remaining:  policy.Limit - int(values[1]),
+		ResetAfter: time.Duration(values[2]) * time.Microsecond,
+	}
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+	return result, nil
+}
+
+// uniqueMember returns a ZSET member that cannot collide between concurrent requests
+func uniqueMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "-" + hex.EncodeToString(b), nil
+}
+
+// MemoryRateLimiter implements per-process token buckets
+type MemoryRateLimiter struct {
+	cache *LimiterCache
+}
+
+// NewMemoryRateLimiter creates an in-memory rate limiter backed by a bounded limiter cache
+func NewMemoryRateLimiter(config LimiterCacheConfig) *MemoryRateLimiter {
+	return &MemoryRateLimiter{cache: NewLimiterCache(config)}
+}
+
+// Allow takes a token for key and reports whether it fits the policy
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	limiter := l.limiter(policy.Name+":"+key, policy)
+
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); !reservation.OK() || delay > 0 {
+		reservation.CancelAt(now)
+		return RateLimitResult{
+			Allowed:    false,
+			Limit:      policy.Limit,
+			Remaining:  0,
+			ResetAfter: l.resetAfter(limiter, now),
+			RetryAfter: delay,
+		}, nil
+	}
+
+	return RateLimitResult{
+		Allowed:    true,
+		Limit:      policy.Limit,
+		Remaining:  int(math.Max(0, math.Floor(limiter.TokensAt(now)))),
+		ResetAfter: l.resetAfter(limiter, now),
+	}, nil
+}
+
+// limiter returns the token bucket for key, creating it on first use
+func (l *MemoryRateLimiter) limiter(key string, policy RateLimitPolicy) *rate.Limiter {
+	return l.cache.Get(key, policy.Window, func() *rate.Limiter {
+		burst := policy.Burst
+		if burst <= 0 {
+			burst = policy.Limit
+		}
+		return rate.NewLimiter(rate.Every(policy.Window/time.Duration(policy.Limit)), burst)
+	})
+}
+
+// resetAfter estimates how long until the bucket is full again
+func (l *MemoryRateLimiter) resetAfter(limiter *rate.Limiter, now time.Time) time.Duration {
+	missing := float64(limiter.Burst()) - limiter.TokensAt(now)
+	if missing <= 0 || limiter.Limit() <= 0 {
+		return 0
+	}
+	return time.Duration(missing / float64(limiter.Limit()) * float64(time.Second))
+}
+
+// Cleanup removes limiters whose buckets have refilled while idle
+func (l *MemoryRateLimiter) Cleanup() {
+	l.cache.PurgeExpired()
+}
+
+// Collectors returns the limiter cache's Prometheus collectors
+func (l *MemoryRateLimiter) Collectors() []prometheus.Collector {
+	return l.cache.Collectors()
+}
+
+// Close stops the limiter cache janitor
+func (l *MemoryRateLimiter) Close() {
+	l.cache.Close()
+}
+
+// RateLimitPolicyFor returns the named policy, preferring configured overrides over the defaults.
+// The "default" policy is built from RateLimit, RateLimitWindow and RateLimitBurst.
+func (sm *SecurityMiddleware) RateLimitPolicyFor(name string) RateLimitPolicy {
+	if policy, exists := sm.config.RateLimitPolicies[name]; exists {
+		policy.Name = name
+		return policy
+	}
+	if policy, exists := DefaultRateLimitPolicies()[name]; exists {
+		return policy
+	}
+	return RateLimitPolicy{
+		Name:   RateLimitPolicyDefault,
+		Limit:  sm.config.RateLimit,
+		Window: sm.config.RateLimitWindow,
+		Burst:  sm.config.RateLimitBurst,
+		KeyBy:  RateLimitByIP,
+	}
+}
+
+// RateLimitWith middleware enforces the named policy. Policies keyed by user or
+// API key must be mounted after JWTAuth or APIKeyAuth. Requests the gateway
+// vouched for were already limited there.
+func (sm *SecurityMiddleware) RateLimitWith(name string) gin.HandlerFunc {
+	policy := sm.RateLimitPolicyFor(name)
+	return func(c *gin.Context) {
+		if !viaGateway(c) && !sm.enforceRateLimit(c, policy, sm.rateLimitKey(c, policy.KeyBy)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitByMethod middleware applies the read policy to safe methods and the write policy otherwise
+func (sm *SecurityMiddleware) RateLimitByMethod() gin.HandlerFunc {
+	read := sm.RateLimitPolicyFor(RateLimitPolicyRead)
+	write := sm.RateLimitPolicyFor(RateLimitPolicyWrite)
+	return func(c *gin.Context) {
+		policy := methodPolicy(c.Request.Method, read, write)
+		if !viaGateway(c) && !sm.enforceRateLimit(c, policy, sm.rateLimitKey(c, policy.KeyBy)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitRequest enforces the named policy on a request outside a middleware
+// chain, e.g. in the gateway after authentication. RateLimitPolicyByMethod picks
+// the read or write policy. It reports whether the request may proceed.
+func (sm *SecurityMiddleware) RateLimitRequest(c *gin.Context, name string) bool {
+	policy := sm.RateLimitPolicyFor(name)
+	if name == RateLimitPolicyByMethod {
+		policy = methodPolicy(c.Request.Method, sm.RateLimitPolicyFor(RateLimitPolicyRead), sm.RateLimitPolicyFor(RateLimitPolicyWrite))
+	}
+	return sm.enforceRateLimit(c, policy, sm.rateLimitKey(c, policy.KeyBy))
+}
+
+// methodPolicy returns the read policy for safe methods and the write policy otherwise
+func methodPolicy(method string, read, write RateLimitPolicy) RateLimitPolicy {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return read
+	}
+	return write
+}
+
+// rateLimitKey resolves the client identity a policy is keyed on
+func (sm *SecurityMiddleware) rateLimitKey(c *gin.Context, keyBy RateLimitKey) string {
+	switch keyBy {
+	case RateLimitByUser:
+		if userID := c.GetString("user_id"); userID != "" {
+			return "user:" + userID
+		}
+		fallthrough
+	case RateLimitByAPIKey:
+		if keyID := c.GetString("api_key_id"); keyID != "" {
+			return "api_key:" + keyID
+		}
+	}
+	return "ip:" + sm.ClientIP(c)
+}
+
+// enforceRateLimit checks the policy, writes RateLimit-* headers and aborts
+// with 429 when the client is over budget. It reports whether the request may proceed.
+func (sm *SecurityMiddleware) enforceRateLimit(c *gin.Context, policy RateLimitPolicy, key string) bool {
+	if policy.Limit <= 0 {
+		return true
+	}
+	if policy.Window <= 0 {
+		policy.Window = time.Minute
+	}
+
+	result, err := sm.allow(c.Request.Context(), key, policy)
+	if err != nil {
+		sm.logger.Error("Rate limit error", zap.Error(err))
+		return true // Allow request on limiter error
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+	if result.Allowed {
+		return true
+	}
+
+	atomic.AddInt64(&sm.counters.rateLimitHits, 1)
+	sm.bans.RecordFailure(c.Request.Context(), FailureRateLimit, "rate_limit_exceeded", sm.ClientIP(c), c.GetString("user_id"))
+	retryAfter := ceilSeconds(result.RetryAfter)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	sm.logger.Warn("Rate limit exceeded",
+		zap.String("policy", policy.Name),
+		zap.String("rate_limit_key", key),
+		zap.String("client_ip", sm.ClientIP(c)),
+		zap.String("path", c.Request.URL.Path),
+	)
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Rate limit exceeded",
+		"policy":      policy.Name,
+		"retry_after": retryAfter,
+	})
+	c.Abort()
+	return false
+}
+
+// allow checks the primary limiter, switching to the in-memory fallback while Redis
+// is failing. After an error Redis is not retried for redisRetryBackoff so that an
+// outage does not add a failed round trip to every request.
+func (sm *SecurityMiddleware) allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	if sm.limiter == RateLimiter(sm.fallbackLimiter) {
+		return sm.fallbackLimiter.Allow(ctx, key, policy)
+	}
+
+	now := time.Now().UnixNano()
+	if now >= atomic.LoadInt64(&sm.redisRetryAt) {
+		result, err := sm.limiter.Allow(ctx, key, policy)
+		if err == nil {
+			return result, nil
+		}
+		atomic.StoreInt64(&sm.redisRetryAt, now+int64(redisRetryBackoff))
+		sm.logger.Error("Redis rate limit error, using in-memory fallback", zap.Error(err))
+	}
+
+	// Keep limiting per replica while Redis is unavailable
+	return sm.fallbackLimiter.Allow(ctx, key, policy)
+}
+
+// ceilSeconds rounds a duration up to whole seconds for HTTP headers
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,70 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRateLimitWith_PolicyAndHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sm := NewSecurityMiddleware(&SecurityConfig{
+		RateLimitPolicies: map[string]RateLimitPolicy{
+			RateLimitPolicyUpload: {Limit: 2, Window: time.Minute, KeyBy: RateLimitByUser},
+		},
+	}, zap.NewNop(), nil)
+
+	router := gin.New()
+	router.POST("/upload", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+		c.Next()
+	}, sm.RateLimitWith(RateLimitPolicyUpload), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	upload := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		req.Header.Set("X-Test-User", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := upload("athlete-1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", first.Header().Get("RateLimit-Policy"))
+
+	assert.Equal(t, http.StatusCreated, upload("athlete-1").Code)
+
+	denied := upload("athlete-1")
+	assert.Equal(t, http.StatusTooManyRequests, denied.Code)
+	assert.Equal(t, "0", denied.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, denied.Header().Get("Retry-After"))
+	assert.Equal(t, int64(1), sm.GetMetrics().RateLimitHits)
+
+	// Budgets are per user, not per IP
+	assert.Equal(t, http.StatusCreated, upload("athlete-2").Code)
+}
+
+func TestRateLimitPolicyFor_Defaults(t *testing.T) {
+	sm := NewSecurityMiddleware(&SecurityConfig{
+		RateLimit:       100,
+		RateLimitWindow: time.Minute,
+	}, zap.NewNop(), nil)
+
+	auth := sm.RateLimitPolicyFor(RateLimitPolicyAuth)
+	read := sm.RateLimitPolicyFor(RateLimitPolicyRead)
+	assert.Less(t, auth.Limit, read.Limit)
+	assert.Equal(t, RateLimitByIP, auth.KeyBy)
+
+	fallback := sm.RateLimitPolicyFor(RateLimitPolicyDefault)
+	assert.Equal(t, 100, fallback.Limit)
+	assert.Equal(t, RateLimitByIP, fallback.KeyBy)
+}
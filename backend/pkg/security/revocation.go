@@ -0,0 +1,209 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+const (
+	revokedTokenKeyPrefix = "revoked_jti:"
+	revokedUserKeyPrefix  = "revoked_user:"
+)
+
+// RevocationStore tracks JWTs that must be rejected before they expire
+type RevocationStore interface {
+	// RevokeToken revokes a single token by its jti until expiresAt
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// IsTokenRevoked reports whether the token with the given jti was revoked
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// RevokeUserTokens invalidates every token issued to the user before the given time
+	RevokeUserTokens(ctx context.Context, userID string, before time.Time) error
+	// UserTokensRevokedBefore returns the user's revocation watermark, or the zero time if none
+	UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error)
+}
+
+// NewRevocationStore creates a Redis-backed revocation store, or an in-memory
+// one when no Redis client is configured. maxTokenLifetime bounds how long a
+// user watermark has to be kept: older tokens have expired on their own by then.
+func NewRevocationStore(client *redis.Client, logger *zap.Logger, maxTokenLifetime time.Duration) RevocationStore {
+	memory := NewMemoryRevocationStore(maxTokenLifetime)
+	if client == nil {
+		return memory
+	}
+	return &RedisRevocationStore{
+		client:           client,
+		logger:           logger,
+		fallback:         memory,
+		maxTokenLifetime: maxTokenLifetime,
+	}
+}
+
+// MemoryRevocationStore keeps revocations in process memory
+type MemoryRevocationStore struct {
+	mu               sync.RWMutex
+	tokens           map[string]time.Time // jti -> token expiry
+	users            map[string]time.Time // user ID -> watermark
+	maxTokenLifetime time.Duration
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store
+func NewMemoryRevocationStore(maxTokenLifetime time.Duration) *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		tokens:           make(map[string]time.Time),
+		users:            make(map[string]time.Time),
+		maxTokenLifetime: maxTokenLifetime,
+	}
+}
+
+// RevokeToken revokes a single token by its jti
+func (s *MemoryRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeExpired(time.Now())
+	s.tokens[jti] = expiresAt
+	return nil
+}
+
+// IsTokenRevoked reports whether the token was revoked
+func (s *MemoryRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expiresAt, exists := s.tokens[jti]
+	if !exists {
+		return false, nil
+	}
+	return expiresAt.IsZero() || time.Now().Before(expiresAt), nil
+}
+
+// RevokeUserTokens moves the user's watermark forward
+func (s *MemoryRevocationStore) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, exists := s.users[userID]; !exists || before.After(current) {
+		s.users[userID] = before
+	}
+	return nil
+}
+
+// UserTokensRevokedBefore returns the user's watermark
+func (s *MemoryRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	watermark := s.users[userID]
+	if s.maxTokenLifetime > 0 && time.Since(watermark) > s.maxTokenLifetime {
+		return time.Time{}, nil
+	}
+	return watermark, nil
+}
+
+// purgeExpired drops entries that can no longer match a valid token.
+// Callers must hold the write lock.
+func (s *MemoryRevocationStore) purgeExpired(now time.Time) {
+	for jti, expiresAt := range s.tokens {
+		if !expiresAt.IsZero() && now.After(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	if s.maxTokenLifetime > 0 {
+		for userID, watermark := range s.users {
+			if now.Sub(watermark) > s.maxTokenLifetime {
+				delete(s.users, userID)
+			}
+		}
+	}
+}
+
+// RedisRevocationStore keeps revocations in Redis so every replica sees them.
+// Revocations are mirrored in memory and served from there while Redis is unavailable.
+type RedisRevocationStore struct {
+	client           *redis.Client
+	logger           *zap.Logger
+	fallback         *MemoryRevocationStore
+	maxTokenLifetime time.Duration
+}
+
+// RevokeToken revokes a single token by its jti
+func (s *RedisRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.fallback.RevokeToken(ctx, jti, expiresAt)
+
+	ttl := time.Duration(0)
+	if !expiresAt.IsZero() {
+		ttl = time.Until(expiresAt)
+		if ttl <= 0 {
+			return nil // Already expired, nothing to revoke
+		}
+	}
+
+	if err := s.client.Set(ctx, revokedTokenKeyPrefix+jti, expiresAt.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store token revocation: %w", err)
+	}
+	return nil
+}
+
+// IsTokenRevoked reports whether the token was revoked
+func (s *RedisRevocationStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	exists, err := s.client.Exists(ctx, revokedTokenKeyPrefix+jti).Result()
+	if err != nil {
+		s.logger.Warn("Redis revocation lookup failed, using in-memory fallback", zap.Error(err))
+		return s.fallback.IsTokenRevoked(ctx, jti)
+	}
+	return exists > 0, nil
+}
+
+// revokeUserScript moves a user's watermark forward and sets its expiry in
+// one step, so concurrent revocations never move it back
+//
+// KEYS[1] = watermark key
+// ARGV[1] = watermark (unix seconds), ARGV[2] = expiry (milliseconds, 0 keeps it forever)
+var revokeUserScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]))
+if current and current >= tonumber(ARGV[1]) then
+	return 0
+end
+
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// RevokeUserTokens moves the user's watermark forward
+func (s *RedisRevocationStore) RevokeUserTokens(ctx context.Context, userID string, before time.Time) error {
+	s.fallback.RevokeUserTokens(ctx, userID, before)
+
+	keys := []string{revokedUserKeyPrefix + userID}
+	if err := revokeUserScript.Run(ctx, s.client, keys, before.Unix(), s.maxTokenLifetime.Milliseconds()).Err(); err != nil {
+		return fmt.Errorf("failed to store user revocation: %w", err)
+	}
+	return nil
+}
+
+// UserTokensRevokedBefore returns the user's watermark
+func (s *RedisRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	value, err := s.client.Get(ctx, revokedUserKeyPrefix+userID).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		s.logger.Warn("Redis revocation lookup failed, using in-memory fallback", zap.Error(err))
+		return s.fallback.UserTokensRevokedBefore(ctx, userID)
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid revocation watermark for user %s: %w", userID, err)
+	}
+	return time.Unix(unix, 0), nil
+}
@@ -0,0 +1,101 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestSecurityMiddleware() *SecurityMiddleware {
+	return NewSecurityMiddleware(&SecurityConfig{
+		JWTSecret:     "test-secret",
+		JWTExpiration: time.Hour,
+		JWTIssuer:     "dunksense",
+	}, zap.NewNop(), nil)
+}
+
+func signTestToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	assert.NoError(t, err)
+	return token
+}
+
+func performJWTRequest(sm *SecurityMiddleware, token string) int {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/protected", sm.JWTAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestJWTAuth_RevokedJTI(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	token := signTestToken(t, jwt.MapClaims{
+		"sub": "user-123",
+		"jti": "token-1",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	assert.Equal(t, http.StatusOK, performJWTRequest(sm, token))
+
+	err := sm.Revocations().RevokeToken(context.Background(), "token-1", time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, performJWTRequest(sm, token))
+	assert.Equal(t, int64(1), sm.GetMetrics().InvalidJWTTokens)
+}
+
+func TestJWTAuth_UserWatermark(t *testing.T) {
+	sm := newTestSecurityMiddleware()
+	oldToken := signTestToken(t, jwt.MapClaims{
+		"sub": "user-123",
+		"iat": time.Now().Add(-10 * time.Minute).Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	newToken := signTestToken(t, jwt.MapClaims{
+		"sub": "user-123",
+		"iat": time.Now().Add(time.Minute).Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	otherUser := signTestToken(t, jwt.MapClaims{
+		"sub": "user-456",
+		"iat": time.Now().Add(-10 * time.Minute).Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	err := sm.Revocations().RevokeUserTokens(context.Background(), "user-123", time.Now())
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, performJWTRequest(sm, oldToken))
+	assert.Equal(t, http.StatusOK, performJWTRequest(sm, newToken))
+	assert.Equal(t, http.StatusOK, performJWTRequest(sm, otherUser))
+}
+
+func TestMemoryRevocationStore_ExpiredEntries(t *testing.T) {
+	store := NewMemoryRevocationStore(time.Hour)
+	ctx := context.Background()
+
+	assert.NoError(t, store.RevokeToken(ctx, "expired", time.Now().Add(-time.Minute)))
+	revoked, err := store.IsTokenRevoked(ctx, "expired")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	assert.NoError(t, store.RevokeUserTokens(ctx, "user-123", time.Now().Add(-2*time.Hour)))
+	watermark, err := store.UserTokensRevokedBefore(ctx, "user-123")
+	assert.NoError(t, err)
+	assert.True(t, watermark.IsZero())
+}
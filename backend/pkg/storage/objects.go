@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Bucket       string    `json:"bucket"`
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// ObjectStore stores blobs such as uploaded videos and export archives
+type ObjectStore interface {
+	Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, *ObjectInfo, error)
+	List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
+	Delete(ctx context.Context, bucket, key string) error
+}
+
+// MemoryObjectStore is an in-memory object store for development and tests
+type MemoryObjectStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	info ObjectInfo
+	data []byte
+}
+
+// NewMemoryObjectStore creates an in-memory object store
+func NewMemoryObjectStore() *MemoryObjectStore {
+	return &MemoryObjectStore{objects: make(map[string]memoryObject)}
+}
+
+func objectID(bucket, key string) string {
+	return bucket + "/" + key
+}
+
+// Put stores an object
+func (s *MemoryObjectStore) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[objectID(bucket, key)] = memoryObject{
+		info: ObjectInfo{
+			Bucket:       bucket,
+			Key:          key,
+			Size:         int64(len(data)),
+			ContentType:  contentType,
+			LastModified: time.Now().UTC(),
+		},
+		data: data,
+	}
+	return nil
+}
+
+// Get returns an object's content
+func (s *MemoryObjectStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, *ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, exists := s.objects[objectID(bucket, key)]
+	if !exists {
+		return nil, nil, ErrObjectNotFound
+	}
+	info := object.info
+	return io.NopCloser(bytes.NewReader(object.data)), &info, nil
+}
+
+// List returns the objects of a bucket whose key starts with prefix
+func (s *MemoryObjectStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]ObjectInfo, 0)
+	for _, object := range s.objects {
+		if object.info.Bucket == bucket && strings.HasPrefix(object.info.Key, prefix) {
+			objects = append(objects, object.info)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Delete removes an object. Deleting a missing object is not an error.
+func (s *MemoryObjectStore) Delete(ctx context.Context, bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, objectID(bucket, key))
+	return nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// S3ObjectStore stores objects in S3 or an S3-compatible service such as MinIO
+type S3ObjectStore struct {
+	client *minio.Client
+}
+
+// NewS3ObjectStore connects to the endpoint from configuration, e.g.
+// "s3.eu-central-1.amazonaws.com" or "minio:9000". A scheme in the endpoint
+// takes precedence over S3UseSSL. The transport may be nil to use the default one.
+func NewS3ObjectStore(cfg config.ExternalConfig, transport http.RoundTripper) (*S3ObjectStore, error) {
+	endpoint := cfg.S3Endpoint
+	secure := cfg.S3UseSSL
+	if strings.Contains(endpoint, "://") {
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+		}
+		endpoint, secure = parsed.Host, parsed.Scheme == "https"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure:    secure,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &S3ObjectStore{client: client}, nil
+}
+
+// Put uploads an object. A negative size streams an object of unknown length.
+func (s *S3ObjectStore) Put(ctx context.Context, bucket, key string, body io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, bucket, key, body, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3ObjectStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, *ObjectInfo, error) {
+	object, err := s.client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download %s/%s: %w", bucket, key, err)
+	}
+
+	// GetObject is lazy, Stat surfaces a missing object
+	stat, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, nil, ErrObjectNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to download %s/%s: %w", bucket, key, err)
+	}
+	return object, objectInfo(bucket, stat), nil
+}
+
+// List returns the objects of a bucket whose key starts with prefix
+func (s *S3ObjectStore) List(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	objects := make([]ObjectInfo, 0)
+	for object := range s.client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list %s/%s: %w", bucket, prefix, object.Err)
+		}
+		objects = append(objects, *objectInfo(bucket, object))
+	}
+	return objects, nil
+}
+
+// Delete removes an object. Deleting a missing object is not an error.
+func (s *S3ObjectStore) Delete(ctx context.Context, bucket, key string) error {
+	if err := s.client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+func objectInfo(bucket string, object minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Bucket:       bucket,
+		Key:          object.Key,
+		Size:         object.Size,
+		ContentType:  object.ContentType,
+		LastModified: object.LastModified,
+	}
+}
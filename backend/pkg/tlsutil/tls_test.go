@@ -0,0 +1,187 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Danchouvzv/DunkSense/backend/pkg/config"
+)
+
+// writeTestPKI writes a CA and a leaf certificate usable for both server and client auth
+func writeTestPKI(t *testing.T, dir string, serial int64) config.TLSConfig {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "dunksense-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(serial + 1),
+		Subject:      pkix.Name{CommonName: "metrics-svc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	require.NoError(t, err)
+
+	cfg := config.TLSConfig{
+		Enabled:           true,
+		CertPath:          filepath.Join(dir, "tls.crt"),
+		KeyPath:           filepath.Join(dir, "tls.key"),
+		CAPath:            filepath.Join(dir, "ca.crt"),
+		RequireClientCert: true,
+		ReloadInterval:    time.Hour,
+	}
+	writePEM(t, cfg.CAPath, "CERTIFICATE", caDER)
+	writePEM(t, cfg.CertPath, "CERTIFICATE", leafDER)
+	writePEM(t, cfg.KeyPath, "EC PRIVATE KEY", keyDER)
+	return cfg
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+}
+
+func TestManager_MutualTLS(t *testing.T) {
+	cfg := writeTestPKI(t, t.TempDir(), 1)
+	manager, err := NewManager(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer manager.Close()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = manager.ServerTLSConfig()
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	resp, err := manager.HTTPClient(5 * time.Second).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Without a client certificate the handshake is rejected
+	_, caPool := manager.current()
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caPool}}}
+	_, err = anonymous.Get(server.URL)
+	assert.Error(t, err)
+}
+
+func TestManager_ReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	cfg := writeTestPKI(t, dir, 1)
+	manager, err := NewManager(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer manager.Close()
+
+	before, _ := manager.current()
+	assert.NoError(t, manager.ReloadIfChanged())
+	unchanged, _ := manager.current()
+	assert.Same(t, before, unchanged)
+
+	writeTestPKI(t, dir, 10)
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{cfg.CertPath, cfg.KeyPath, cfg.CAPath} {
+		require.NoError(t, os.Chtimes(path, future, future))
+	}
+	assert.NoError(t, manager.ReloadIfChanged())
+
+	after, _ := manager.current()
+	assert.NotEqual(t, before.Certificate[0], after.Certificate[0])
+}
+
+func TestManager_ClientTrustsRotatedCA(t *testing.T) {
+	dir := t.TempDir()
+	cfg := writeTestPKI(t, dir, 1)
+	manager, err := NewManager(cfg, zap.NewNop())
+	require.NoError(t, err)
+	defer manager.Close()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = manager.ServerTLSConfig()
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	// The client is created before the rotation and kept across it
+	transport := manager.HTTPTransport()
+	transport.DisableKeepAlives = true
+	client := &http.Client{Timeout: 5 * time.Second, Transport: transport}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	writeTestPKI(t, dir, 10)
+	future := time.Now().Add(time.Minute)
+	for _, path := range []string{cfg.CertPath, cfg.KeyPath, cfg.CAPath} {
+		require.NoError(t, os.Chtimes(path, future, future))
+	}
+	require.NoError(t, manager.ReloadIfChanged())
+
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Servers signed by another CA are still rejected
+	other, err := NewManager(writeTestPKI(t, t.TempDir(), 20), zap.NewNop())
+	require.NoError(t, err)
+	defer other.Close()
+	otherServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	otherServer.TLS = other.ServerTLSConfig()
+	otherServer.Config.ErrorLog = log.New(io.Discard, "", 0)
+	otherServer.StartTLS()
+	defer otherServer.Close()
+
+	_, err = client.Get(otherServer.URL)
+	assert.ErrorContains(t, err, "certificate signed by unknown authority")
+}
+
+func TestManager_Disabled(t *testing.T) {
+	manager, err := NewManager(config.TLSConfig{}, zap.NewNop())
+	assert.NoError(t, err)
+	assert.Nil(t, manager)
+	assert.False(t, manager.Enabled())
+	assert.Nil(t, manager.ServerTLSConfig())
+	assert.Nil(t, manager.ClientTLSConfig())
+	assert.NotNil(t, manager.HTTPTransport())
+	manager.Close()
+}
@@ -0,0 +1,2657 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: proto/metrics/v1/metrics.proto
+
+package metricsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// JumpMetric is a single jump measurement
+type JumpMetric struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AthleteId string                 `protobuf:"bytes,2,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	SessionId string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Core jump metrics
+	HeightCm           float64 `protobuf:"fixed64,5,opt,name=height_cm,json=heightCm,proto3" json:"height_cm,omitempty"`
+	ContactTimeMs      int32   `protobuf:"varint,6,opt,name=contact_time_ms,json=contactTimeMs,proto3" json:"contact_time_ms,omitempty"`
+	FlightTimeMs       int32   `protobuf:"varint,7,opt,name=flight_time_ms,json=flightTimeMs,proto3" json:"flight_time_ms,omitempty"`
+	TakeoffVelocityMps float64 `protobuf:"fixed64,23,opt,name=takeoff_velocity_mps,json=takeoffVelocityMps,proto3" json:"takeoff_velocity_mps,omitempty"`
+	LandingForceN      float64 `protobuf:"fixed64,24,opt,name=landing_force_n,json=landingForceN,proto3" json:"landing_force_n,omitempty"`
+	// Biomechanical analysis
+	ValgusAngleDeg float64 `protobuf:"fixed64,8,opt,name=valgus_angle_deg,json=valgusAngleDeg,proto3" json:"valgus_angle_deg,omitempty"`
+	KneeFlexionDeg float64 `protobuf:"fixed64,9,opt,name=knee_flexion_deg,json=kneeFlexionDeg,proto3" json:"knee_flexion_deg,omitempty"`
+	HipFlexionDeg  float64 `protobuf:"fixed64,10,opt,name=hip_flexion_deg,json=hipFlexionDeg,proto3" json:"hip_flexion_deg,omitempty"`
+	// Technique scores (0-100)
+	TakeoffScore int32 `protobuf:"varint,11,opt,name=takeoff_score,json=takeoffScore,proto3" json:"takeoff_score,omitempty"`
+	LandingScore int32 `protobuf:"varint,12,opt,name=landing_score,json=landingScore,proto3" json:"landing_score,omitempty"`
+	OverallScore int32 `protobuf:"varint,13,opt,name=overall_score,json=overallScore,proto3" json:"overall_score,omitempty"`
+	// Left and right leg balance
+	SymmetryScore int32 `protobuf:"varint,25,opt,name=symmetry_score,json=symmetryScore,proto3" json:"symmetry_score,omitempty"`
+	// Device and processing info
+	DeviceType       string  `protobuf:"bytes,14,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	AppVersion       string  `protobuf:"bytes,15,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
+	ProcessingTimeMs int32   `protobuf:"varint,16,opt,name=processing_time_ms,json=processingTimeMs,proto3" json:"processing_time_ms,omitempty"`
+	Confidence       float64 `protobuf:"fixed64,17,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	DeviceId         string  `protobuf:"bytes,18,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// Set by the server for signed uploads only
+	DeviceVerified bool `protobuf:"varint,19,opt,name=device_verified,json=deviceVerified,proto3" json:"device_verified,omitempty"`
+	// Additional metadata
+	Location *Location    `protobuf:"bytes,20,opt,name=location,proto3" json:"location,omitempty"`
+	Weather  *Weather     `protobuf:"bytes,21,opt,name=weather,proto3" json:"weather,omitempty"`
+	Pose     []*PoseFrame `protobuf:"bytes,26,rep,name=pose,proto3" json:"pose,omitempty"`
+	Notes    string       `protobuf:"bytes,22,opt,name=notes,proto3" json:"notes,omitempty"`
+}
+
+func (x *JumpMetric) Reset() {
+	*x = JumpMetric{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JumpMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JumpMetric) ProtoMessage() {}
+
+func (x *JumpMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JumpMetric.ProtoReflect.Descriptor instead.
+func (*JumpMetric) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JumpMetric) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *JumpMetric) GetHeightCm() float64 {
+	if x != nil {
+		return x.HeightCm
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetContactTimeMs() int32 {
+	if x != nil {
+		return x.ContactTimeMs
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetFlightTimeMs() int32 {
+	if x != nil {
+		return x.FlightTimeMs
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetTakeoffVelocityMps() float64 {
+	if x != nil {
+		return x.TakeoffVelocityMps
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetLandingForceN() float64 {
+	if x != nil {
+		return x.LandingForceN
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetValgusAngleDeg() float64 {
+	if x != nil {
+		return x.ValgusAngleDeg
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetKneeFlexionDeg() float64 {
+	if x != nil {
+		return x.KneeFlexionDeg
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetHipFlexionDeg() float64 {
+	if x != nil {
+		return x.HipFlexionDeg
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetTakeoffScore() int32 {
+	if x != nil {
+		return x.TakeoffScore
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetLandingScore() int32 {
+	if x != nil {
+		return x.LandingScore
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetOverallScore() int32 {
+	if x != nil {
+		return x.OverallScore
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetSymmetryScore() int32 {
+	if x != nil {
+		return x.SymmetryScore
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetProcessingTimeMs() int32 {
+	if x != nil {
+		return x.ProcessingTimeMs
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *JumpMetric) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *JumpMetric) GetDeviceVerified() bool {
+	if x != nil {
+		return x.DeviceVerified
+	}
+	return false
+}
+
+func (x *JumpMetric) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *JumpMetric) GetWeather() *Weather {
+	if x != nil {
+		return x.Weather
+	}
+	return nil
+}
+
+func (x *JumpMetric) GetPose() []*PoseFrame {
+	if x != nil {
+		return x.Pose
+	}
+	return nil
+}
+
+func (x *JumpMetric) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+// Location is the GPS position of a jump
+type Location struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Latitude  float64 `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Altitude  float64 `protobuf:"fixed64,3,opt,name=altitude,proto3" json:"altitude,omitempty"`
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Location) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Location) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Location) GetAltitude() float64 {
+	if x != nil {
+		return x.Altitude
+	}
+	return 0
+}
+
+// Weather describes the conditions of a jump
+type Weather struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Temperature float64 `protobuf:"fixed64,1,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	Humidity    float64 `protobuf:"fixed64,2,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure    float64 `protobuf:"fixed64,3,opt,name=pressure,proto3" json:"pressure,omitempty"`
+}
+
+func (x *Weather) Reset() {
+	*x = Weather{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Weather) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Weather) ProtoMessage() {}
+
+func (x *Weather) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Weather.ProtoReflect.Descriptor instead.
+func (*Weather) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Weather) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Weather) GetHumidity() float64 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Weather) GetPressure() float64 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+// PoseFrame is the pose estimated in one video frame of a jump
+type PoseFrame struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// On the clock of the capturing device
+	TimeMs int64 `protobuf:"varint,1,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`
+	// By joint, e.g. left_knee
+	Keypoints  map[string]*Keypoint `protobuf:"bytes,2,rep,name=keypoints,proto3" json:"keypoints,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Confidence float64              `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+}
+
+func (x *PoseFrame) Reset() {
+	*x = PoseFrame{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PoseFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PoseFrame) ProtoMessage() {}
+
+func (x *PoseFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PoseFrame.ProtoReflect.Descriptor instead.
+func (*PoseFrame) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PoseFrame) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *PoseFrame) GetKeypoints() map[string]*Keypoint {
+	if x != nil {
+		return x.Keypoints
+	}
+	return nil
+}
+
+func (x *PoseFrame) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+// Keypoint is the position of a joint in normalized image coordinates
+type Keypoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X          float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y          float64 `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Confidence float64 `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+}
+
+func (x *Keypoint) Reset() {
+	*x = Keypoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Keypoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Keypoint) ProtoMessage() {}
+
+func (x *Keypoint) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Keypoint.ProtoReflect.Descriptor instead.
+func (*Keypoint) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Keypoint) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Keypoint) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Keypoint) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+// JumpSession is a training session
+type JumpSession struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AthleteId       string                 `protobuf:"bytes,2,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	StartTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	DurationSeconds int32                  `protobuf:"varint,5,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	JumpCount       int32                  `protobuf:"varint,6,opt,name=jump_count,json=jumpCount,proto3" json:"jump_count,omitempty"`
+	MaxHeightCm     float64                `protobuf:"fixed64,7,opt,name=max_height_cm,json=maxHeightCm,proto3" json:"max_height_cm,omitempty"`
+	AvgHeightCm     float64                `protobuf:"fixed64,8,opt,name=avg_height_cm,json=avgHeightCm,proto3" json:"avg_height_cm,omitempty"`
+	LoadScore       int32                  `protobuf:"varint,9,opt,name=load_score,json=loadScore,proto3" json:"load_score,omitempty"`
+	// Rate of Perceived Exertion (1-10)
+	Rpe   int32         `protobuf:"varint,10,opt,name=rpe,proto3" json:"rpe,omitempty"`
+	Jumps []*JumpMetric `protobuf:"bytes,11,rep,name=jumps,proto3" json:"jumps,omitempty"`
+}
+
+func (x *JumpSession) Reset() {
+	*x = JumpSession{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JumpSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JumpSession) ProtoMessage() {}
+
+func (x *JumpSession) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JumpSession.ProtoReflect.Descriptor instead.
+func (*JumpSession) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JumpSession) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JumpSession) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *JumpSession) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *JumpSession) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *JumpSession) GetDurationSeconds() int32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *JumpSession) GetJumpCount() int32 {
+	if x != nil {
+		return x.JumpCount
+	}
+	return 0
+}
+
+func (x *JumpSession) GetMaxHeightCm() float64 {
+	if x != nil {
+		return x.MaxHeightCm
+	}
+	return 0
+}
+
+func (x *JumpSession) GetAvgHeightCm() float64 {
+	if x != nil {
+		return x.AvgHeightCm
+	}
+	return 0
+}
+
+func (x *JumpSession) GetLoadScore() int32 {
+	if x != nil {
+		return x.LoadScore
+	}
+	return 0
+}
+
+func (x *JumpSession) GetRpe() int32 {
+	if x != nil {
+		return x.Rpe
+	}
+	return 0
+}
+
+func (x *JumpSession) GetJumps() []*JumpMetric {
+	if x != nil {
+		return x.Jumps
+	}
+	return nil
+}
+
+// AthleteProfile describes an athlete
+type AthleteProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId   string  `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name     string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Age      int32   `protobuf:"varint,4,opt,name=age,proto3" json:"age,omitempty"`
+	HeightCm int32   `protobuf:"varint,5,opt,name=height_cm,json=heightCm,proto3" json:"height_cm,omitempty"`
+	WeightKg float64 `protobuf:"fixed64,6,opt,name=weight_kg,json=weightKg,proto3" json:"weight_kg,omitempty"`
+	// beginner, intermediate, advanced or pro
+	SportLevel string                 `protobuf:"bytes,7,opt,name=sport_level,json=sportLevel,proto3" json:"sport_level,omitempty"`
+	Minor      bool                   `protobuf:"varint,8,opt,name=minor,proto3" json:"minor,omitempty"`
+	CreatedAt  *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt  *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Performance baselines
+	MaxJumpHeightCm   float64 `protobuf:"fixed64,11,opt,name=max_jump_height_cm,json=maxJumpHeightCm,proto3" json:"max_jump_height_cm,omitempty"`
+	AvgJumpHeightCm   float64 `protobuf:"fixed64,12,opt,name=avg_jump_height_cm,json=avgJumpHeightCm,proto3" json:"avg_jump_height_cm,omitempty"`
+	BestContactTimeMs int32   `protobuf:"varint,13,opt,name=best_contact_time_ms,json=bestContactTimeMs,proto3" json:"best_contact_time_ms,omitempty"`
+	// Reactive Strength Index
+	Rsi float64 `protobuf:"fixed64,14,opt,name=rsi,proto3" json:"rsi,omitempty"`
+	// Training preferences
+	Goals                []string         `protobuf:"bytes,15,rep,name=goals,proto3" json:"goals,omitempty"`
+	TrainingDays         []string         `protobuf:"bytes,16,rep,name=training_days,json=trainingDays,proto3" json:"training_days,omitempty"`
+	PreferredDurationMin int32            `protobuf:"varint,17,opt,name=preferred_duration_min,json=preferredDurationMin,proto3" json:"preferred_duration_min,omitempty"`
+	Privacy              *PrivacySettings `protobuf:"bytes,18,opt,name=privacy,proto3" json:"privacy,omitempty"`
+}
+
+func (x *AthleteProfile) Reset() {
+	*x = AthleteProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AthleteProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AthleteProfile) ProtoMessage() {}
+
+func (x *AthleteProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AthleteProfile.ProtoReflect.Descriptor instead.
+func (*AthleteProfile) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AthleteProfile) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AthleteProfile) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AthleteProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AthleteProfile) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetHeightCm() int32 {
+	if x != nil {
+		return x.HeightCm
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetWeightKg() float64 {
+	if x != nil {
+		return x.WeightKg
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetSportLevel() string {
+	if x != nil {
+		return x.SportLevel
+	}
+	return ""
+}
+
+func (x *AthleteProfile) GetMinor() bool {
+	if x != nil {
+		return x.Minor
+	}
+	return false
+}
+
+func (x *AthleteProfile) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *AthleteProfile) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *AthleteProfile) GetMaxJumpHeightCm() float64 {
+	if x != nil {
+		return x.MaxJumpHeightCm
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetAvgJumpHeightCm() float64 {
+	if x != nil {
+		return x.AvgJumpHeightCm
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetBestContactTimeMs() int32 {
+	if x != nil {
+		return x.BestContactTimeMs
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetRsi() float64 {
+	if x != nil {
+		return x.Rsi
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetGoals() []string {
+	if x != nil {
+		return x.Goals
+	}
+	return nil
+}
+
+func (x *AthleteProfile) GetTrainingDays() []string {
+	if x != nil {
+		return x.TrainingDays
+	}
+	return nil
+}
+
+func (x *AthleteProfile) GetPreferredDurationMin() int32 {
+	if x != nil {
+		return x.PreferredDurationMin
+	}
+	return 0
+}
+
+func (x *AthleteProfile) GetPrivacy() *PrivacySettings {
+	if x != nil {
+		return x.Privacy
+	}
+	return nil
+}
+
+// PrivacySettings records what an athlete consented to
+type PrivacySettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LocationCapture    bool                   `protobuf:"varint,1,opt,name=location_capture,json=locationCapture,proto3" json:"location_capture,omitempty"`
+	LeaderboardVisible bool                   `protobuf:"varint,2,opt,name=leaderboard_visible,json=leaderboardVisible,proto3" json:"leaderboard_visible,omitempty"`
+	CoachAccess        bool                   `protobuf:"varint,3,opt,name=coach_access,json=coachAccess,proto3" json:"coach_access,omitempty"`
+	ModelTraining      bool                   `protobuf:"varint,4,opt,name=model_training,json=modelTraining,proto3" json:"model_training,omitempty"`
+	Guardian           *GuardianConsent       `protobuf:"bytes,5,opt,name=guardian,proto3" json:"guardian,omitempty"`
+	Version            int32                  `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`
+	UpdatedAt          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+}
+
+func (x *PrivacySettings) Reset() {
+	*x = PrivacySettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PrivacySettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrivacySettings) ProtoMessage() {}
+
+func (x *PrivacySettings) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrivacySettings.ProtoReflect.Descriptor instead.
+func (*PrivacySettings) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PrivacySettings) GetLocationCapture() bool {
+	if x != nil {
+		return x.LocationCapture
+	}
+	return false
+}
+
+func (x *PrivacySettings) GetLeaderboardVisible() bool {
+	if x != nil {
+		return x.LeaderboardVisible
+	}
+	return false
+}
+
+func (x *PrivacySettings) GetCoachAccess() bool {
+	if x != nil {
+		return x.CoachAccess
+	}
+	return false
+}
+
+func (x *PrivacySettings) GetModelTraining() bool {
+	if x != nil {
+		return x.ModelTraining
+	}
+	return false
+}
+
+func (x *PrivacySettings) GetGuardian() *GuardianConsent {
+	if x != nil {
+		return x.Guardian
+	}
+	return nil
+}
+
+func (x *PrivacySettings) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PrivacySettings) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// GuardianConsent records the guardian who consented on behalf of a minor
+type GuardianConsent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ConsentedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=consented_at,json=consentedAt,proto3" json:"consented_at,omitempty"`
+}
+
+func (x *GuardianConsent) Reset() {
+	*x = GuardianConsent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GuardianConsent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GuardianConsent) ProtoMessage() {}
+
+func (x *GuardianConsent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GuardianConsent.ProtoReflect.Descriptor instead.
+func (*GuardianConsent) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GuardianConsent) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GuardianConsent) GetConsentedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConsentedAt
+	}
+	return nil
+}
+
+// MetricsSummary aggregates the metrics of an athlete over a period
+type MetricsSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AthleteId string `protobuf:"bytes,1,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	// daily, weekly or monthly
+	Period    string                 `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	StartDate *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	// Jump statistics
+	TotalJumps          int32   `protobuf:"varint,5,opt,name=total_jumps,json=totalJumps,proto3" json:"total_jumps,omitempty"`
+	TotalSessions       int32   `protobuf:"varint,6,opt,name=total_sessions,json=totalSessions,proto3" json:"total_sessions,omitempty"`
+	MaxHeightCm         float64 `protobuf:"fixed64,7,opt,name=max_height_cm,json=maxHeightCm,proto3" json:"max_height_cm,omitempty"`
+	AvgHeightCm         float64 `protobuf:"fixed64,8,opt,name=avg_height_cm,json=avgHeightCm,proto3" json:"avg_height_cm,omitempty"`
+	HeightImprovementCm float64 `protobuf:"fixed64,9,opt,name=height_improvement_cm,json=heightImprovementCm,proto3" json:"height_improvement_cm,omitempty"`
+	// Technique analysis
+	AvgTakeoffScore int32 `protobuf:"varint,10,opt,name=avg_takeoff_score,json=avgTakeoffScore,proto3" json:"avg_takeoff_score,omitempty"`
+	AvgLandingScore int32 `protobuf:"varint,11,opt,name=avg_landing_score,json=avgLandingScore,proto3" json:"avg_landing_score,omitempty"`
+	AvgOverallScore int32 `protobuf:"varint,12,opt,name=avg_overall_score,json=avgOverallScore,proto3" json:"avg_overall_score,omitempty"`
+	// Injury risk indicators
+	AvgValgusAngleDeg float64 `protobuf:"fixed64,13,opt,name=avg_valgus_angle_deg,json=avgValgusAngleDeg,proto3" json:"avg_valgus_angle_deg,omitempty"`
+	MaxValgusAngleDeg float64 `protobuf:"fixed64,14,opt,name=max_valgus_angle_deg,json=maxValgusAngleDeg,proto3" json:"max_valgus_angle_deg,omitempty"`
+	// 0-100, higher means more risk
+	RiskScore int32 `protobuf:"varint,15,opt,name=risk_score,json=riskScore,proto3" json:"risk_score,omitempty"`
+	// Training load
+	TotalLoadScore int32   `protobuf:"varint,16,opt,name=total_load_score,json=totalLoadScore,proto3" json:"total_load_score,omitempty"`
+	AvgRpe         float64 `protobuf:"fixed64,17,opt,name=avg_rpe,json=avgRpe,proto3" json:"avg_rpe,omitempty"`
+	// Trends: improving, stable or declining
+	HeightTrend    string `protobuf:"bytes,18,opt,name=height_trend,json=heightTrend,proto3" json:"height_trend,omitempty"`
+	TechniqueTrend string `protobuf:"bytes,19,opt,name=technique_trend,json=techniqueTrend,proto3" json:"technique_trend,omitempty"`
+	LoadTrend      string `protobuf:"bytes,20,opt,name=load_trend,json=loadTrend,proto3" json:"load_trend,omitempty"`
+}
+
+func (x *MetricsSummary) Reset() {
+	*x = MetricsSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetricsSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsSummary) ProtoMessage() {}
+
+func (x *MetricsSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsSummary.ProtoReflect.Descriptor instead.
+func (*MetricsSummary) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricsSummary) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *MetricsSummary) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *MetricsSummary) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *MetricsSummary) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *MetricsSummary) GetTotalJumps() int32 {
+	if x != nil {
+		return x.TotalJumps
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetTotalSessions() int32 {
+	if x != nil {
+		return x.TotalSessions
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetMaxHeightCm() float64 {
+	if x != nil {
+		return x.MaxHeightCm
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgHeightCm() float64 {
+	if x != nil {
+		return x.AvgHeightCm
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetHeightImprovementCm() float64 {
+	if x != nil {
+		return x.HeightImprovementCm
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgTakeoffScore() int32 {
+	if x != nil {
+		return x.AvgTakeoffScore
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgLandingScore() int32 {
+	if x != nil {
+		return x.AvgLandingScore
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgOverallScore() int32 {
+	if x != nil {
+		return x.AvgOverallScore
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgValgusAngleDeg() float64 {
+	if x != nil {
+		return x.AvgValgusAngleDeg
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetMaxValgusAngleDeg() float64 {
+	if x != nil {
+		return x.MaxValgusAngleDeg
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetRiskScore() int32 {
+	if x != nil {
+		return x.RiskScore
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetTotalLoadScore() int32 {
+	if x != nil {
+		return x.TotalLoadScore
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetAvgRpe() float64 {
+	if x != nil {
+		return x.AvgRpe
+	}
+	return 0
+}
+
+func (x *MetricsSummary) GetHeightTrend() string {
+	if x != nil {
+		return x.HeightTrend
+	}
+	return ""
+}
+
+func (x *MetricsSummary) GetTechniqueTrend() string {
+	if x != nil {
+		return x.TechniqueTrend
+	}
+	return ""
+}
+
+func (x *MetricsSummary) GetLoadTrend() string {
+	if x != nil {
+		return x.LoadTrend
+	}
+	return ""
+}
+
+// LeaderboardEntry is an athlete's best jump within the leaderboard period
+type LeaderboardEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rank int32 `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	// Empty for minors
+	AthleteId string `protobuf:"bytes,2,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	// Empty for minors
+	Name        string  `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	SportLevel  string  `protobuf:"bytes,4,opt,name=sport_level,json=sportLevel,proto3" json:"sport_level,omitempty"`
+	MaxHeightCm float64 `protobuf:"fixed64,5,opt,name=max_height_cm,json=maxHeightCm,proto3" json:"max_height_cm,omitempty"`
+	Jumps       int32   `protobuf:"varint,6,opt,name=jumps,proto3" json:"jumps,omitempty"`
+}
+
+func (x *LeaderboardEntry) Reset() {
+	*x = LeaderboardEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeaderboardEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaderboardEntry) ProtoMessage() {}
+
+func (x *LeaderboardEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaderboardEntry.ProtoReflect.Descriptor instead.
+func (*LeaderboardEntry) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LeaderboardEntry) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *LeaderboardEntry) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *LeaderboardEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LeaderboardEntry) GetSportLevel() string {
+	if x != nil {
+		return x.SportLevel
+	}
+	return ""
+}
+
+func (x *LeaderboardEntry) GetMaxHeightCm() float64 {
+	if x != nil {
+		return x.MaxHeightCm
+	}
+	return 0
+}
+
+func (x *LeaderboardEntry) GetJumps() int32 {
+	if x != nil {
+		return x.Jumps
+	}
+	return 0
+}
+
+type SubmitMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AthleteId string        `protobuf:"bytes,1,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	Session   *JumpSession  `protobuf:"bytes,2,opt,name=session,proto3" json:"session,omitempty"`
+	Metrics   []*JumpMetric `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+func (x *SubmitMetricsRequest) Reset() {
+	*x = SubmitMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitMetricsRequest) ProtoMessage() {}
+
+func (x *SubmitMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitMetricsRequest.ProtoReflect.Descriptor instead.
+func (*SubmitMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SubmitMetricsRequest) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *SubmitMetricsRequest) GetSession() *JumpSession {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *SubmitMetricsRequest) GetMetrics() []*JumpMetric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+type SubmitMetricsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionId      string `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Metrics        int32  `protobuf:"varint,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	DeviceVerified bool   `protobuf:"varint,3,opt,name=device_verified,json=deviceVerified,proto3" json:"device_verified,omitempty"`
+}
+
+func (x *SubmitMetricsResponse) Reset() {
+	*x = SubmitMetricsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubmitMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitMetricsResponse) ProtoMessage() {}
+
+func (x *SubmitMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitMetricsResponse.ProtoReflect.Descriptor instead.
+func (*SubmitMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SubmitMetricsResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SubmitMetricsResponse) GetMetrics() int32 {
+	if x != nil {
+		return x.Metrics
+	}
+	return 0
+}
+
+func (x *SubmitMetricsResponse) GetDeviceVerified() bool {
+	if x != nil {
+		return x.DeviceVerified
+	}
+	return false
+}
+
+type GetAthleteMetricsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AthleteId string `protobuf:"bytes,1,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+}
+
+func (x *GetAthleteMetricsRequest) Reset() {
+	*x = GetAthleteMetricsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAthleteMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAthleteMetricsRequest) ProtoMessage() {}
+
+func (x *GetAthleteMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAthleteMetricsRequest.ProtoReflect.Descriptor instead.
+func (*GetAthleteMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetAthleteMetricsRequest) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+type GetAthleteMetricsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metrics    []*JumpMetric   `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Summary    *MetricsSummary `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	TotalCount int32           `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	HasMore    bool            `protobuf:"varint,4,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+}
+
+func (x *GetAthleteMetricsResponse) Reset() {
+	*x = GetAthleteMetricsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAthleteMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAthleteMetricsResponse) ProtoMessage() {}
+
+func (x *GetAthleteMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAthleteMetricsResponse.ProtoReflect.Descriptor instead.
+func (*GetAthleteMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetAthleteMetricsResponse) GetMetrics() []*JumpMetric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *GetAthleteMetricsResponse) GetSummary() *MetricsSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *GetAthleteMetricsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *GetAthleteMetricsResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+type GetSummaryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AthleteId string `protobuf:"bytes,1,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+}
+
+func (x *GetSummaryRequest) Reset() {
+	*x = GetSummaryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSummaryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSummaryRequest) ProtoMessage() {}
+
+func (x *GetSummaryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSummaryRequest.ProtoReflect.Descriptor instead.
+func (*GetSummaryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetSummaryRequest) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+type StreamSessionJumpsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AthleteId string `protobuf:"bytes,1,opt,name=athlete_id,json=athleteId,proto3" json:"athlete_id,omitempty"`
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+}
+
+func (x *StreamSessionJumpsRequest) Reset() {
+	*x = StreamSessionJumpsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamSessionJumpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamSessionJumpsRequest) ProtoMessage() {}
+
+func (x *StreamSessionJumpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamSessionJumpsRequest.ProtoReflect.Descriptor instead.
+func (*StreamSessionJumpsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StreamSessionJumpsRequest) GetAthleteId() string {
+	if x != nil {
+		return x.AthleteId
+	}
+	return ""
+}
+
+func (x *StreamSessionJumpsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GetLeaderboardRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// week (the default), month or all
+	Period string `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	// 1 to 100, 20 when unset
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *GetLeaderboardRequest) Reset() {
+	*x = GetLeaderboardRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaderboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderboardRequest) ProtoMessage() {}
+
+func (x *GetLeaderboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderboardRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetLeaderboardRequest) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetLeaderboardRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetLeaderboardResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Period  string              `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Entries []*LeaderboardEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *GetLeaderboardResponse) Reset() {
+	*x = GetLeaderboardResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLeaderboardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderboardResponse) ProtoMessage() {}
+
+func (x *GetLeaderboardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderboardResponse.ProtoReflect.Descriptor instead.
+func (*GetLeaderboardResponse) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetLeaderboardResponse) GetPeriod() string {
+	if x != nil {
+		return x.Period
+	}
+	return ""
+}
+
+func (x *GetLeaderboardResponse) GetEntries() []*LeaderboardEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ListGuardianAthletesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListGuardianAthletesRequest) Reset() {
+	*x = ListGuardianAthletesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGuardianAthletesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGuardianAthletesRequest) ProtoMessage() {}
+
+func (x *ListGuardianAthletesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGuardianAthletesRequest.ProtoReflect.Descriptor instead.
+func (*ListGuardianAthletesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{19}
+}
+
+type ListGuardianAthletesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Athletes []*AthleteProfile `protobuf:"bytes,1,rep,name=athletes,proto3" json:"athletes,omitempty"`
+}
+
+func (x *ListGuardianAthletesResponse) Reset() {
+	*x = ListGuardianAthletesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGuardianAthletesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGuardianAthletesResponse) ProtoMessage() {}
+
+func (x *ListGuardianAthletesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGuardianAthletesResponse.ProtoReflect.Descriptor instead.
+func (*ListGuardianAthletesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListGuardianAthletesResponse) GetAthletes() []*AthleteProfile {
+	if x != nil {
+		return x.Athletes
+	}
+	return nil
+}
+
+type ExportTrainingDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Only metrics recorded at or after since are exported
+	Since *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+}
+
+func (x *ExportTrainingDataRequest) Reset() {
+	*x = ExportTrainingDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_metrics_v1_metrics_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportTrainingDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTrainingDataRequest) ProtoMessage() {}
+
+func (x *ExportTrainingDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_metrics_v1_metrics_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTrainingDataRequest.ProtoReflect.Descriptor instead.
+func (*ExportTrainingDataRequest) Descriptor() ([]byte, []int) {
+	return file_proto_metrics_v1_metrics_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ExportTrainingDataRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+var File_proto_metrics_v1_metrics_proto protoreflect.FileDescriptor
+
+var file_proto_metrics_v1_metrics_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f,
+	0x76, 0x31, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x14, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x81, 0x08, 0x0a, 0x0a, 0x4a, 0x75, 0x6d, 0x70,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c,
+	0x65, 0x74, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1b,
+	0x0a, 0x09, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x26, 0x0a, 0x0f, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x54, 0x69, 0x6d,
+	0x65, 0x4d, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x66, 0x6c, 0x69,
+	0x67, 0x68, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x4d, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x74, 0x61, 0x6b,
+	0x65, 0x6f, 0x66, 0x66, 0x5f, 0x76, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x5f, 0x6d, 0x70,
+	0x73, 0x18, 0x17, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x74, 0x61, 0x6b, 0x65, 0x6f, 0x66, 0x66,
+	0x56, 0x65, 0x6c, 0x6f, 0x63, 0x69, 0x74, 0x79, 0x4d, 0x70, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6c,
+	0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x5f, 0x6e, 0x18, 0x18,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x46, 0x6f, 0x72,
+	0x63, 0x65, 0x4e, 0x12, 0x28, 0x0a, 0x10, 0x76, 0x61, 0x6c, 0x67, 0x75, 0x73, 0x5f, 0x61, 0x6e,
+	0x67, 0x6c, 0x65, 0x5f, 0x64, 0x65, 0x67, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x76,
+	0x61, 0x6c, 0x67, 0x75, 0x73, 0x41, 0x6e, 0x67, 0x6c, 0x65, 0x44, 0x65, 0x67, 0x12, 0x28, 0x0a,
+	0x10, 0x6b, 0x6e, 0x65, 0x65, 0x5f, 0x66, 0x6c, 0x65, 0x78, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x65,
+	0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x6b, 0x6e, 0x65, 0x65, 0x46, 0x6c, 0x65,
+	0x78, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x67, 0x12, 0x26, 0x0a, 0x0f, 0x68, 0x69, 0x70, 0x5f, 0x66,
+	0x6c, 0x65, 0x78, 0x69, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x67, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0d, 0x68, 0x69, 0x70, 0x46, 0x6c, 0x65, 0x78, 0x69, 0x6f, 0x6e, 0x44, 0x65, 0x67, 0x12,
+	0x23, 0x0a, 0x0d, 0x74, 0x61, 0x6b, 0x65, 0x6f, 0x66, 0x66, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x74, 0x61, 0x6b, 0x65, 0x6f, 0x66, 0x66, 0x53,
+	0x63, 0x6f, 0x72, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x6c, 0x61, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6f, 0x76, 0x65,
+	0x72, 0x61, 0x6c, 0x6c, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0c, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x6c, 0x6c, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x25,
+	0x0a, 0x0e, 0x73, 0x79, 0x6d, 0x6d, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65,
+	0x18, 0x19, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x73, 0x79, 0x6d, 0x6d, 0x65, 0x74, 0x72, 0x79,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x70, 0x70, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x70,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x12, 0x70, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x54,
+	0x69, 0x6d, 0x65, 0x4d, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65,
+	0x6e, 0x63, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x69, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72,
+	0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x13, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x12, 0x3a, 0x0a, 0x08, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x6c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68,
+	0x65, 0x72, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73,
+	0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x52, 0x07, 0x77, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72,
+	0x12, 0x33, 0x0a, 0x04, 0x70, 0x6f, 0x73, 0x65, 0x18, 0x1a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x73, 0x65, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x52,
+	0x04, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x16,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x74, 0x65, 0x73, 0x22, 0x60, 0x0a, 0x08, 0x4c,
+	0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74,
+	0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x61, 0x6c, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x22, 0x63, 0x0a,
+	0x07, 0x57, 0x65, 0x61, 0x74, 0x68, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x74,
+	0x65, 0x6d, 0x70, 0x65, 0x72, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x75,
+	0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x68, 0x75,
+	0x6d, 0x69, 0x64, 0x69, 0x74, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75,
+	0x72, 0x65, 0x22, 0xf0, 0x01, 0x0a, 0x09, 0x50, 0x6f, 0x73, 0x65, 0x46, 0x72, 0x61, 0x6d, 0x65,
+	0x12, 0x17, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x74, 0x69, 0x6d, 0x65, 0x4d, 0x73, 0x12, 0x4c, 0x0a, 0x09, 0x6b, 0x65, 0x79,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x64,
+	0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x73, 0x65, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x2e, 0x4b, 0x65,
+	0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x6b, 0x65,
+	0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x1a, 0x5c, 0x0a, 0x0e, 0x4b, 0x65, 0x79, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x34, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x64, 0x75, 0x6e,
+	0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4b, 0x65, 0x79, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x46, 0x0a, 0x08, 0x4b, 0x65, 0x79, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12,
+	0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x79, 0x12, 0x1e, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xa9, 0x03,
+	0x0a, 0x0b, 0x4a, 0x75, 0x6d, 0x70, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x29,
+	0x0a, 0x10, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6a, 0x75, 0x6d,
+	0x70, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6a,
+	0x75, 0x6d, 0x70, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0b, 0x6d, 0x61, 0x78, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x22, 0x0a, 0x0d,
+	0x61, 0x76, 0x67, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0b, 0x61, 0x76, 0x67, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x61, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x72, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x72, 0x70,
+	0x65, 0x12, 0x36, 0x0a, 0x05, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x20, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75, 0x6d, 0x70, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x52, 0x05, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x22, 0x95, 0x05, 0x0a, 0x0e, 0x41, 0x74,
+	0x68, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x07,
+	0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75,
+	0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x67, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x6b, 0x67, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x77, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x4b, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x6c,
+	0x65, 0x76, 0x65, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x70, 0x6f, 0x72,
+	0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x39, 0x0a, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x2b, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x5f, 0x6a, 0x75, 0x6d, 0x70, 0x5f, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f,
+	0x6d, 0x61, 0x78, 0x4a, 0x75, 0x6d, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12,
+	0x2b, 0x0a, 0x12, 0x61, 0x76, 0x67, 0x5f, 0x6a, 0x75, 0x6d, 0x70, 0x5f, 0x68, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x61, 0x76, 0x67,
+	0x4a, 0x75, 0x6d, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x2f, 0x0a, 0x14,
+	0x62, 0x65, 0x73, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x5f, 0x6d, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x62, 0x65, 0x73, 0x74,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x4d, 0x73, 0x12, 0x10, 0x0a,
+	0x03, 0x72, 0x73, 0x69, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x72, 0x73, 0x69, 0x12,
+	0x14, 0x0a, 0x05, 0x67, 0x6f, 0x61, 0x6c, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05,
+	0x67, 0x6f, 0x61, 0x6c, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e,
+	0x67, 0x5f, 0x64, 0x61, 0x79, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x72,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x79, 0x73, 0x12, 0x34, 0x0a, 0x16, 0x70, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x14, 0x70, 0x72, 0x65, 0x66,
+	0x65, 0x72, 0x72, 0x65, 0x64, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x69, 0x6e,
+	0x12, 0x3f, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x63, 0x79, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x25, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x69, 0x76, 0x61, 0x63, 0x79,
+	0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x07, 0x70, 0x72, 0x69, 0x76, 0x61, 0x63,
+	0x79, 0x22, 0xcf, 0x02, 0x0a, 0x0f, 0x50, 0x72, 0x69, 0x76, 0x61, 0x63, 0x79, 0x53, 0x65, 0x74,
+	0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x63, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0f, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65,
+	0x12, 0x2f, 0x0a, 0x13, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x5f,
+	0x76, 0x69, 0x73, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x6c,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x56, 0x69, 0x73, 0x69, 0x62, 0x6c,
+	0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x61, 0x63, 0x68, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x63, 0x6f, 0x61, 0x63, 0x68, 0x41, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x5f, 0x74, 0x72,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x41, 0x0a, 0x08, 0x67,
+	0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e,
+	0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x43, 0x6f, 0x6e,
+	0x73, 0x65, 0x6e, 0x74, 0x52, 0x08, 0x67, 0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x69, 0x0a, 0x0f, 0x47, 0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x43,
+	0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x3d, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xb0,
+	0x06, 0x0a, 0x0e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64,
+	0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x44,
+	0x61, 0x74, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x44, 0x61, 0x74, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f,
+	0x74, 0x61, 0x6c, 0x5f, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x4a, 0x75, 0x6d, 0x70, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x5f, 0x63, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x48, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x22, 0x0a, 0x0d, 0x61, 0x76, 0x67, 0x5f, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x61,
+	0x76, 0x67, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6d, 0x12, 0x32, 0x0a, 0x15, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x5f, 0x69, 0x6d, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x63, 0x6d, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x13, 0x68, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x49, 0x6d, 0x70, 0x72, 0x6f, 0x76, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x43, 0x6d, 0x12, 0x2a,
+	0x0a, 0x11, 0x61, 0x76, 0x67, 0x5f, 0x74, 0x61, 0x6b, 0x65, 0x6f, 0x66, 0x66, 0x5f, 0x73, 0x63,
+	0x6f, 0x72, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x61, 0x76, 0x67, 0x54, 0x61,
+	0x6b, 0x65, 0x6f, 0x66, 0x66, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x76,
+	0x67, 0x5f, 0x6c, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x61, 0x76, 0x67, 0x4c, 0x61, 0x6e, 0x64, 0x69, 0x6e,
+	0x67, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x61, 0x76, 0x67, 0x5f, 0x6f, 0x76,
+	0x65, 0x72, 0x61, 0x6c, 0x6c, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0f, 0x61, 0x76, 0x67, 0x4f, 0x76, 0x65, 0x72, 0x61, 0x6c, 0x6c, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x12, 0x2f, 0x0a, 0x14, 0x61, 0x76, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x67, 0x75, 0x73,
+	0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x5f, 0x64, 0x65, 0x67, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x11, 0x61, 0x76, 0x67, 0x56, 0x61, 0x6c, 0x67, 0x75, 0x73, 0x41, 0x6e, 0x67, 0x6c, 0x65,
+	0x44, 0x65, 0x67, 0x12, 0x2f, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x61, 0x6c, 0x67, 0x75,
+	0x73, 0x5f, 0x61, 0x6e, 0x67, 0x6c, 0x65, 0x5f, 0x64, 0x65, 0x67, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x56, 0x61, 0x6c, 0x67, 0x75, 0x73, 0x41, 0x6e, 0x67, 0x6c,
+	0x65, 0x44, 0x65, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x69, 0x73, 0x6b, 0x5f, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x72, 0x69, 0x73, 0x6b, 0x53, 0x63,
+	0x6f, 0x72, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x6c, 0x6f, 0x61,
+	0x64, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x4c, 0x6f, 0x61, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x17, 0x0a,
+	0x07, 0x61, 0x76, 0x67, 0x5f, 0x72, 0x70, 0x65, 0x18, 0x11, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x61, 0x76, 0x67, 0x52, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x5f, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x68, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x54, 0x72, 0x65, 0x6e, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x65, 0x63,
+	0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x5f, 0x74, 0x72, 0x65, 0x6e, 0x64, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0e, 0x74, 0x65, 0x63, 0x68, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x54, 0x72, 0x65,
+	0x6e, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x74, 0x72, 0x65, 0x6e, 0x64,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x6f, 0x61, 0x64, 0x54, 0x72, 0x65, 0x6e,
+	0x64, 0x22, 0xb4, 0x01, 0x0a, 0x10, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72,
+	0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x6b, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74,
+	0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a,
+	0x0b, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x22,
+	0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x5f, 0x63, 0x6d, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x43, 0x6d, 0x12, 0x14, 0x0a, 0x05, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x6a, 0x75, 0x6d, 0x70, 0x73, 0x22, 0xae, 0x01, 0x0a, 0x14, 0x53, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64,
+	0x12, 0x3b, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75, 0x6d, 0x70, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a,
+	0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75, 0x6d, 0x70, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x22, 0x79, 0x0a, 0x15, 0x53, 0x75, 0x62,
+	0x6d, 0x69, 0x74, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x56, 0x65, 0x72, 0x69,
+	0x66, 0x69, 0x65, 0x64, 0x22, 0x39, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x41, 0x74, 0x68, 0x6c, 0x65,
+	0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64, 0x22,
+	0xd3, 0x01, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a,
+	0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75, 0x6d, 0x70, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x3e, 0x0a, 0x07, 0x73, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x64, 0x75, 0x6e,
+	0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x61,
+	0x73, 0x5f, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x61,
+	0x73, 0x4d, 0x6f, 0x72, 0x65, 0x22, 0x32, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x53, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74,
+	0x68, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x49, 0x64, 0x22, 0x59, 0x0a, 0x19, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4a, 0x75, 0x6d, 0x70, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x74, 0x68, 0x6c,
+	0x65, 0x74, 0x65, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x22, 0x45, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x72, 0x0a, 0x16, 0x47,
+	0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x40, 0x0a,
+	0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26,
+	0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69,
+	0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72,
+	0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22,
+	0x1d, 0x0a, 0x1b, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x41,
+	0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x60,
+	0x0a, 0x1c, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x41, 0x74,
+	0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x40,
+	0x0a, 0x08, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x08, 0x61, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73,
+	0x22, 0x4d, 0x0a, 0x19, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69,
+	0x6e, 0x67, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x30, 0x0a,
+	0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x32,
+	0x8f, 0x06, 0x0a, 0x0e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x68, 0x0a, 0x0d, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x12, 0x2a, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e,
+	0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x2b, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x4d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x74, 0x0a, 0x11,
+	0x47, 0x65, 0x74, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x12, 0x2e, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x68, 0x6c,
+	0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2f, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x68, 0x6c,
+	0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x5b, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x12, 0x27, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74,
+	0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x75, 0x6d, 0x6d, 0x61,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x64, 0x75, 0x6e, 0x6b,
+	0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12,
+	0x69, 0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x4a, 0x75, 0x6d, 0x70, 0x73, 0x12, 0x2f, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73,
+	0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4a, 0x75, 0x6d, 0x70, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e,
+	0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75,
+	0x6d, 0x70, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x30, 0x01, 0x12, 0x6b, 0x0a, 0x0e, 0x47, 0x65,
+	0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x12, 0x2b, 0x2e, 0x64,
+	0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61,
+	0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2c, 0x2e, 0x64, 0x75, 0x6e, 0x6b,
+	0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x62, 0x6f, 0x61, 0x72, 0x64, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7d, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x47,
+	0x75, 0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x12,
+	0x31, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x75, 0x61, 0x72, 0x64,
+	0x69, 0x61, 0x6e, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x32, 0x2e, 0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d,
+	0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x75,
+	0x61, 0x72, 0x64, 0x69, 0x61, 0x6e, 0x41, 0x74, 0x68, 0x6c, 0x65, 0x74, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x69, 0x0a, 0x12, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74,
+	0x54, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x44, 0x61, 0x74, 0x61, 0x12, 0x2f, 0x2e, 0x64,
+	0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x72, 0x61, 0x69, 0x6e, 0x69,
+	0x6e, 0x67, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e,
+	0x64, 0x75, 0x6e, 0x6b, 0x73, 0x65, 0x6e, 0x73, 0x65, 0x2e, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x75, 0x6d, 0x70, 0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x30,
+	0x01, 0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x44, 0x61, 0x6e, 0x63, 0x68, 0x6f, 0x75, 0x76, 0x7a, 0x76, 0x2f, 0x44, 0x75, 0x6e, 0x6b, 0x53,
+	0x65, 0x6e, 0x73, 0x65, 0x2f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2f, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x2f, 0x76, 0x31, 0x3b, 0x6d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_proto_metrics_v1_metrics_proto_rawDescOnce sync.Once
+	file_proto_metrics_v1_metrics_proto_rawDescData = file_proto_metrics_v1_metrics_proto_rawDesc
+)
+
+func file_proto_metrics_v1_metrics_proto_rawDescGZIP() []byte {
+	file_proto_metrics_v1_metrics_proto_rawDescOnce.Do(func() {
+		file_proto_metrics_v1_metrics_proto_rawDescData = protoimpl.X.CompressGZIP(file_proto_metrics_v1_metrics_proto_rawDescData)
+	})
+	return file_proto_metrics_v1_metrics_proto_rawDescData
+}
+
+var file_proto_metrics_v1_metrics_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_proto_metrics_v1_metrics_proto_goTypes = []interface{}{
+	(*JumpMetric)(nil),                   // 0: dunksense.metrics.v1.JumpMetric
+	(*Location)(nil),                     // 1: dunksense.metrics.v1.Location
+	(*Weather)(nil),                      // 2: dunksense.metrics.v1.Weather
+	(*PoseFrame)(nil),                    // 3: dunksense.metrics.v1.PoseFrame
+	(*Keypoint)(nil),                     // 4: dunksense.metrics.v1.Keypoint
+	(*JumpSession)(nil),                  // 5: dunksense.metrics.v1.JumpSession
+	(*AthleteProfile)(nil),               // 6: dunksense.metrics.v1.AthleteProfile
+	(*PrivacySettings)(nil),              // 7: dunksense.metrics.v1.PrivacySettings
+	(*GuardianConsent)(nil),              // 8: dunksense.metrics.v1.GuardianConsent
+	(*MetricsSummary)(nil),               // 9: dunksense.metrics.v1.MetricsSummary
+	(*LeaderboardEntry)(nil),             // 10: dunksense.metrics.v1.LeaderboardEntry
+	(*SubmitMetricsRequest)(nil),         // 11: dunksense.metrics.v1.SubmitMetricsRequest
+	(*SubmitMetricsResponse)(nil),        // 12: dunksense.metrics.v1.SubmitMetricsResponse
+	(*GetAthleteMetricsRequest)(nil),     // 13: dunksense.metrics.v1.GetAthleteMetricsRequest
+	(*GetAthleteMetricsResponse)(nil),    // 14: dunksense.metrics.v1.GetAthleteMetricsResponse
+	(*GetSummaryRequest)(nil),            // 15: dunksense.metrics.v1.GetSummaryRequest
+	(*StreamSessionJumpsRequest)(nil),    // 16: dunksense.metrics.v1.StreamSessionJumpsRequest
+	(*GetLeaderboardRequest)(nil),        // 17: dunksense.metrics.v1.GetLeaderboardRequest
+	(*GetLeaderboardResponse)(nil),       // 18: dunksense.metrics.v1.GetLeaderboardResponse
+	(*ListGuardianAthletesRequest)(nil),  // 19: dunksense.metrics.v1.ListGuardianAthletesRequest
+	(*ListGuardianAthletesResponse)(nil), // 20: dunksense.metrics.v1.ListGuardianAthletesResponse
+	(*ExportTrainingDataRequest)(nil),    // 21: dunksense.metrics.v1.ExportTrainingDataRequest
+	nil,                                  // 22: dunksense.metrics.v1.PoseFrame.KeypointsEntry
+	(*timestamppb.Timestamp)(nil),        // 23: google.protobuf.Timestamp
+}
+var file_proto_metrics_v1_metrics_proto_depIdxs = []int32{
+	23, // 0: dunksense.metrics.v1.JumpMetric.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 1: dunksense.metrics.v1.JumpMetric.location:type_name -> dunksense.metrics.v1.Location
+	2,  // 2: dunksense.metrics.v1.JumpMetric.weather:type_name -> dunksense.metrics.v1.Weather
+	3,  // 3: dunksense.metrics.v1.JumpMetric.pose:type_name -> dunksense.metrics.v1.PoseFrame
+	22, // 4: dunksense.metrics.v1.PoseFrame.keypoints:type_name -> dunksense.metrics.v1.PoseFrame.KeypointsEntry
+	23, // 5: dunksense.metrics.v1.JumpSession.start_time:type_name -> google.protobuf.Timestamp
+	23, // 6: dunksense.metrics.v1.JumpSession.end_time:type_name -> google.protobuf.Timestamp
+	0,  // 7: dunksense.metrics.v1.JumpSession.jumps:type_name -> dunksense.metrics.v1.JumpMetric
+	23, // 8: dunksense.metrics.v1.AthleteProfile.created_at:type_name -> google.protobuf.Timestamp
+	23, // 9: dunksense.metrics.v1.AthleteProfile.updated_at:type_name -> google.protobuf.Timestamp
+	7,  // 10: dunksense.metrics.v1.AthleteProfile.privacy:type_name -> dunksense.metrics.v1.PrivacySettings
+	8,  // 11: dunksense.metrics.v1.PrivacySettings.guardian:type_name -> dunksense.metrics.v1.GuardianConsent
+	23, // 12: dunksense.metrics.v1.PrivacySettings.updated_at:type_name -> google.protobuf.Timestamp
+	23, // 13: dunksense.metrics.v1.GuardianConsent.consented_at:type_name -> google.protobuf.Timestamp
+	23, // 14: dunksense.metrics.v1.MetricsSummary.start_date:type_name -> google.protobuf.Timestamp
+	23, // 15: dunksense.metrics.v1.MetricsSummary.end_date:type_name -> google.protobuf.Timestamp
+	5,  // 16: dunksense.metrics.v1.SubmitMetricsRequest.session:type_name -> dunksense.metrics.v1.JumpSession
+	0,  // 17: dunksense.metrics.v1.SubmitMetricsRequest.metrics:type_name -> dunksense.metrics.v1.JumpMetric
+	0,  // 18: dunksense.metrics.v1.GetAthleteMetricsResponse.metrics:type_name -> dunksense.metrics.v1.JumpMetric
+	9,  // 19: dunksense.metrics.v1.GetAthleteMetricsResponse.summary:type_name -> dunksense.metrics.v1.MetricsSummary
+	10, // 20: dunksense.metrics.v1.GetLeaderboardResponse.entries:type_name -> dunksense.metrics.v1.LeaderboardEntry
+	6,  // 21: dunksense.metrics.v1.ListGuardianAthletesResponse.athletes:type_name -> dunksense.metrics.v1.AthleteProfile
+	23, // 22: dunksense.metrics.v1.ExportTrainingDataRequest.since:type_name -> google.protobuf.Timestamp
+	4,  // 23: dunksense.metrics.v1.PoseFrame.KeypointsEntry.value:type_name -> dunksense.metrics.v1.Keypoint
+	11, // 24: dunksense.metrics.v1.MetricsService.SubmitMetrics:input_type -> dunksense.metrics.v1.SubmitMetricsRequest
+	13, // 25: dunksense.metrics.v1.MetricsService.GetAthleteMetrics:input_type -> dunksense.metrics.v1.GetAthleteMetricsRequest
+	15, // 26: dunksense.metrics.v1.MetricsService.GetSummary:input_type -> dunksense.metrics.v1.GetSummaryRequest
+	16, // 27: dunksense.metrics.v1.MetricsService.StreamSessionJumps:input_type -> dunksense.metrics.v1.StreamSessionJumpsRequest
+	17, // 28: dunksense.metrics.v1.MetricsService.GetLeaderboard:input_type -> dunksense.metrics.v1.GetLeaderboardRequest
+	19, // 29: dunksense.metrics.v1.MetricsService.ListGuardianAthletes:input_type -> dunksense.metrics.v1.ListGuardianAthletesRequest
+	21, // 30: dunksense.metrics.v1.MetricsService.ExportTrainingData:input_type -> dunksense.metrics.v1.ExportTrainingDataRequest
+	12, // 31: dunksense.metrics.v1.MetricsService.SubmitMetrics:output_type -> dunksense.metrics.v1.SubmitMetricsResponse
+	14, // 32: dunksense.metrics.v1.MetricsService.GetAthleteMetrics:output_type -> dunksense.metrics.v1.GetAthleteMetricsResponse
+	9,  // 33: dunksense.metrics.v1.MetricsService.GetSummary:output_type -> dunksense.metrics.v1.MetricsSummary
+	0,  // 34: dunksense.metrics.v1.MetricsService.StreamSessionJumps:output_type -> dunksense.metrics.v1.JumpMetric
+	18, // 35: dunksense.metrics.v1.MetricsService.GetLeaderboard:output_type -> dunksense.metrics.v1.GetLeaderboardResponse
+	20, // 36: dunksense.metrics.v1.MetricsService.ListGuardianAthletes:output_type -> dunksense.metrics.v1.ListGuardianAthletesResponse
+	0,  // 37: dunksense.metrics.v1.MetricsService.ExportTrainingData:output_type -> dunksense.metrics.v1.JumpMetric
+	31, // [31:38] is the sub-list for method output_type
+	24, // [24:31] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
+}
+
+func init() { file_proto_metrics_v1_metrics_proto_init() }
+func file_proto_metrics_v1_metrics_proto_init() {
+	if File_proto_metrics_v1_metrics_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_proto_metrics_v1_metrics_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JumpMetric); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Location); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Weather); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PoseFrame); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Keypoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JumpSession); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AthleteProfile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PrivacySettings); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GuardianConsent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetricsSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaderboardEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubmitMetricsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAthleteMetricsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAthleteMetricsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSummaryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StreamSessionJumpsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaderboardRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLeaderboardResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGuardianAthletesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGuardianAthletesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_metrics_v1_metrics_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportTrainingDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_metrics_v1_metrics_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_metrics_v1_metrics_proto_goTypes,
+		DependencyIndexes: file_proto_metrics_v1_metrics_proto_depIdxs,
+		MessageInfos:      file_proto_metrics_v1_metrics_proto_msgTypes,
+	}.Build()
+	File_proto_metrics_v1_metrics_proto = out.File
+	file_proto_metrics_v1_metrics_proto_rawDesc = nil
+	file_proto_metrics_v1_metrics_proto_goTypes = nil
+	file_proto_metrics_v1_metrics_proto_depIdxs = nil
+}
@@ -0,0 +1,402 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: proto/metrics/v1/metrics.proto
+
+package metricsv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MetricsService_SubmitMetrics_FullMethodName        = "/dunksense.metrics.v1.MetricsService/SubmitMetrics"
+	MetricsService_GetAthleteMetrics_FullMethodName    = "/dunksense.metrics.v1.MetricsService/GetAthleteMetrics"
+	MetricsService_GetSummary_FullMethodName           = "/dunksense.metrics.v1.MetricsService/GetSummary"
+	MetricsService_StreamSessionJumps_FullMethodName   = "/dunksense.metrics.v1.MetricsService/StreamSessionJumps"
+	MetricsService_GetLeaderboard_FullMethodName       = "/dunksense.metrics.v1.MetricsService/GetLeaderboard"
+	MetricsService_ListGuardianAthletes_FullMethodName = "/dunksense.metrics.v1.MetricsService/ListGuardianAthletes"
+	MetricsService_ExportTrainingData_FullMethodName   = "/dunksense.metrics.v1.MetricsService/ExportTrainingData"
+)
+
+// MetricsServiceClient is the client API for MetricsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MetricsServiceClient interface {
+	// SubmitMetrics stores a session and its jump metrics
+	SubmitMetrics(ctx context.Context, in *SubmitMetricsRequest, opts ...grpc.CallOption) (*SubmitMetricsResponse, error)
+	// GetAthleteMetrics returns the metrics of the last 30 days of an athlete
+	GetAthleteMetrics(ctx context.Context, in *GetAthleteMetricsRequest, opts ...grpc.CallOption) (*GetAthleteMetricsResponse, error)
+	// GetSummary returns the weekly summary of an athlete
+	GetSummary(ctx context.Context, in *GetSummaryRequest, opts ...grpc.CallOption) (*MetricsSummary, error)
+	// StreamSessionJumps streams the jumps of a session in the order they were made
+	StreamSessionJumps(ctx context.Context, in *StreamSessionJumpsRequest, opts ...grpc.CallOption) (MetricsService_StreamSessionJumpsClient, error)
+	// GetLeaderboard ranks athletes who opted in by their best jump of the period
+	GetLeaderboard(ctx context.Context, in *GetLeaderboardRequest, opts ...grpc.CallOption) (*GetLeaderboardResponse, error)
+	// ListGuardianAthletes lists the minors the caller is guardian of
+	ListGuardianAthletes(ctx context.Context, in *ListGuardianAthletesRequest, opts ...grpc.CallOption) (*ListGuardianAthletesResponse, error)
+	// ExportTrainingData streams the metrics of athletes who consented to model
+	// training, oldest first. Admins only.
+	ExportTrainingData(ctx context.Context, in *ExportTrainingDataRequest, opts ...grpc.CallOption) (MetricsService_ExportTrainingDataClient, error)
+}
+
+type metricsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetricsServiceClient(cc grpc.ClientConnInterface) MetricsServiceClient {
+	return &metricsServiceClient{cc}
+}
+
+func (c *metricsServiceClient) SubmitMetrics(ctx context.Context, in *SubmitMetricsRequest, opts ...grpc.CallOption) (*SubmitMetricsResponse, error) {
+	out := new(SubmitMetricsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_SubmitMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetAthleteMetrics(ctx context.Context, in *GetAthleteMetricsRequest, opts ...grpc.CallOption) (*GetAthleteMetricsResponse, error) {
+	out := new(GetAthleteMetricsResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetAthleteMetrics_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) GetSummary(ctx context.Context, in *GetSummaryRequest, opts ...grpc.CallOption) (*MetricsSummary, error) {
+	out := new(MetricsSummary)
+	err := c.cc.Invoke(ctx, MetricsService_GetSummary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) StreamSessionJumps(ctx context.Context, in *StreamSessionJumpsRequest, opts ...grpc.CallOption) (MetricsService_StreamSessionJumpsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[0], MetricsService_StreamSessionJumps_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &metricsServiceStreamSessionJumpsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MetricsService_StreamSessionJumpsClient interface {
+	Recv() (*JumpMetric, error)
+	grpc.ClientStream
+}
+
+type metricsServiceStreamSessionJumpsClient struct {
+	grpc.ClientStream
+}
+
+func (x *metricsServiceStreamSessionJumpsClient) Recv() (*JumpMetric, error) {
+	m := new(JumpMetric)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *metricsServiceClient) GetLeaderboard(ctx context.Context, in *GetLeaderboardRequest, opts ...grpc.CallOption) (*GetLeaderboardResponse, error) {
+	out := new(GetLeaderboardResponse)
+	err := c.cc.Invoke(ctx, MetricsService_GetLeaderboard_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) ListGuardianAthletes(ctx context.Context, in *ListGuardianAthletesRequest, opts ...grpc.CallOption) (*ListGuardianAthletesResponse, error) {
+	out := new(ListGuardianAthletesResponse)
+	err := c.cc.Invoke(ctx, MetricsService_ListGuardianAthletes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsServiceClient) ExportTrainingData(ctx context.Context, in *ExportTrainingDataRequest, opts ...grpc.CallOption) (MetricsService_ExportTrainingDataClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MetricsService_ServiceDesc.Streams[1], MetricsService_ExportTrainingData_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &metricsServiceExportTrainingDataClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MetricsService_ExportTrainingDataClient interface {
+	Recv() (*JumpMetric, error)
+	grpc.ClientStream
+}
+
+type metricsServiceExportTrainingDataClient struct {
+	grpc.ClientStream
+}
+
+func (x *metricsServiceExportTrainingDataClient) Recv() (*JumpMetric, error) {
+	m := new(JumpMetric)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MetricsServiceServer is the server API for MetricsService service.
+// All implementations must embed UnimplementedMetricsServiceServer
+// for forward compatibility
+type MetricsServiceServer interface {
+	// SubmitMetrics stores a session and its jump metrics
+	SubmitMetrics(context.Context, *SubmitMetricsRequest) (*SubmitMetricsResponse, error)
+	// GetAthleteMetrics returns the metrics of the last 30 days of an athlete
+	GetAthleteMetrics(context.Context, *GetAthleteMetricsRequest) (*GetAthleteMetricsResponse, error)
+	// GetSummary returns the weekly summary of an athlete
+	GetSummary(context.Context, *GetSummaryRequest) (*MetricsSummary, error)
+	// StreamSessionJumps streams the jumps of a session in the order they were made
+	StreamSessionJumps(*StreamSessionJumpsRequest, MetricsService_StreamSessionJumpsServer) error
+	// GetLeaderboard ranks athletes who opted in by their best jump of the period
+	GetLeaderboard(context.Context, *GetLeaderboardRequest) (*GetLeaderboardResponse, error)
+	// ListGuardianAthletes lists the minors the caller is guardian of
+	ListGuardianAthletes(context.Context, *ListGuardianAthletesRequest) (*ListGuardianAthletesResponse, error)
+	// ExportTrainingData streams the metrics of athletes who consented to model
+	// training, oldest first. Admins only.
+	ExportTrainingData(*ExportTrainingDataRequest, MetricsService_ExportTrainingDataServer) error
+	mustEmbedUnimplementedMetricsServiceServer()
+}
+
+// UnimplementedMetricsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedMetricsServiceServer struct {
+}
+
+func (UnimplementedMetricsServiceServer) SubmitMetrics(context.Context, *SubmitMetricsRequest) (*SubmitMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitMetrics not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetAthleteMetrics(context.Context, *GetAthleteMetricsRequest) (*GetAthleteMetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAthleteMetrics not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetSummary(context.Context, *GetSummaryRequest) (*MetricsSummary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSummary not implemented")
+}
+func (UnimplementedMetricsServiceServer) StreamSessionJumps(*StreamSessionJumpsRequest, MetricsService_StreamSessionJumpsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSessionJumps not implemented")
+}
+func (UnimplementedMetricsServiceServer) GetLeaderboard(context.Context, *GetLeaderboardRequest) (*GetLeaderboardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeaderboard not implemented")
+}
+func (UnimplementedMetricsServiceServer) ListGuardianAthletes(context.Context, *ListGuardianAthletesRequest) (*ListGuardianAthletesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGuardianAthletes not implemented")
+}
+func (UnimplementedMetricsServiceServer) ExportTrainingData(*ExportTrainingDataRequest, MetricsService_ExportTrainingDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTrainingData not implemented")
+}
+func (UnimplementedMetricsServiceServer) mustEmbedUnimplementedMetricsServiceServer() {}
+
+// UnsafeMetricsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetricsServiceServer will
+// result in compilation errors.
+type UnsafeMetricsServiceServer interface {
+	mustEmbedUnimplementedMetricsServiceServer()
+}
+
+func RegisterMetricsServiceServer(s grpc.ServiceRegistrar, srv MetricsServiceServer) {
+	s.RegisterService(&MetricsService_ServiceDesc, srv)
+}
+
+func _MetricsService_SubmitMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).SubmitMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_SubmitMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).SubmitMetrics(ctx, req.(*SubmitMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetAthleteMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAthleteMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetAthleteMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetAthleteMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetAthleteMetrics(ctx, req.(*GetAthleteMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_GetSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetSummary(ctx, req.(*GetSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_StreamSessionJumps_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamSessionJumpsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsServiceServer).StreamSessionJumps(m, &metricsServiceStreamSessionJumpsServer{stream})
+}
+
+type MetricsService_StreamSessionJumpsServer interface {
+	Send(*JumpMetric) error
+	grpc.ServerStream
+}
+
+type metricsServiceStreamSessionJumpsServer struct {
+	grpc.ServerStream
+}
+
+func (x *metricsServiceStreamSessionJumpsServer) Send(m *JumpMetric) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MetricsService_GetLeaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).GetLeaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_GetLeaderboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).GetLeaderboard(ctx, req.(*GetLeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_ListGuardianAthletes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGuardianAthletesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServiceServer).ListGuardianAthletes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MetricsService_ListGuardianAthletes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServiceServer).ListGuardianAthletes(ctx, req.(*ListGuardianAthletesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MetricsService_ExportTrainingData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTrainingDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsServiceServer).ExportTrainingData(m, &metricsServiceExportTrainingDataServer{stream})
+}
+
+type MetricsService_ExportTrainingDataServer interface {
+	Send(*JumpMetric) error
+	grpc.ServerStream
+}
+
+type metricsServiceExportTrainingDataServer struct {
+	grpc.ServerStream
+}
+
+func (x *metricsServiceExportTrainingDataServer) Send(m *JumpMetric) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MetricsService_ServiceDesc is the grpc.ServiceDesc for MetricsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MetricsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dunksense.metrics.v1.MetricsService",
+	HandlerType: (*MetricsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitMetrics",
+			Handler:    _MetricsService_SubmitMetrics_Handler,
+		},
+		{
+			MethodName: "GetAthleteMetrics",
+			Handler:    _MetricsService_GetAthleteMetrics_Handler,
+		},
+		{
+			MethodName: "GetSummary",
+			Handler:    _MetricsService_GetSummary_Handler,
+		},
+		{
+			MethodName: "GetLeaderboard",
+			Handler:    _MetricsService_GetLeaderboard_Handler,
+		},
+		{
+			MethodName: "ListGuardianAthletes",
+			Handler:    _MetricsService_ListGuardianAthletes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSessionJumps",
+			Handler:       _MetricsService_StreamSessionJumps_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportTrainingData",
+			Handler:       _MetricsService_ExportTrainingData_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/metrics/v1/metrics.proto",
+}